@@ -19,18 +19,24 @@ type AnalyseUserInputs struct {
 	GrammarRules services.GrammarRules `json:"grammar_rules" binding:"required"`
 	// Type rules for Type Checking
 	TypeRules []services.TypeRule `json:"type_rules" binding:"required"`
+	// Implicit conversions allowed between types, such as int to float
+	Promotions []services.Promotion `json:"promotions"`
+	// Tree to analyse, either the parse tree (tree) or the abstract syntax tree (ast)
+	TreeSource string `json:"tree_source" example:"tree"`
+	// Warnings to report, each one disabled unless requested
+	Warnings services.WarningOptions `json:"warnings"`
 	// User's project name
 	Project_Name string `json:"project_name" binding:"required"`
 }
 
 // @Summary Analysing phase
-// @Description Accepts scope rules, grammar rules and type rules from the user. Searches the database for the syntax tree created from the user. If it exists, the analysing process is performed and the artefacts are stored in the database
+// @Description Accepts scope rules, grammar rules and type rules from the user. Searches the database for the syntax tree created from the user. If it exists, the analysing process is performed and the artefacts, the symbol table and the tree decorated with the type, scope and declaration of every node, are stored in the database. When semantic errors are found, every diagnostic is returned and the partial symbol table is stored. Warnings enabled in the request are returned separately from the errors
 // @Tags Analysing
 // @Accept json
 // @Produce json
 // @Param request body AnalyseUserInputs true "Read Analysing Inputs From User"
 // @Success 200 {object} map[string]string "Artefacts Successfully stored"
-// @Failure 400 {object} map[string]string "Invalid input or artefacts failed to insert"
+// @Failure 400 {object} map[string]string "Invalid input or semantic errors found"
 // @Failure 401 {object} map[string]string "Unauthorized"
 // @Failure 404 {object} map[string]string "Syntax Tree not found"
 // @Failure 500 {object} map[string]string "Internal server error"
@@ -69,7 +75,9 @@ func Analyse(c *gin.Context) {
 	}
 
 	var parsing_res struct {
-		Tree services.SyntaxTree `bson:"tree"`
+		Tree         services.SyntaxTree    `bson:"tree"`
+		AST          services.SyntaxTree    `bson:"ast"`
+		SyntaxErrors []services.SyntaxError `bson:"syntax_errors"`
 	}
 
 	err = parsing_collection.FindOne(ctx, bson.M{"users_id": dbUser.UsersID, "project_name": req.Project_Name}).Decode(&parsing_res)
@@ -78,12 +86,25 @@ func Analyse(c *gin.Context) {
 		return
 	}
 
-	artefact, _, err := services.Analyse(req.ScopeRules, parsing_res.Tree, req.GrammarRules, req.TypeRules)
+	if len(parsing_res.SyntaxErrors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Syntax Tree contains syntax errors. Please go back to parsing", "syntax_errors": parsing_res.SyntaxErrors})
+		return
+	}
+
+	tree, err := SelectSyntaxTree(req.TreeSource, parsing_res.Tree, parsing_res.AST)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input is invalid", "details": err.Error()})
+		return
+	}
+
+	artefact, decorated_tree, diagnostics, err := services.AnalyseWithDiagnostics(req.ScopeRules, tree, req.GrammarRules, req.TypeRules, req.Promotions)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Artefacts creation failed", "details": err.Error()})
 		return
 	}
 
+	warnings := services.FindWarnings(artefact, tree, req.Warnings)
+
 	filters := bson.M{"users_id": dbUser.UsersID, "project_name": req.Project_Name}
 	var userexisting bson.M
 
@@ -93,10 +114,15 @@ func Analyse(c *gin.Context) {
 		_, err = analyse_collection.InsertOne(ctx, bson.M{
 			"users_id":              dbUser.UsersID,
 			"symbol_table_artefact": artefact,
+			"decorated_tree":        decorated_tree,
+			"diagnostics":           diagnostics,
+			"warnings":              warnings,
 			"project_name":          req.Project_Name,
 			"scope_rules":           req.ScopeRules,
 			"grammar_rules":         req.GrammarRules,
 			"type_rules":            req.TypeRules,
+			"promotions":            req.Promotions,
+			"tree_source":           req.TreeSource,
 		})
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database Insertion error"})
@@ -106,9 +132,147 @@ func Analyse(c *gin.Context) {
 		update_existing := bson.D{
 			bson.E{Key: "$set", Value: bson.M{
 				"symbol_table_artefact": artefact,
+				"decorated_tree":        decorated_tree,
+				"diagnostics":           diagnostics,
+				"warnings":              warnings,
 				"scope_rules":           req.ScopeRules,
 				"grammar_rules":         req.GrammarRules,
 				"type_rules":            req.TypeRules,
+				"promotions":            req.Promotions,
+				"tree_source":           req.TreeSource,
+			}},
+		}
+		_, err = analyse_collection.UpdateOne(ctx, filters, update_existing)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database Update error"})
+			return
+		}
+	} else {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database lookup error"})
+		return
+	}
+
+	if len(diagnostics) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":        "Semantic errors found. Partial Symbol Table Artefact inserted.",
+			"details":      services.DiagnosticsError(diagnostics).Error(),
+			"diagnostics":  diagnostics,
+			"warnings":     warnings,
+			"symbol_table": artefact,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Symbol Table Artefact successfully inserted.",
+		"warnings":       warnings,
+		"symbol_table":   artefact,
+		"decorated_tree": decorated_tree,
+	})
+}
+
+type AttributeUserInputs struct {
+	// Attribute equations attached to the productions of the grammar
+	AttributeGrammar services.AttributeGrammar `json:"attribute_grammar" binding:"required"`
+	// Tree to evaluate, either the parse tree (tree) or the abstract syntax tree (ast)
+	TreeSource string `json:"tree_source" example:"tree"`
+	// User's project name
+	Project_Name string `json:"project_name" binding:"required"`
+}
+
+// @Summary Evaluate an attribute grammar
+// @Description Accepts attribute equations attached to the productions of the grammar. Searches the database for the syntax tree created from the user. If it exists, the synthesised and inherited attributes of every node are evaluated in dependency order and the attributed tree is stored in the database
+// @Tags Analysing
+// @Accept json
+// @Produce json
+// @Param request body AttributeUserInputs true "Read Attribute Grammar From User"
+// @Success 200 {object} map[string]string "Attributed tree successfully stored"
+// @Failure 400 {object} map[string]string "Invalid input, invalid equations or circular attributes"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Syntax Tree not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /analysing/attributes [post]
+func EvaluateAttributes(c *gin.Context) {
+	authID, is_existing := c.Get("auth0_id")
+	if !is_existing {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req AttributeUserInputs
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input is invalid", "details": err.Error()})
+		return
+	}
+
+	mongo_cli := db.ConnectClient()
+	users_collection := mongo_cli.Database("visual-compiler").Collection("users")
+	parsing_collection := mongo_cli.Database("visual-compiler").Collection("parsing")
+	analyse_collection := mongo_cli.Database("visual-compiler").Collection("analysing")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var dbUser struct {
+		UsersID bson.ObjectID `bson:"_id"`
+		Auth0ID string        `bson:"auth0_id"`
+	}
+
+	err := users_collection.FindOne(ctx, bson.M{"auth0_id": authID}).Decode(&dbUser)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	var parsing_res struct {
+		Tree services.SyntaxTree `bson:"tree"`
+		AST  services.SyntaxTree `bson:"ast"`
+	}
+
+	err = parsing_collection.FindOne(ctx, bson.M{"users_id": dbUser.UsersID, "project_name": req.Project_Name}).Decode(&parsing_res)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tree not found. Please go back to parsing"})
+		return
+	}
+
+	tree, err := SelectSyntaxTree(req.TreeSource, parsing_res.Tree, parsing_res.AST)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input is invalid", "details": err.Error()})
+		return
+	}
+
+	attributed_tree, err := services.EvaluateAttributes(tree, req.AttributeGrammar)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Attribute evaluation failed", "details": err.Error()})
+		return
+	}
+
+	tree_string := services.ConvertAttributedTreeToString(attributed_tree.Root, "", true)
+
+	filters := bson.M{"users_id": dbUser.UsersID, "project_name": req.Project_Name}
+	var userexisting bson.M
+
+	err = analyse_collection.FindOne(ctx, filters).Decode(&userexisting)
+
+	if err == mongo.ErrNoDocuments {
+		_, err = analyse_collection.InsertOne(ctx, bson.M{
+			"users_id":               dbUser.UsersID,
+			"project_name":           req.Project_Name,
+			"attribute_grammar":      req.AttributeGrammar,
+			"attributed_tree":        attributed_tree,
+			"attributed_tree_string": tree_string,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database Insertion error"})
+			return
+		}
+	} else if err == nil {
+		update_existing := bson.D{
+			bson.E{Key: "$set", Value: bson.M{
+				"attribute_grammar":      req.AttributeGrammar,
+				"attributed_tree":        attributed_tree,
+				"attributed_tree_string": tree_string,
 			}},
 		}
 		_, err = analyse_collection.UpdateOne(ctx, filters, update_existing)
@@ -122,7 +286,8 @@ func Analyse(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message":      "Symbol Table Artefact successfully inserted.",
-		"symbol_table": artefact,
+		"message":         "Attributed tree successfully inserted.",
+		"attributed_tree": attributed_tree,
+		"tree_string":     tree_string,
 	})
 }
@@ -40,6 +40,7 @@ func DeleteUser(c *gin.Context) {
 	lexing_collection := client.Database("visual-compiler").Collection("lexing")
 	parsing_collection := client.Database("visual-compiler").Collection("parsing")
 	analysing_collection := client.Database("visual-compiler").Collection("analysing")
+	intermediate_collection := client.Database("visual-compiler").Collection("intermediate")
 	translating_collection := client.Database("visual-compiler").Collection("translating")
 	optimising_collection := client.Database("visual-compiler").Collection("optimising")
 
@@ -75,6 +76,12 @@ func DeleteUser(c *gin.Context) {
 		return
 	}
 
+	intermediate_res, err := intermediate_collection.DeleteOne(ctx, bson.M{"users_id": req.ID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user's related intermediate data: " + err.Error()})
+		return
+	}
+
 	translating_res, err := translating_collection.DeleteOne(ctx, bson.M{"users_id": req.ID})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user's related translating data: " + err.Error()})
@@ -87,12 +94,13 @@ func DeleteUser(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message":             "User deleted successfully",
-		"user":                res.DeletedCount,
-		"lexing_deleted":      lexing_res.DeletedCount,
-		"parsing_deleted":     parsing_res.DeletedCount,
-		"analysing_deleted":   analysing_res.DeletedCount,
-		"translating_deleted": translating_res.DeletedCount,
-		"optimising_deleted":  optimising_res.DeletedCount,
+		"message":              "User deleted successfully",
+		"user":                 res.DeletedCount,
+		"lexing_deleted":       lexing_res.DeletedCount,
+		"parsing_deleted":      parsing_res.DeletedCount,
+		"analysing_deleted":    analysing_res.DeletedCount,
+		"intermediate_deleted": intermediate_res.DeletedCount,
+		"translating_deleted":  translating_res.DeletedCount,
+		"optimising_deleted":   optimising_res.DeletedCount,
 	})
 }
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/COS301-SE-2025/Visual-Compiler/backend/core/db"
+	"github.com/COS301-SE-2025/Visual-Compiler/backend/core/services"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type IRUserInputs struct {
+	// Node kinds the intermediate representation is generated from
+	IRRules services.IRRules `json:"ir_rules" binding:"required"`
+	// User's project name
+	Project_Name string `json:"project_name" binding:"required"`
+}
+
+// @Summary Generate the intermediate representation
+// @Description Searches the database for the user's analysed syntax tree. If it exists and has no semantic errors, the three-address code of the tree is generated from the node kinds in the IR rules. The code is stored as quadruples together with its textual listing, and any existing intermediate representation is overwritten
+// @Tags Intermediate
+// @Accept json
+// @Produce json
+// @Param request body IRUserInputs true "Generate Intermediate Representation"
+// @Success 200 {object} map[string]string "Intermediate representation successfully stored"
+// @Failure 400 {object} map[string]string "Invalid input/Semantic errors found/Generation failed"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Tree not found/Analysis not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /intermediate/generate [post]
+func GenerateIntermediate(c *gin.Context) {
+	authID, is_existing := c.Get("auth0_id")
+	if !is_existing {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req IRUserInputs
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input is invalid", "details": err.Error()})
+		return
+	}
+
+	mongo_cli := db.ConnectClient()
+	users_collection := mongo_cli.Database("visual-compiler").Collection("users")
+	parsing_collection := mongo_cli.Database("visual-compiler").Collection("parsing")
+	analyse_collection := mongo_cli.Database("visual-compiler").Collection("analysing")
+	intermediate_collection := mongo_cli.Database("visual-compiler").Collection("intermediate")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var dbUser struct {
+		UsersID bson.ObjectID `bson:"_id"`
+		Auth0ID string        `bson:"auth0_id"`
+	}
+
+	err := users_collection.FindOne(ctx, bson.M{"auth0_id": authID}).Decode(&dbUser)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	filters := bson.M{"users_id": dbUser.UsersID, "project_name": req.Project_Name}
+
+	var parsing_res struct {
+		Tree         services.SyntaxTree    `bson:"tree"`
+		AST          services.SyntaxTree    `bson:"ast"`
+		SyntaxErrors []services.SyntaxError `bson:"syntax_errors"`
+	}
+
+	// the attribute evaluation shares the analysing document, so the symbol table marks that the analysis ran
+	var analysing_res struct {
+		SymbolTable *services.SymbolTableArtefact `bson:"symbol_table_artefact"`
+		TreeSource  string                        `bson:"tree_source"`
+		Diagnostics []services.Diagnostic         `bson:"diagnostics"`
+	}
+
+	err = parsing_collection.FindOne(ctx, filters).Decode(&parsing_res)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tree not found. Please go back to parsing"})
+		return
+	}
+
+	err = analyse_collection.FindOne(ctx, filters).Decode(&analysing_res)
+	if err != nil || analysing_res.SymbolTable == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Analysis not found. Please go back to analysing"})
+		return
+	}
+
+	if len(parsing_res.SyntaxErrors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Syntax Tree contains syntax errors. Please go back to parsing", "syntax_errors": parsing_res.SyntaxErrors})
+		return
+	}
+
+	if len(analysing_res.Diagnostics) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Semantic errors found. Please go back to analysing", "diagnostics": analysing_res.Diagnostics})
+		return
+	}
+
+	tree, err := SelectSyntaxTree(analysing_res.TreeSource, parsing_res.Tree, parsing_res.AST)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input is invalid", "details": err.Error()})
+		return
+	}
+
+	program, err := services.GenerateIR(tree, req.IRRules)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Intermediate representation creation failed", "details": err.Error()})
+		return
+	}
+
+	listing := services.ConvertIRToString(program)
+
+	var userexisting bson.M
+
+	err = intermediate_collection.FindOne(ctx, filters).Decode(&userexisting)
+
+	if err == mongo.ErrNoDocuments {
+		_, err = intermediate_collection.InsertOne(ctx, bson.M{
+			"users_id":     dbUser.UsersID,
+			"project_name": req.Project_Name,
+			"ir_rules":     req.IRRules,
+			"ir":           program,
+			"ir_listing":   listing,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database Insertion error"})
+			return
+		}
+	} else if err == nil {
+		update_existing := bson.D{
+			bson.E{Key: "$set", Value: bson.M{
+				"ir_rules":   req.IRRules,
+				"ir":         program,
+				"ir_listing": listing,
+			}},
+		}
+		_, err = intermediate_collection.UpdateOne(ctx, filters, update_existing)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database Update error"})
+			return
+		}
+	} else {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database lookup error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Intermediate representation successfully inserted.",
+		"ir":      program,
+		"listing": listing,
+	})
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"time"
 
@@ -15,19 +16,71 @@ import (
 
 type ReadGrammerFromUser struct {
 	// User's defined variables
-	Vars []string `json:"variables" binding:"required" example:"S, Decl"`
+	Vars []string `json:"variables" example:"S, Decl"`
 	// User's defined terminal variables
-	Terminals []string `json:"terminals" binding:"required" example:"KEYWORD, IDENTIFIER, OPERATOR, NUMBER, PUNCTUATION"`
+	Terminals []string `json:"terminals" example:"KEYWORD, IDENTIFIER, OPERATOR, NUMBER, PUNCTUATION"`
 	// User's defined start variable
-	StartVar string `json:"start" binding:"required" example:"S"`
+	StartVar string `json:"start" example:"S"`
 	// User's defined rules
-	Rules []services.ParsingRule `json:"rules" binding:"required"`
+	Rules []services.ParsingRule `json:"rules"`
+	// User's grammar in BNF/EBNF notation, used instead of the variables, terminals, start and rules
+	Notation string `json:"notation" example:"S ::= Decl { Decl }"`
+	// User's terminals at which the parser resumes after a syntax error
+	SyncTerminals []string `json:"sync_terminals" example:"PUNCTUATION"`
+	// User's operator declarations with their precedence and associativity
+	Operators []services.Operator `json:"operators"`
+	// User's expression variables, parsed using the operator declarations
+	Expressions []string `json:"expressions" example:"Expr"`
 	// User's project name
 	Project_Name string `json:"project_name" binding:"required"`
 }
 
+type CreateTreeRequest struct {
+	// User's annotations for building the abstract syntax tree
+	ASTRules []services.ASTRule `json:"ast_rules"`
+	// User's project name
+	Project_Name string `json:"project_name" binding:"required"`
+}
+
+type TreeStringRequest struct {
+	// Format of the tree string: text, dot, sexpr, forest or qtree
+	Format string `json:"format" example:"text"`
+	// User's project name
+	Project_Name string `json:"project_name" binding:"required"`
+}
+
+type AmbiguityRequest struct {
+	// Maximum number of terminals in an enumerated sentence
+	MaxLength int `json:"max_length" example:"8"`
+	// Maximum depth of an enumerated parse tree
+	MaxDepth int `json:"max_depth" example:"12"`
+	// Time budget of the search in milliseconds
+	TimeBudget int `json:"time_budget" example:"2000"`
+	// User's project name
+	Project_Name string `json:"project_name" binding:"required"`
+}
+
+type GenerateRequest struct {
+	// Number of sentences to generate
+	Samples int `json:"samples" example:"5"`
+	// Maximum depth of a generated syntax tree before the shortest rules are chosen
+	MaxDepth int `json:"max_depth" example:"10"`
+	// Seed of the random generator, a new seed is used when it is zero
+	Seed int64 `json:"seed" example:"42"`
+	// Whether to create source code for the samples using the project's regex rules
+	Source bool `json:"source" example:"true"`
+	// User's project name
+	Project_Name string `json:"project_name" binding:"required"`
+}
+
+// Longest time budget in milliseconds allowed for an ambiguity check
+const max_ambiguity_budget = 5000
+
+// Most sentences that can be generated in one request
+const max_generated_samples = 100
+
 // @Summary Processs and store user-defined grammer
-// @Description Accepts grammar variables, terminals, start variable, and rules from the user and stores them in the database. If it already exists, it updates the current grammar
+// @Description Accepts grammar variables, terminals, start variable, and rules from the user, or the grammar in BNF/EBNF notation, and stores them in the database. If it already exists, it updates the current grammar
 // @Tags Parsing
 // @Accept json
 // @Produce json
@@ -50,6 +103,46 @@ func ReadGrammar(c *gin.Context) {
 		return
 	}
 
+	if req.Notation == "" && (req.Vars == nil || req.Terminals == nil || req.StartVar == "" || req.Rules == nil) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input is invalid", "details": "either the grammar notation or the variables, terminals, start and rules are required"})
+		return
+	}
+
+	var grammar services.Grammar
+	var err error
+
+	if req.Notation != "" {
+		grammar, err = services.ReadGrammarNotation(req.Notation)
+		if err == nil {
+			grammar.SyncTerminals = req.SyncTerminals
+			grammar.Operators = req.Operators
+			grammar.Expressions = req.Expressions
+			grammar, err = services.ValidateGrammar(grammar)
+		}
+	} else {
+		users_grammer_rules := services.Grammar{
+			Variables:     req.Vars,
+			Terminals:     req.Terminals,
+			Start:         req.StartVar,
+			Rules:         req.Rules,
+			SyncTerminals: req.SyncTerminals,
+			Operators:     req.Operators,
+			Expressions:   req.Expressions,
+		}
+
+		json_as_bytes, marshal_err := json.Marshal(users_grammer_rules)
+		if marshal_err != nil {
+			panic(marshal_err)
+		}
+
+		grammar, err = services.ReadGrammar(json_as_bytes)
+	}
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Grammar creation failed", "details": err.Error()})
+		return
+	}
+
 	mongo_cli := db.ConnectClient()
 	users_collection := mongo_cli.Database("visual-compiler").Collection("users")
 	collection := mongo_cli.Database("visual-compiler").Collection("parsing")
@@ -62,29 +155,12 @@ func ReadGrammar(c *gin.Context) {
 		Auth0ID string        `bson:"auth0_id"`
 	}
 
-	err := users_collection.FindOne(ctx, bson.M{"auth0_id": authID}).Decode(&dbUser)
+	err = users_collection.FindOne(ctx, bson.M{"auth0_id": authID}).Decode(&dbUser)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
 		return
 	}
 
-	users_grammer_rules := services.Grammar{
-		Variables: req.Vars,
-		Terminals: req.Terminals,
-		Start:     req.StartVar,
-		Rules:     req.Rules,
-	}
-
-	json_as_bytes, err := json.Marshal(users_grammer_rules)
-	if err != nil {
-		panic(err)
-	}
-
-	grammar, err := services.ReadGrammar(json_as_bytes)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Grammar creation failed", "details": err.Error()})
-	}
-
 	filters := bson.M{"users_id": dbUser.UsersID, "project_name": req.Project_Name}
 	var userexisting bson.M
 
@@ -103,8 +179,10 @@ func ReadGrammar(c *gin.Context) {
 	} else if err == nil {
 		update_existing := bson.D{
 			bson.E{Key: "$unset", Value: bson.M{
-				"tree":        "",
-				"tree_string": "",
+				"tree":          "",
+				"tree_string":   "",
+				"ast":           "",
+				"syntax_errors": "",
 			}},
 			bson.E{Key: "$set", Value: bson.M{
 				"grammar": grammar,
@@ -120,15 +198,18 @@ func ReadGrammar(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Grammar successfully inserted. Ready to create Syntax Tree"})
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Grammar successfully inserted. Ready to create Syntax Tree",
+		"notation": services.ConvertGrammarToNotation(grammar),
+	})
 }
 
 // @Summary Create and store syntax tree from stored grammar and tokens
-// @Description Searches database for Grammar and Tokens. If found, and creates and stores the tree.
+// @Description Searches database for Grammar and Tokens. If found, and creates and stores the tree. The abstract syntax tree is derived from the tree using the optional AST rules and stored alongside it. When the grammar has synchronising terminals, syntax errors are recovered from and the partial tree is stored with the list of syntax errors.
 // @Tags Parsing
 // @Accept json
 // @Produce json
-// @Param request body ProjectNameRequest true "Create syntax tree"
+// @Param request body CreateTreeRequest true "Create syntax tree"
 // @Success 200 {object} map[string]string "Syntax tree successfully created and stored/updated"
 // @Failure 400 {object} map[string]string "Invalid input or Syntax Tree failed to insert"
 // @Failure 404 {object} map[string]string "Tokens or Grammer not found"
@@ -141,7 +222,7 @@ func CreateSyntaxTree(c *gin.Context) {
 		return
 	}
 
-	var req ProjectNameRequest
+	var req CreateTreeRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Input is invalid", "details": err.Error()})
@@ -187,15 +268,29 @@ func CreateSyntaxTree(c *gin.Context) {
 		return
 	}
 
+	syntax_errors := []services.SyntaxError{}
+
 	tree, err := services.CreateSyntaxTree(lexing_res.Tokens, parsing_res.Grammar)
+	if err != nil && len(parsing_res.Grammar.SyncTerminals) > 0 {
+		tree, syntax_errors, err = services.CreateRecoveredSyntaxTree(lexing_res.Tokens, parsing_res.Grammar)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Syntax Tree creation failed", "details": err.Error(), "syntax_errors": syntax_errors})
+		return
+	}
+
+	ast, err := services.CreateAbstractSyntaxTree(tree, req.ASTRules)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Syntax Tree creation failed", "details": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Abstract Syntax Tree creation failed", "details": err.Error()})
 		return
 	}
 
 	filters := bson.M{"users_id": dbUser.UsersID, "project_name": req.Project_Name}
 	update_users_tree := bson.M{"$set": bson.M{
-		"tree": tree,
+		"tree":          tree,
+		"ast":           ast,
+		"ast_rules":     req.ASTRules,
+		"syntax_errors": syntax_errors,
 	}}
 
 	_, err = parsing_collection.UpdateOne(ctx, filters, update_users_tree)
@@ -204,18 +299,432 @@ func CreateSyntaxTree(c *gin.Context) {
 		return
 	}
 
+	message := "Successfully created Syntax Tree"
+	if len(syntax_errors) > 0 {
+		message = "Syntax Tree created with syntax errors"
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       message,
+		"tree":          tree,
+		"ast":           ast,
+		"syntax_errors": syntax_errors,
+	})
+}
+
+// @Summary Trace the parser over the stored grammar and tokens
+// @Description Searches database for Grammar and Tokens. If found, parses the tokens while recording every rule attempt, match and backtrack. Returns the trace and the leftmost derivation of the tree. The trace is also returned when the tokens are not accepted
+// @Tags Parsing
+// @Accept json
+// @Produce json
+// @Param request body ProjectNameRequest true "Trace the parser"
+// @Success 200 {object} map[string]string "Parse trace created"
+// @Failure 400 {object} map[string]string "Invalid input"
+// @Failure 404 {object} map[string]string "Tokens or Grammer not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /parsing/trace [post]
+func ParseTrace(c *gin.Context) {
+	authID, is_existing := c.Get("auth0_id")
+	if !is_existing {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req ProjectNameRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input is invalid", "details": err.Error()})
+		return
+	}
+
+	mongo_cli := db.ConnectClient()
+	users_collection := mongo_cli.Database("visual-compiler").Collection("users")
+	lexing_collection := mongo_cli.Database("visual-compiler").Collection("lexing")
+	parsing_collection := mongo_cli.Database("visual-compiler").Collection("parsing")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var dbUser struct {
+		UsersID bson.ObjectID `bson:"_id"`
+		Auth0ID string        `bson:"auth0_id"`
+	}
+
+	err := users_collection.FindOne(ctx, bson.M{"auth0_id": authID}).Decode(&dbUser)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	var lexing_res struct {
+		Tokens []services.TypeValue `bson:"tokens"`
+	}
+
+	var parsing_res struct {
+		Grammar services.Grammar `bson:"grammar"`
+	}
+
+	err = lexing_collection.FindOne(ctx, bson.M{"users_id": dbUser.UsersID, "project_name": req.Project_Name}).Decode(&lexing_res)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tokens code not found. Please go back to lexing"})
+		return
+	}
+
+	err = parsing_collection.FindOne(ctx, bson.M{"users_id": dbUser.UsersID, "project_name": req.Project_Name}).Decode(&parsing_res)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Grammar code not found. Please create one"})
+		return
+	}
+
+	tree, trace, err := services.CreateTracedSyntaxTree(lexing_res.Tokens, parsing_res.Grammar)
+	if err != nil {
+		if trace == nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Parse trace failed", "details": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message":  "Tokens were not accepted by the grammar",
+			"accepted": false,
+			"details":  err.Error(),
+			"trace":    trace,
+		})
+		return
+	}
+
+	derivation, err := services.CreateDerivation(tree, parsing_res.Grammar)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Derivation failed", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Successfully traced the parser",
+		"accepted":   true,
+		"trace":      trace,
+		"derivation": derivation,
+	})
+}
+
+// @Summary Check the stored grammar for ambiguity
+// @Description Searches database for Grammar. If found, enumerates the sentences of the grammar up to the maximum length and tree depth until the time budget in milliseconds runs out. If a sentence has two parse trees, the sentence and both trees are returned
+// @Tags Parsing
+// @Accept json
+// @Produce json
+// @Param request body AmbiguityRequest true "Check grammar for ambiguity"
+// @Success 200 {object} map[string]string "Ambiguity check completed"
+// @Failure 400 {object} map[string]string "Invalid input"
+// @Failure 404 {object} map[string]string "Grammer not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /parsing/ambiguity [post]
+func DetectAmbiguity(c *gin.Context) {
+	authID, is_existing := c.Get("auth0_id")
+	if !is_existing {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req AmbiguityRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input is invalid", "details": err.Error()})
+		return
+	}
+
+	if req.TimeBudget > max_ambiguity_budget {
+		req.TimeBudget = max_ambiguity_budget
+	}
+
+	mongo_cli := db.ConnectClient()
+	users_collection := mongo_cli.Database("visual-compiler").Collection("users")
+	parsing_collection := mongo_cli.Database("visual-compiler").Collection("parsing")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var dbUser struct {
+		UsersID bson.ObjectID `bson:"_id"`
+		Auth0ID string        `bson:"auth0_id"`
+	}
+
+	err := users_collection.FindOne(ctx, bson.M{"auth0_id": authID}).Decode(&dbUser)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	var parsing_res struct {
+		Grammar services.Grammar `bson:"grammar"`
+	}
+
+	err = parsing_collection.FindOne(ctx, bson.M{"users_id": dbUser.UsersID, "project_name": req.Project_Name}).Decode(&parsing_res)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Grammar code not found. Please create one"})
+		return
+	}
+
+	result, err := services.DetectAmbiguity(parsing_res.Grammar, services.AmbiguityOptions{
+		MaxLength:  req.MaxLength,
+		MaxDepth:   req.MaxDepth,
+		TimeBudget: req.TimeBudget,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ambiguity check failed", "details": err.Error()})
+		return
+	}
+
+	message := "No ambiguity found within the bounds"
+	if result.Ambiguous {
+		message = "Grammar is ambiguous"
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"result":  result,
+	})
+}
+
+// @Summary Generate random sentences from the stored grammar
+// @Description Searches database for Grammar. If found, generates random sentences of the grammar with their syntax trees, using every rule at least once where possible. When source is set, the project's regex rules are used to create source code for each sentence
+// @Tags Parsing
+// @Accept json
+// @Produce json
+// @Param request body GenerateRequest true "Generate sentences"
+// @Success 200 {object} map[string]string "Sentences generated"
+// @Failure 400 {object} map[string]string "Invalid input"
+// @Failure 404 {object} map[string]string "Grammer or regex rules not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /parsing/generate [post]
+func GenerateSentences(c *gin.Context) {
+	authID, is_existing := c.Get("auth0_id")
+	if !is_existing {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req GenerateRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input is invalid", "details": err.Error()})
+		return
+	}
+
+	if req.Samples > max_generated_samples {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input is invalid", "details": fmt.Sprintf("at most %d samples can be generated", max_generated_samples)})
+		return
+	}
+
+	mongo_cli := db.ConnectClient()
+	users_collection := mongo_cli.Database("visual-compiler").Collection("users")
+	lexing_collection := mongo_cli.Database("visual-compiler").Collection("lexing")
+	parsing_collection := mongo_cli.Database("visual-compiler").Collection("parsing")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var dbUser struct {
+		UsersID bson.ObjectID `bson:"_id"`
+		Auth0ID string        `bson:"auth0_id"`
+	}
+
+	err := users_collection.FindOne(ctx, bson.M{"auth0_id": authID}).Decode(&dbUser)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	var parsing_res struct {
+		Grammar services.Grammar `bson:"grammar"`
+	}
+
+	err = parsing_collection.FindOne(ctx, bson.M{"users_id": dbUser.UsersID, "project_name": req.Project_Name}).Decode(&parsing_res)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Grammar code not found. Please create one"})
+		return
+	}
+
+	var lexing_res struct {
+		Rules []services.TypeRegex `bson:"rules"`
+	}
+
+	if req.Source {
+		err = lexing_collection.FindOne(ctx, bson.M{"users_id": dbUser.UsersID, "project_name": req.Project_Name}).Decode(&lexing_res)
+		if err != nil || len(lexing_res.Rules) == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Regex rules not found. Please go back to lexing"})
+			return
+		}
+	}
+
+	result, err := services.GenerateSentences(parsing_res.Grammar, services.GeneratorOptions{
+		Samples:  req.Samples,
+		MaxDepth: req.MaxDepth,
+		Seed:     req.Seed,
+	}, lexing_res.Rules)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Sentence generation failed", "details": err.Error()})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Successfully created Syntax Tree",
-		"tree":    tree,
+		"message":   "Successfully generated sentences",
+		"samples":   result.Samples,
+		"uncovered": result.Uncovered,
+	})
+}
+
+// @Summary Convert the stored grammar to Chomsky normal form
+// @Description Searches database for Grammar. If found, converts it to Chomsky normal form and returns the grammar after each step: adding a start variable, removing ε rules, unit rules and useless symbols, and splitting the rules
+// @Tags Parsing
+// @Accept json
+// @Produce json
+// @Param request body ProjectNameRequest true "Convert grammar to CNF"
+// @Success 200 {object} map[string]string "Grammar converted"
+// @Failure 400 {object} map[string]string "Invalid input"
+// @Failure 404 {object} map[string]string "Grammer not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /parsing/cnf [post]
+func ConvertToCNF(c *gin.Context) {
+	authID, is_existing := c.Get("auth0_id")
+	if !is_existing {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req ProjectNameRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input is invalid", "details": err.Error()})
+		return
+	}
+
+	mongo_cli := db.ConnectClient()
+	users_collection := mongo_cli.Database("visual-compiler").Collection("users")
+	parsing_collection := mongo_cli.Database("visual-compiler").Collection("parsing")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var dbUser struct {
+		UsersID bson.ObjectID `bson:"_id"`
+		Auth0ID string        `bson:"auth0_id"`
+	}
+
+	err := users_collection.FindOne(ctx, bson.M{"auth0_id": authID}).Decode(&dbUser)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	var parsing_res struct {
+		Grammar services.Grammar `bson:"grammar"`
+	}
+
+	err = parsing_collection.FindOne(ctx, bson.M{"users_id": dbUser.UsersID, "project_name": req.Project_Name}).Decode(&parsing_res)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Grammar code not found. Please create one"})
+		return
+	}
+
+	cnf, steps, err := services.ConvertToCNF(parsing_res.Grammar)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Chomsky normal form conversion failed", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Successfully converted grammar to Chomsky normal form",
+		"grammar": cnf,
+		"steps":   steps,
+	})
+}
+
+// @Summary Parse the stored tokens with the CYK algorithm
+// @Description Searches database for Grammar and Tokens. If found, parses the tokens with the CYK algorithm over the Chomsky normal form of the grammar. Returns the filled table and, when the tokens are accepted, the syntax tree using the symbols of the original grammar
+// @Tags Parsing
+// @Accept json
+// @Produce json
+// @Param request body ProjectNameRequest true "Parse with CYK"
+// @Success 200 {object} map[string]string "CYK table created"
+// @Failure 400 {object} map[string]string "Invalid input"
+// @Failure 404 {object} map[string]string "Tokens or Grammer not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /parsing/cyk [post]
+func ParseCYK(c *gin.Context) {
+	authID, is_existing := c.Get("auth0_id")
+	if !is_existing {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req ProjectNameRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input is invalid", "details": err.Error()})
+		return
+	}
+
+	mongo_cli := db.ConnectClient()
+	users_collection := mongo_cli.Database("visual-compiler").Collection("users")
+	lexing_collection := mongo_cli.Database("visual-compiler").Collection("lexing")
+	parsing_collection := mongo_cli.Database("visual-compiler").Collection("parsing")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var dbUser struct {
+		UsersID bson.ObjectID `bson:"_id"`
+		Auth0ID string        `bson:"auth0_id"`
+	}
+
+	err := users_collection.FindOne(ctx, bson.M{"auth0_id": authID}).Decode(&dbUser)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	var lexing_res struct {
+		Tokens []services.TypeValue `bson:"tokens"`
+	}
+
+	var parsing_res struct {
+		Grammar services.Grammar `bson:"grammar"`
+	}
+
+	err = lexing_collection.FindOne(ctx, bson.M{"users_id": dbUser.UsersID, "project_name": req.Project_Name}).Decode(&lexing_res)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tokens code not found. Please go back to lexing"})
+		return
+	}
+
+	err = parsing_collection.FindOne(ctx, bson.M{"users_id": dbUser.UsersID, "project_name": req.Project_Name}).Decode(&parsing_res)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Grammar code not found. Please create one"})
+		return
+	}
+
+	result, err := services.CreateCYKSyntaxTree(lexing_res.Tokens, parsing_res.Grammar)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "CYK parse failed", "details": err.Error()})
+		return
+	}
+
+	message := "Tokens were not accepted by the grammar"
+	if result.Accepted {
+		message = "Successfully parsed the tokens with CYK"
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"result":  result,
 	})
 }
 
 // @Summary Create and store syntax tree as a string from stored tree
-// @Description Searches database for an existing syntax tree. If found, and creates and stores the tree as a string.
+// @Description Searches database for an existing syntax tree. If found, and creates and stores the tree as a string. The format can be text (default), dot (Graphviz), sexpr (S-expression), forest or qtree (LaTeX). Only the text format is stored.
 // @Tags Parsing
 // @Accept json
 // @Produce json
-// @Param request body ProjectNameRequest true "Convert tree to string"
+// @Param request body TreeStringRequest true "Convert tree to string"
 // @Success 200 {object} map[string]string "Syntax tree String successfully created and stored/updated"
 // @Failure 400 {object} map[string]string "Invalid input or Syntax Tree String failed to insert"
 // @Failure 404 {object} map[string]string "Syntax Tree not found"
@@ -228,7 +737,7 @@ func TreeToString(c *gin.Context) {
 		return
 	}
 
-	var req ProjectNameRequest
+	var req TreeStringRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Input is invalid", "details": err.Error()})
@@ -263,7 +772,20 @@ func TreeToString(c *gin.Context) {
 		return
 	}
 
-	tree_as_string := services.ConvertTreeToString(res.Tree.Root, "", true)
+	tree_as_string, err := services.ExportTree(res.Tree, req.Format)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input is invalid", "details": err.Error()})
+		return
+	}
+
+	if req.Format != "" && req.Format != "text" {
+		c.JSON(http.StatusOK, gin.H{
+			"message":     "Successfully generated Syntax Tree into a string",
+			"format":      req.Format,
+			"tree_string": tree_as_string,
+		})
+		return
+	}
 
 	filters := bson.M{"users_id": dbUser.UsersID, "project_name": req.Project_Name}
 	update_users_tree_string := bson.M{"$set": bson.M{
@@ -278,6 +800,7 @@ func TreeToString(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{
 		"message":     "Successfully generated Syntax Tree into a string",
+		"format":      "text",
 		"tree_string": tree_as_string,
 	})
 }
@@ -340,3 +863,24 @@ func GetTree(c *gin.Context) {
 		"tree":    res.Tree,
 	})
 }
+
+// Name: SelectSyntaxTree
+//
+// Parameters: string, SyntaxTree, SyntaxTree
+//
+// Return: SyntaxTree, error
+//
+// Chooses the parse tree or the abstract syntax tree for the later phases. The parse tree is used by default
+func SelectSyntaxTree(tree_source string, tree services.SyntaxTree, ast services.SyntaxTree) (services.SyntaxTree, error) {
+	switch tree_source {
+	case "", "tree":
+		return tree, nil
+	case "ast":
+		if ast.Root == nil {
+			return services.SyntaxTree{}, fmt.Errorf("abstract syntax tree not found. Please recreate the syntax tree")
+		}
+		return ast, nil
+	default:
+		return services.SyntaxTree{}, fmt.Errorf("unknown tree source: %v. Use tree or ast", tree_source)
+	}
+}
@@ -224,6 +224,7 @@ func DeleteProject(c *gin.Context) {
 	lexing_collection := mongo_cli.Database("visual-compiler").Collection("lexing")
 	parsing_collection := mongo_cli.Database("visual-compiler").Collection("parsing")
 	analysing_collection := mongo_cli.Database("visual-compiler").Collection("analysing")
+	intermediate_collection := mongo_cli.Database("visual-compiler").Collection("intermediate")
 	translating_collection := mongo_cli.Database("visual-compiler").Collection("translating")
 	optimising_collection := mongo_cli.Database("visual-compiler").Collection("optimising")
 
@@ -276,6 +277,11 @@ func DeleteProject(c *gin.Context) {
 		return
 	}
 
+	if _, err := intermediate_collection.DeleteOne(ctx, filters_for_collections); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete project"})
+		return
+	}
+
 	if _, err := translating_collection.DeleteOne(ctx, filters_for_collections); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete project"})
 		return
@@ -315,7 +321,7 @@ func GetProject(c *gin.Context) {
 	database := "visual-compiler"
 
 	users_collection := mongo_cli.Database("visual-compiler").Collection("users")
-	db_collections := []string{"lexing", "parsing", "analysing", "translating", "optimising"}
+	db_collections := []string{"lexing", "parsing", "analysing", "intermediate", "translating", "optimising"}
 	res := make(map[string]any)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"time"
 
@@ -11,17 +12,45 @@ import (
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
 type TranslatorRules struct {
-	// Translation Rules
-	Rules []services.TranslationRule `json:"translation_rules" binding:"required"`
+	// Translation Rules matched against sequences of tokens
+	Rules []services.TranslationRule `json:"translation_rules"`
+	// Translation Rules keyed on the productions of the syntax tree
+	TreeRules []services.TreeTranslationRule `json:"tree_rules"`
 	// User's project name
 	Project_Name string `json:"project_name" binding:"required"`
 }
 
+type TranslateRequest struct {
+	// Tree to translate, either the parse tree (tree) or the abstract syntax tree (ast)
+	TreeSource string `json:"tree_source" example:"tree"`
+	// Translation mode, either token sequences (sequence), the productions of the tree (tree) or the intermediate representation to the stack machine (vm)
+	Mode string `json:"mode" example:"sequence"`
+	// User's project name
+	Project_Name string `json:"project_name" binding:"required"`
+}
+
+type RunRequest struct {
+	// Assembly of the stack machine to run. The translated code of the project is run when it is empty
+	Code []string `json:"code"`
+	// Input read by the program, as whitespace-separated numbers
+	Stdin string `json:"stdin"`
+	// Most instructions the program may execute
+	InstructionLimit int `json:"instruction_limit" example:"100000"`
+	// Whether to return the state of the machine before each instruction, up to the first 1000 instructions
+	Trace bool `json:"trace"`
+	// User's project name
+	Project_Name string `json:"project_name" binding:"required"`
+}
+
+// Most instructions a program may be allowed to execute
+const max_instruction_limit = 1000000
+
 // @Summary Create translation rules
-// @Description Takes the user's rules, reads it, verifies it and stores it with the user's ID. If translation rules already exist, it overwrites those rules and removes any other created fields (translation)
+// @Description Takes the user's rules, reads it, verifies it and stores it with the user's ID. The rules are either sequence rules (translation_rules), tree rules (tree_rules) or both. If translation rules already exist, it overwrites those rules and removes any other created fields (translation)
 // @Tags Translating
 // @Accept json
 // @Produce json
@@ -45,14 +74,37 @@ func ReadRules(c *gin.Context) {
 		return
 	}
 
-	json_as_bytes, err := json.Marshal(req.Rules)
-	if err != nil {
-		panic(err)
+	if len(req.Rules) == 0 && len(req.TreeRules) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input is invalid", "details": "no translation rules entered"})
+		return
 	}
 
-	rules, err := services.ReadTranslationRules(json_as_bytes)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Regex rule creation failed"})
+	rules := []services.TranslationRule{}
+	if len(req.Rules) > 0 {
+		json_as_bytes, err := json.Marshal(req.Rules)
+		if err != nil {
+			panic(err)
+		}
+
+		rules, err = services.ReadTranslationRules(json_as_bytes)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Translation rule creation failed", "details": err.Error()})
+			return
+		}
+	}
+
+	tree_rules := []services.TreeTranslationRule{}
+	if len(req.TreeRules) > 0 {
+		json_as_bytes, err := json.Marshal(req.TreeRules)
+		if err != nil {
+			panic(err)
+		}
+
+		tree_rules, err = services.ReadTreeTranslationRules(json_as_bytes)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Tree translation rule creation failed", "details": err.Error()})
+			return
+		}
 	}
 
 	mongo_cli := db.ConnectClient()
@@ -67,7 +119,7 @@ func ReadRules(c *gin.Context) {
 		Auth0ID string        `bson:"auth0_id"`
 	}
 
-	err = users_collection.FindOne(ctx, bson.M{"auth0_id": authID}).Decode(&dbUser)
+	err := users_collection.FindOne(ctx, bson.M{"auth0_id": authID}).Decode(&dbUser)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
 		return
@@ -80,9 +132,10 @@ func ReadRules(c *gin.Context) {
 
 	if err == mongo.ErrNoDocuments {
 		_, err = collection.InsertOne(ctx, bson.M{
-			"translating_rules": rules,
-			"users_id":          dbUser.UsersID,
-			"project_name":      req.Project_Name,
+			"translating_rules":      rules,
+			"translating_tree_rules": tree_rules,
+			"users_id":               dbUser.UsersID,
+			"project_name":           req.Project_Name,
 		})
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database Insertion error"})
@@ -94,7 +147,8 @@ func ReadRules(c *gin.Context) {
 				"translation": "",
 			}},
 			bson.E{Key: "$set", Value: bson.M{
-				"translating_rules": rules,
+				"translating_rules":      rules,
+				"translating_tree_rules": tree_rules,
 			}},
 		}
 		_, err = collection.UpdateOne(ctx, filters, update_existing)
@@ -111,11 +165,11 @@ func ReadRules(c *gin.Context) {
 }
 
 // @Summary Translates syntax tree using the translation rules into code
-// @Description Searches the database for the user's Syntax tree and Translation Rules. If found, both are used to translate the tree into code. The code is either created or ,if already existing, updated. If the syntax tree and translation rules are not found, returns an error
+// @Description Searches the database for the user's Syntax tree and Translation Rules. If found, both are used to translate the tree into code, matching sequences of tokens (mode sequence) or the productions of the tree (mode tree). Mode vm instead translates the stored intermediate representation to the assembly of the stack machine. The code is either created or ,if already existing, updated. If the syntax tree and translation rules are not found, returns an error
 // @Tags Translating
 // @Accept json
 // @Produce json
-// @Param request body ProjectNameRequest true "Create Code from Syntax Tree and Translation Rules"
+// @Param request body TranslateRequest true "Create Code from Syntax Tree and Translation Rules"
 // @Success 200 {object} map[string]string "Code successfully created and stored"
 // @Failure 400 {object} map[string]string "Invalid input/Conversion failed"
 // @Failure 401 {object} map[string]string "Unauthorized"
@@ -129,7 +183,7 @@ func TranslateCode(c *gin.Context) {
 		return
 	}
 
-	var req ProjectNameRequest
+	var req TranslateRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Input is invalid", "details": err.Error()})
@@ -139,6 +193,7 @@ func TranslateCode(c *gin.Context) {
 	mongo_cli := db.ConnectClient()
 	users_collection := mongo_cli.Database("visual-compiler").Collection("users")
 	parsing_collection := mongo_cli.Database("visual-compiler").Collection("parsing")
+	intermediate_collection := mongo_cli.Database("visual-compiler").Collection("intermediate")
 	translating_collection := mongo_cli.Database("visual-compiler").Collection("translating")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -155,45 +210,186 @@ func TranslateCode(c *gin.Context) {
 		return
 	}
 
-	var parsing_res struct {
-		Tree services.SyntaxTree `bson:"tree"`
-	}
+	filters := bson.M{"users_id": dbUser.UsersID, "project_name": req.Project_Name}
+	var translated_code []string
 
-	var translating_res struct {
-		Rules []services.TranslationRule `bson:"translating_rules"`
+	if req.Mode == "vm" {
+		var intermediate_res struct {
+			IR services.IRProgram `bson:"ir"`
+		}
+
+		err = intermediate_collection.FindOne(ctx, filters).Decode(&intermediate_res)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Intermediate representation not found. Please go back to intermediate code generation"})
+			return
+		}
+
+		translated_code, err = services.EmitVMCode(intermediate_res.IR)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Code creation failed", "details": err.Error()})
+			return
+		}
+	} else {
+		var parsing_res struct {
+			Tree         services.SyntaxTree    `bson:"tree"`
+			AST          services.SyntaxTree    `bson:"ast"`
+			SyntaxErrors []services.SyntaxError `bson:"syntax_errors"`
+		}
+
+		var translating_res struct {
+			Rules     []services.TranslationRule     `bson:"translating_rules"`
+			TreeRules []services.TreeTranslationRule `bson:"translating_tree_rules"`
+		}
+
+		err = parsing_collection.FindOne(ctx, bson.M{"users_id": dbUser.UsersID, "project_name": req.Project_Name}).Decode(&parsing_res)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Tree not found. Please go back to parsing"})
+			return
+		}
+
+		err = translating_collection.FindOne(ctx, bson.M{"users_id": dbUser.UsersID, "project_name": req.Project_Name}).Decode(&translating_res)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Translating rules not found. Please go back to Translation"})
+			return
+		}
+
+		if len(parsing_res.SyntaxErrors) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Syntax Tree contains syntax errors. Please go back to parsing", "syntax_errors": parsing_res.SyntaxErrors})
+			return
+		}
+
+		tree, err := SelectSyntaxTree(req.TreeSource, parsing_res.Tree, parsing_res.AST)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Input is invalid", "details": err.Error()})
+			return
+		}
+
+		translated_code, err = TranslateWithMode(req.Mode, tree, translating_res.Rules, translating_res.TreeRules)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Code creation failed", "details": err.Error()})
+			return
+		}
 	}
 
-	err = parsing_collection.FindOne(ctx, bson.M{"users_id": dbUser.UsersID, "project_name": req.Project_Name}).Decode(&parsing_res)
+	update_users_translating := bson.M{"$set": bson.M{
+		"code": translated_code,
+	}}
+
+	_, err = translating_collection.UpdateOne(ctx, filters, update_users_translating, options.UpdateOne().SetUpsert(true))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Tree not found. Please go back to parsing"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to insert NFA"})
 		return
 	}
 
-	err = translating_collection.FindOne(ctx, bson.M{"users_id": dbUser.UsersID, "project_name": req.Project_Name}).Decode(&translating_res)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Translating rules not found. Please go back to Translation"})
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Successfully translated your code",
+		"code":    translated_code,
+	})
+}
+
+// Name: TranslateWithMode
+//
+// Parameters: string, services.SyntaxTree, []services.TranslationRule, []services.TreeTranslationRule
+//
+// Return: []string, error
+//
+// Translates the syntax tree with the rules of the requested mode. Sequence rules are used by default
+func TranslateWithMode(mode string, tree services.SyntaxTree, rules []services.TranslationRule, tree_rules []services.TreeTranslationRule) ([]string, error) {
+	switch mode {
+	case "", "sequence":
+		if len(rules) == 0 {
+			return nil, fmt.Errorf("no sequence translation rules found. Please go back to Translation")
+		}
+		return services.Translate(tree, rules)
+	case "tree":
+		if len(tree_rules) == 0 {
+			return nil, fmt.Errorf("no tree translation rules found. Please go back to Translation")
+		}
+		return services.TranslateTree(tree, tree_rules)
+	default:
+		return nil, fmt.Errorf("unknown translation mode: %v. Use sequence, tree or vm", mode)
+	}
+}
+
+// @Summary Runs code on the stack machine
+// @Description Assembles the code in the request, or the translated code of the project when no code is given, and runs it on the stack machine with the input in the request. Returns the output, the final stack and variables and the number of executed instructions, with the state before each of the first 1000 instructions when a trace is requested. trace_truncated is set when the program ran longer than the trace. The program stops with an error after the instruction limit
+// @Tags Translating
+// @Accept json
+// @Produce json
+// @Param request body RunRequest true "Run Code on the Stack Machine"
+// @Success 200 {object} map[string]interface{} "Program ran to completion"
+// @Failure 400 {object} map[string]string "Invalid input/Assembly failed/Runtime error"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Code not found"
+// @Router /translating/run [post]
+func RunCode(c *gin.Context) {
+	authID, is_existing := c.Get("auth0_id")
+	if !is_existing {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
 
-	translated_code, err := services.Translate(parsing_res.Tree, translating_res.Rules)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Code creation failed", "details": err.Error()})
+	var req RunRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input is invalid", "details": err.Error()})
 		return
 	}
 
-	filters := bson.M{"users_id": dbUser.UsersID, "project_name": req.Project_Name}
-	update_users_translating := bson.M{"$set": bson.M{
-		"code": translated_code,
-	}}
+	if req.InstructionLimit < 0 || req.InstructionLimit > max_instruction_limit {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input is invalid", "details": fmt.Sprintf("instruction limit must be between 0 and %v", max_instruction_limit)})
+		return
+	}
+
+	code := req.Code
+
+	if len(code) == 0 {
+		mongo_cli := db.ConnectClient()
+		users_collection := mongo_cli.Database("visual-compiler").Collection("users")
+		translating_collection := mongo_cli.Database("visual-compiler").Collection("translating")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		var dbUser struct {
+			UsersID bson.ObjectID `bson:"_id"`
+			Auth0ID string        `bson:"auth0_id"`
+		}
+
+		err := users_collection.FindOne(ctx, bson.M{"auth0_id": authID}).Decode(&dbUser)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+			return
+		}
 
-	_, err = translating_collection.UpdateOne(ctx, filters, update_users_translating)
+		var translating_res struct {
+			Code []string `bson:"code"`
+		}
+
+		err = translating_collection.FindOne(ctx, bson.M{"users_id": dbUser.UsersID, "project_name": req.Project_Name}).Decode(&translating_res)
+		if err != nil || len(translating_res.Code) == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Code not found. Please go back to Translation"})
+			return
+		}
+
+		code = translating_res.Code
+	}
+
+	program, err := services.AssembleVM(code)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to insert NFA"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Assembly failed", "details": err.Error()})
+		return
+	}
+
+	result, err := services.RunVM(program, req.Stdin, req.InstructionLimit, req.Trace)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Program stopped with an error", "details": err.Error(), "result": result, "trace_truncated": result.TraceTruncated})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Successfully translated your code",
-		"code":    translated_code,
+		"message":         "Program ran to completion",
+		"result":          result,
+		"trace_truncated": result.TraceTruncated,
 	})
 }
@@ -15,6 +15,7 @@ import (
 // Creates the endpoints for lexing. Links the endpoints to the respective function
 func SetupAnalysingRouter(r *gin.RouterGroup) *gin.RouterGroup {
 	r.POST("/analyse", handlers.Analyse)
+	r.POST("/attributes", handlers.EvaluateAttributes)
 
 	return r
 }
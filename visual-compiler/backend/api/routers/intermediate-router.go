@@ -0,0 +1,19 @@
+package routers
+
+import (
+	"github.com/COS301-SE-2025/Visual-Compiler/backend/api/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+// Name: SetupIntermediateRouter
+//
+// Parameters: None
+//
+// Return: Endpoints
+//
+// Creates the endpoints for the intermediate representation. Links the endpoints to the respective function
+func SetupIntermediateRouter(r *gin.RouterGroup) *gin.RouterGroup {
+	r.POST("/generate", handlers.GenerateIntermediate)
+
+	return r
+}
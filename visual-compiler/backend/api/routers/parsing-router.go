@@ -18,6 +18,11 @@ func SetupParsingRouter(r *gin.RouterGroup) *gin.RouterGroup {
 	r.POST("/tree", handlers.CreateSyntaxTree)
 	r.POST("/treeString", handlers.TreeToString)
 	r.GET("/getTree", handlers.GetTree)
+	r.POST("/trace", handlers.ParseTrace)
+	r.POST("/ambiguity", handlers.DetectAmbiguity)
+	r.POST("/generate", handlers.GenerateSentences)
+	r.POST("/cnf", handlers.ConvertToCNF)
+	r.POST("/cyk", handlers.ParseCYK)
 
 	return r
 }
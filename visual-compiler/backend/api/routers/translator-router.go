@@ -16,6 +16,7 @@ import (
 func SetupTranslatorRouter(r *gin.RouterGroup) *gin.RouterGroup {
 	r.POST("/readRules", handlers.ReadRules)
 	r.POST("/translate", handlers.TranslateCode)
+	r.POST("/run", handlers.RunCode)
 
 	return r
 }
@@ -16,7 +16,7 @@ func TestSetupAnalysingRouter(t *testing.T) {
 		t.Errorf("SetupRouter function does not initialise router")
 	}
 	endpoints := r.Routes()
-	if len(endpoints) != 1 {
+	if len(endpoints) != 2 {
 		t.Errorf("Amount of routes does not match")
 	}
 }
@@ -42,3 +42,35 @@ func TestAnalyse_Unauthorised(t *testing.T) {
 	}
 
 }
+
+func TestEvaluateAttributes_Unauthorised(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	contxt, rec := createPhaseTestContext(t)
+
+	res, err := http.NewRequest("POST", "/api/analysing/attributes", bytes.NewBuffer([]byte{}))
+	if err != nil {
+		t.Errorf("Request could not be created")
+	}
+	res.Header.Set("Content-Type", "application/json")
+	contxt.Request = res
+
+	handlers.EvaluateAttributes(contxt)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("StatusUnauthorized status code expected")
+	} else {
+		body_bytes, err := io.ReadAll(rec.Body)
+		if err != nil {
+			t.Errorf("Error: %v", err)
+		}
+		var body_array map[string]string
+		err = json.Unmarshal(body_bytes, &body_array)
+		if err != nil {
+			t.Errorf("Error: %v", err)
+		}
+		if body_array["error"] != "Unauthorized" {
+			t.Errorf("Incorrect error")
+		}
+	}
+
+}
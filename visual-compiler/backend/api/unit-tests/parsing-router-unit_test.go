@@ -16,7 +16,7 @@ func TestSetupParsingRouter(t *testing.T) {
 		t.Errorf("SetupRouter function does not initialise router")
 	}
 	endpoints := r.Routes()
-	if len(endpoints) != 4 {
+	if len(endpoints) != 9 {
 		t.Errorf("Amount of routes does not match")
 	}
 }
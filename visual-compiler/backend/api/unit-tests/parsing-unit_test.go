@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/COS301-SE-2025/Visual-Compiler/backend/api/handlers"
+	"github.com/COS301-SE-2025/Visual-Compiler/backend/core/services"
 	"github.com/gin-gonic/gin"
 )
 
@@ -134,3 +135,183 @@ func TestGetree_Unauthorised(t *testing.T) {
 		}
 	}
 }
+
+func TestParseTrace_Unauthorised(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	contxt, rec := createPhaseTestContext(t)
+
+	res, err := http.NewRequest("POST", "/api/parsing/trace", bytes.NewBuffer([]byte{}))
+	if err != nil {
+		t.Errorf("Request could not be created")
+	}
+	res.Header.Set("Content-Type", "application/json")
+	contxt.Request = res
+
+	handlers.ParseTrace(contxt)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("StatusUnauthorized status code expected")
+	} else {
+		body_bytes, err := io.ReadAll(rec.Body)
+		if err != nil {
+			t.Errorf("Error: %v", err)
+		}
+		var body_array map[string]string
+		err = json.Unmarshal(body_bytes, &body_array)
+		if err != nil {
+			t.Errorf("Error: %v", err)
+		}
+		if body_array["error"] != "Unauthorized" {
+			t.Errorf("Incorrect error")
+		}
+	}
+}
+
+func TestDetectAmbiguity_Unauthorised(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	contxt, rec := createPhaseTestContext(t)
+
+	res, err := http.NewRequest("POST", "/api/parsing/ambiguity", bytes.NewBuffer([]byte{}))
+	if err != nil {
+		t.Errorf("Request could not be created")
+	}
+	res.Header.Set("Content-Type", "application/json")
+	contxt.Request = res
+
+	handlers.DetectAmbiguity(contxt)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("StatusUnauthorized status code expected")
+	} else {
+		body_bytes, err := io.ReadAll(rec.Body)
+		if err != nil {
+			t.Errorf("Error: %v", err)
+		}
+		var body_array map[string]string
+		err = json.Unmarshal(body_bytes, &body_array)
+		if err != nil {
+			t.Errorf("Error: %v", err)
+		}
+		if body_array["error"] != "Unauthorized" {
+			t.Errorf("Incorrect error")
+		}
+	}
+}
+
+func TestGenerateSentences_Unauthorised(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	contxt, rec := createPhaseTestContext(t)
+
+	res, err := http.NewRequest("POST", "/api/parsing/generate", bytes.NewBuffer([]byte{}))
+	if err != nil {
+		t.Errorf("Request could not be created")
+	}
+	res.Header.Set("Content-Type", "application/json")
+	contxt.Request = res
+
+	handlers.GenerateSentences(contxt)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("StatusUnauthorized status code expected")
+	} else {
+		body_bytes, err := io.ReadAll(rec.Body)
+		if err != nil {
+			t.Errorf("Error: %v", err)
+		}
+		var body_array map[string]string
+		err = json.Unmarshal(body_bytes, &body_array)
+		if err != nil {
+			t.Errorf("Error: %v", err)
+		}
+		if body_array["error"] != "Unauthorized" {
+			t.Errorf("Incorrect error")
+		}
+	}
+}
+
+func TestConvertToCNF_Unauthorised(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	contxt, rec := createPhaseTestContext(t)
+
+	res, err := http.NewRequest("POST", "/api/parsing/cnf", bytes.NewBuffer([]byte{}))
+	if err != nil {
+		t.Errorf("Request could not be created")
+	}
+	res.Header.Set("Content-Type", "application/json")
+	contxt.Request = res
+
+	handlers.ConvertToCNF(contxt)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("StatusUnauthorized status code expected")
+	} else {
+		body_bytes, err := io.ReadAll(rec.Body)
+		if err != nil {
+			t.Errorf("Error: %v", err)
+		}
+		var body_array map[string]string
+		err = json.Unmarshal(body_bytes, &body_array)
+		if err != nil {
+			t.Errorf("Error: %v", err)
+		}
+		if body_array["error"] != "Unauthorized" {
+			t.Errorf("Incorrect error")
+		}
+	}
+}
+
+func TestParseCYK_Unauthorised(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	contxt, rec := createPhaseTestContext(t)
+
+	res, err := http.NewRequest("POST", "/api/parsing/cyk", bytes.NewBuffer([]byte{}))
+	if err != nil {
+		t.Errorf("Request could not be created")
+	}
+	res.Header.Set("Content-Type", "application/json")
+	contxt.Request = res
+
+	handlers.ParseCYK(contxt)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("StatusUnauthorized status code expected")
+	} else {
+		body_bytes, err := io.ReadAll(rec.Body)
+		if err != nil {
+			t.Errorf("Error: %v", err)
+		}
+		var body_array map[string]string
+		err = json.Unmarshal(body_bytes, &body_array)
+		if err != nil {
+			t.Errorf("Error: %v", err)
+		}
+		if body_array["error"] != "Unauthorized" {
+			t.Errorf("Incorrect error")
+		}
+	}
+}
+
+func TestSelectSyntaxTree(t *testing.T) {
+	tree := services.SyntaxTree{Root: &services.TreeNode{Symbol: "STATEMENT"}}
+	ast := services.SyntaxTree{Root: &services.TreeNode{Symbol: "DECLARE"}}
+
+	selected, err := handlers.SelectSyntaxTree("", tree, ast)
+	if err != nil || selected.Root.Symbol != "STATEMENT" {
+		t.Errorf("Parse tree expected by default")
+	}
+
+	selected, err = handlers.SelectSyntaxTree("ast", tree, ast)
+	if err != nil || selected.Root.Symbol != "DECLARE" {
+		t.Errorf("Abstract syntax tree expected")
+	}
+
+	_, err = handlers.SelectSyntaxTree("ast", tree, services.SyntaxTree{})
+	if err == nil {
+		t.Errorf("Error expected for missing abstract syntax tree")
+	}
+
+	_, err = handlers.SelectSyntaxTree("other", tree, ast)
+	if err == nil {
+		t.Errorf("Error expected for unknown tree source")
+	}
+}
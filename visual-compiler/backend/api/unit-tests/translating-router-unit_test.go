@@ -17,7 +17,7 @@ func TestSetupTranslatingRouter(t *testing.T) {
 	}
 
 	endpoints := r.Routes()
-	if len(endpoints) != 2 {
+	if len(endpoints) != 3 {
 		t.Errorf("Amount of routes does not match")
 	}
 }
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/COS301-SE-2025/Visual-Compiler/backend/api/handlers"
+	"github.com/COS301-SE-2025/Visual-Compiler/backend/core/services"
 	"github.com/gin-gonic/gin"
 )
 
@@ -72,3 +73,63 @@ func TestTranslateCode_Unauthorised(t *testing.T) {
 		}
 	}
 }
+
+func TestRunCode_Unauthorised(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	contxt, rec := createPhaseTestContext(t)
+
+	res, err := http.NewRequest("POST", "/api/translating/run", bytes.NewBuffer([]byte{}))
+	if err != nil {
+		t.Errorf("Request could not be created")
+	}
+	res.Header.Set("Content-Type", "application/json")
+	contxt.Request = res
+
+	handlers.RunCode(contxt)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("StatusUnauthorized status code expected")
+	} else {
+		body_bytes, err := io.ReadAll(rec.Body)
+		if err != nil {
+			t.Errorf("Error: %v", err)
+		}
+		var body_array map[string]string
+		err = json.Unmarshal(body_bytes, &body_array)
+		if err != nil {
+			t.Errorf("Error: %v", err)
+		}
+		if body_array["error"] != "Unauthorized" {
+			t.Errorf("Incorrect error")
+		}
+	}
+}
+
+func TestTranslateWithMode(t *testing.T) {
+	tree := services.SyntaxTree{Root: &services.TreeNode{Symbol: "STATEMENT", Children: []*services.TreeNode{
+		{Symbol: "IDENTIFIER", Value: "x"},
+		{Symbol: "SEPARATOR", Value: ";"},
+	}}}
+	rules := []services.TranslationRule{{Sequence: []string{"IDENTIFIER", "SEPARATOR"}, Translation: []string{"seq {IDENTIFIER}"}}}
+	tree_rules := []services.TreeTranslationRule{{Node: "STATEMENT", Translation: []string{"tree {IDENTIFIER}"}}}
+
+	code, err := handlers.TranslateWithMode("", tree, rules, tree_rules)
+	if err != nil || len(code) != 1 || code[0] != "seq x" {
+		t.Errorf("Sequence translation expected by default but received %v, %v", code, err)
+	}
+
+	code, err = handlers.TranslateWithMode("tree", tree, rules, tree_rules)
+	if err != nil || len(code) != 1 || code[0] != "tree x" {
+		t.Errorf("Tree translation expected but received %v, %v", code, err)
+	}
+
+	_, err = handlers.TranslateWithMode("tree", tree, rules, nil)
+	if err == nil {
+		t.Errorf("Error expected for missing tree translation rules")
+	}
+
+	_, err = handlers.TranslateWithMode("other", tree, rules, tree_rules)
+	if err == nil {
+		t.Errorf("Error expected for unknown translation mode")
+	}
+}
@@ -0,0 +1,293 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Struct for the bounds of the ambiguity search
+type AmbiguityOptions struct {
+	MaxLength  int `json:"max_length"`
+	MaxDepth   int `json:"max_depth"`
+	TimeBudget int `json:"time_budget"`
+}
+
+// Struct for the result of the ambiguity search
+//
+// complete is true when every sentence within the bounds was checked before the time budget ran out
+type AmbiguityResult struct {
+	Ambiguous bool         `json:"ambiguous"`
+	Witness   string       `json:"witness"`
+	Trees     []SyntaxTree `json:"trees"`
+	Sentences int          `json:"sentences"`
+	Complete  bool         `json:"complete"`
+}
+
+// Struct for a sentential form reached by a partial leftmost derivation
+type derivationState struct {
+	symbols []string
+	depths  []int
+	rules   []int
+}
+
+// Default bounds of the ambiguity search
+const (
+	default_max_length  = 8
+	default_max_depth   = 12
+	default_time_budget = 2000
+)
+
+// Name: DetectAmbiguity
+//
+// Parameters: Grammar, AmbiguityOptions
+//
+// Return: AmbiguityResult, error
+//
+// Enumerate the leftmost derivations of the grammar breadth first, up to the maximum sentence length and
+// tree depth. Every leftmost derivation gives a different tree, so two derivations of the same sentence
+// show the grammar is ambiguous. The search stops when the time budget in milliseconds runs out.
+// Only the rules are considered, operator declarations are not
+func DetectAmbiguity(grammar Grammar, options AmbiguityOptions) (AmbiguityResult, error) {
+
+	if grammar.Start == "" {
+		return AmbiguityResult{}, fmt.Errorf("no start variable found")
+	}
+
+	if options.MaxLength <= 0 {
+		options.MaxLength = default_max_length
+	}
+	if options.MaxDepth <= 0 {
+		options.MaxDepth = default_max_depth
+	}
+	if options.TimeBudget <= 0 {
+		options.TimeBudget = default_time_budget
+	}
+
+	rules := []ParsingRule{}
+	for _, rule := range grammar.Rules {
+		duplicate := false
+		for _, existing := range rules {
+			if existing.Input == rule.Input && strings.Join(existing.Output, " ") == strings.Join(rule.Output, " ") {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			rules = append(rules, rule)
+		}
+	}
+
+	minimum := MinimumYield(rules)
+	if _, productive := minimum[grammar.Start]; !productive {
+		return AmbiguityResult{}, fmt.Errorf("start variable does not derive any sentence")
+	}
+
+	variables := make(map[string]bool)
+	for _, rule := range rules {
+		variables[rule.Input] = true
+	}
+
+	deadline := time.Now().Add(time.Duration(options.TimeBudget) * time.Millisecond)
+
+	result := AmbiguityResult{Complete: true}
+	sentences := make(map[string][]int)
+
+	queue := []derivationState{{symbols: []string{grammar.Start}, depths: []int{1}}}
+
+	for steps := 0; len(queue) > 0; steps++ {
+
+		if steps%256 == 0 && time.Now().After(deadline) {
+			result.Complete = false
+			break
+		}
+
+		current := queue[0]
+		queue = queue[1:]
+
+		leftmost := -1
+		for i, symbol := range current.symbols {
+			if variables[symbol] {
+				leftmost = i
+				break
+			}
+		}
+
+		if leftmost < 0 {
+			sentence := strings.Join(current.symbols, " ")
+
+			if previous, exists := sentences[sentence]; exists {
+				result.Ambiguous = true
+				result.Witness = sentence
+				result.Trees = []SyntaxTree{
+					BuildDerivationTree(grammar.Start, rules, previous),
+					BuildDerivationTree(grammar.Start, rules, current.rules),
+				}
+				break
+			}
+
+			sentences[sentence] = current.rules
+			result.Sentences++
+			continue
+		}
+
+		variable := current.symbols[leftmost]
+		depth := current.depths[leftmost]
+
+		for index, rule := range rules {
+			if rule.Input != variable {
+				continue
+			}
+
+			next := derivationState{
+				symbols: append([]string{}, current.symbols[:leftmost]...),
+				depths:  append([]int{}, current.depths[:leftmost]...),
+				rules:   append(append([]int{}, current.rules...), index),
+			}
+
+			valid := true
+			for _, symbol := range rule.Output {
+				if symbol == "ε" {
+					continue
+				}
+				if _, productive := minimum[symbol]; !productive {
+					valid = false
+					break
+				}
+				next.symbols = append(next.symbols, symbol)
+				next.depths = append(next.depths, depth+1)
+			}
+
+			next.symbols = append(next.symbols, current.symbols[leftmost+1:]...)
+			next.depths = append(next.depths, current.depths[leftmost+1:]...)
+
+			if valid && WithinBounds(next, variables, minimum, options) {
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// Name: MinimumYield
+//
+// Parameters: []ParsingRule
+//
+// Return: map[string]int
+//
+// Returns the length of the shortest sentence each productive symbol derives. Symbols without rules
+// are treated as terminals and unproductive variables are left out
+func MinimumYield(rules []ParsingRule) map[string]int {
+
+	minimum := make(map[string]int)
+	has_rules := make(map[string]bool)
+
+	for _, rule := range rules {
+		has_rules[rule.Input] = true
+	}
+
+	for _, rule := range rules {
+		for _, symbol := range rule.Output {
+			if !has_rules[symbol] && symbol != "ε" {
+				minimum[symbol] = 1
+			}
+		}
+	}
+
+	for changed := true; changed; {
+		changed = false
+
+		for _, rule := range rules {
+			length := 0
+			productive := true
+
+			for _, symbol := range rule.Output {
+				if symbol == "ε" {
+					continue
+				}
+				symbol_length, exists := minimum[symbol]
+				if !exists {
+					productive = false
+					break
+				}
+				length += symbol_length
+			}
+
+			if !productive {
+				continue
+			}
+
+			if current, exists := minimum[rule.Input]; !exists || length < current {
+				minimum[rule.Input] = length
+				changed = true
+			}
+		}
+	}
+
+	return minimum
+}
+
+// Name: WithinBounds
+//
+// Parameters: derivationState, map[string]bool, map[string]int, AmbiguityOptions
+//
+// Return: bool
+//
+// Determines if the sentential form can still derive a sentence within the length and depth bounds
+func WithinBounds(state derivationState, variables map[string]bool, minimum map[string]int, options AmbiguityOptions) bool {
+
+	length := 0
+
+	for i, symbol := range state.symbols {
+		length += minimum[symbol]
+
+		if variables[symbol] && state.depths[i] > options.MaxDepth {
+			return false
+		}
+	}
+
+	return length <= options.MaxLength
+}
+
+// Name: BuildDerivationTree
+//
+// Parameters: string, []ParsingRule, []int
+//
+// Return: SyntaxTree
+//
+// Rebuild the syntax tree of a leftmost derivation from the indexes of the rules it applied
+func BuildDerivationTree(start string, rules []ParsingRule, applied []int) SyntaxTree {
+
+	next := 0
+
+	return SyntaxTree{Root: BuildDerivationNode(start, rules, applied, &next)}
+}
+
+// Name: BuildDerivationNode
+//
+// Parameters: string, []ParsingRule, []int, *int
+//
+// Return: *TreeNode
+//
+// Recursively expands the symbol with the next applied rule, in the order of a leftmost derivation
+func BuildDerivationNode(symbol string, rules []ParsingRule, applied []int, next *int) *TreeNode {
+
+	node := &TreeNode{Symbol: symbol}
+
+	if *next >= len(applied) || rules[applied[*next]].Input != symbol {
+		return node
+	}
+
+	rule := rules[applied[*next]]
+	*next++
+
+	node.Children = []*TreeNode{}
+	for _, child := range rule.Output {
+		if child != "ε" {
+			node.Children = append(node.Children, BuildDerivationNode(child, rules, applied, next))
+		}
+	}
+
+	return node
+}
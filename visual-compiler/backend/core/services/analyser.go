@@ -1,34 +1,159 @@
 package services
 
 import (
+	"errors"
 	"fmt"
+	"strings"
+	"text/tabwriter"
 )
 
 // struct to store data on a symbol for the symbol table
+//
+// scope is the depth of the scope the symbol is declared in, scope id the scope in the artefact and
+// node the pre-order number of the tree node the declaration came from. Inferred marks a type that was
+// not declared but inferred from the assignment. Type info holds the full type of arrays, records and functions,
+// and type is then the type as it is compared, except for functions, whose type is their result type. Constant
+// holds the value of a symbol declared from a constant expression
 type Symbol struct {
-	Name       string
-	Type       string
-	Scope      int
-	Parameters []Symbol
-	Assign     bool
-	IsParam    bool
+	Name       string   `json:"name"`
+	Type       string   `json:"type"`
+	Scope      int      `json:"scope"`
+	ScopeID    int      `json:"scope_id"`
+	Node       int      `json:"node"`
+	Parameters []Symbol `json:"parameters,omitempty"`
+	Assign     bool     `json:"assign"`
+	IsParam    bool     `json:"is_param"`
+	IsFunction bool     `json:"is_function"`
+	Inferred   bool     `json:"inferred"`
+	TypeInfo   *Type    `json:"type_info,omitempty"`
+	Constant   string   `json:"constant,omitempty"`
 }
 
 // struct to store data on a symbol table
 type SymbolTable struct {
-	SymbolScopes []map[string]Symbol
+	SymbolScopes     []map[string]Symbol
+	ScopeIDs         []int
+	Nodes            map[*TreeNode]int
+	Paths            map[*TreeNode][]int
+	FunctionScope    bool
+	Diagnostics      []Diagnostic
+	Promotions       []Promotion
+	Decorated        map[*TreeNode]*DecoratedNode
+	Functions        []FunctionContext
+	Conditionals     []ConditionalContext
+	Returning        map[int]bool
+	OpenRules        []*ScopeRule
+	Types            map[string]*Type
+	StructuralTypes  bool
+	TypeRules        []TypeRule
+	DeclaredFunction *FunctionContext
+}
+
+// struct to store data on a function whose body is being analysed.
+//
+// scope id is the scope of the body, which returns on every path when it is marked as returning
+type FunctionContext struct {
+	Symbol  Symbol
+	Node    *TreeNode
+	ScopeID int
+}
+
+// struct to store data on a conditional whose branches are being analysed.
+//
+// scope id is the scope the conditional is in and branches marks, for each branch closed so far, whether it
+// returns on every path. A branch is a scope opened directly in the conditional, or a nested conditional
+type ConditionalContext struct {
+	ScopeID  int
+	Else     bool
+	Branches []bool
+}
+
+// struct to store data on an error found by the analyser
+//
+// path holds the index of the child followed at each level from the root to the offending node
+type Diagnostic struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Symbol  string `json:"symbol"`
+	Path    []int  `json:"path"`
+}
+
+// Codes of the diagnostics reported by the analyser
+const (
+	code_missing_name       = "missing_name"
+	code_redeclared_symbol  = "redeclared_symbol"
+	code_undeclared_symbol  = "undeclared_symbol"
+	code_uninferable_type   = "uninferable_type"
+	code_invalid_assignment = "invalid_assignment"
+	code_invalid_expression = "invalid_expression"
+	code_missing_function   = "undeclared_function"
+	code_argument_count     = "argument_count"
+	code_argument_type      = "argument_type"
+	code_unopened_scope     = "unopened_scope"
+	code_unclosed_scope     = "unclosed_scope"
+	code_mismatched_scope   = "mismatched_scope"
+	code_invalid_type       = "invalid_type"
+	code_invalid_access     = "invalid_access"
+	code_invalid_constant   = "invalid_constant"
+	code_unused_symbol      = "unused_symbol"
+	code_shadowed_symbol    = "shadowed_symbol"
+	code_uninitialised_read = "uninitialised_read"
+	code_unused_parameter   = "unused_parameter"
+	code_return_outside     = "return_outside_function"
+	code_return_type        = "return_type"
+	code_missing_return     = "missing_return"
+)
+
+// Type of the functions that return no value and need no return statement
+const void_type = "void"
+
+// struct to store data on a scope of the symbol table artefact
+//
+// kind is global, function, block or the kind named by the scope rule. The global scope has no parent and a parent of -1
+type Scope struct {
+	ID     int    `json:"id"`
+	Parent int    `json:"parent"`
+	Kind   string `json:"kind"`
+	Node   int    `json:"node"`
+}
+
+// struct to store data on a use of a declared name
+//
+// declaration is the node of the symbol the name resolved to and assign marks a use as the target of an assignment
+type Reference struct {
+	Name        string `json:"name"`
+	Node        int    `json:"node"`
+	Scope       int    `json:"scope"`
+	Declaration int    `json:"declaration"`
+	Assign      bool   `json:"assign"`
+}
+
+// struct to store which warnings the analyser reports
+type WarningOptions struct {
+	Unused           bool `json:"unused"`
+	Shadowed         bool `json:"shadowed"`
+	Uninitialised    bool `json:"uninitialised"`
+	UnusedParameters bool `json:"unused_parameters"`
 }
 
 // struct to store data on a symbol table artefact
 type SymbolTableArtefact struct {
-	SymbolScopes []Symbol
+	SymbolScopes []Symbol    `json:"symbol_scopes"`
+	Scopes       []Scope     `json:"scopes"`
+	References   []Reference `json:"references"`
+	Types        []*Type     `json:"types,omitempty"`
 }
 
-// struct to store data for a scope rule
+// struct to store data for a scope rule.
+//
+// a scope is opened by a node with the start value and closed by the next node with the end value. With a node
+// symbol, a scope is instead opened for the subtree of every node with that symbol, and start and end are not used.
+// Kind names the scope, and without it the scope is a function body or a block
 type ScopeRule struct {
-	Start   string `json:"start"`
-	End     string `json:"end"`
-	Entered bool
+	Start string `json:"start"`
+	End   string `json:"end"`
+	Node  string `json:"node"`
+	Kind  string `json:"kind"`
 }
 
 // struct to store data for valid type rules.
@@ -42,21 +167,42 @@ type TypeRule struct {
 	RHSData    string
 }
 
+// struct to store the symbols of the syntax tree that the analyser looks for.
+//
+// call rule is the node of a function call and argument rule the node of each argument in its
+// argument list. Without an argument rule the term rule is used. Return rule is the node of a return
+// statement, and without it returns are not checked. Index rule is the node indexing an array and field rule
+// the node accessing a field of a record. Type constructors build array, record and function types from nodes,
+// and structural types makes records with the same fields the same type instead of comparing them by name
 type GrammarRules struct {
-	TypeRule       string
-	VariableRule   string
-	FunctionRule   string
-	ParameterRule  string
-	AssignmentRule string
-	OperatorRule   string
-	TermRule       string
+	TypeRule         string
+	VariableRule     string
+	FunctionRule     string
+	ParameterRule    string
+	AssignmentRule   string
+	OperatorRule     string
+	TermRule         string
+	CallRule         string
+	ArgumentRule     string
+	ReturnRule       string
+	IfRule           string
+	ElseRule         string
+	IndexRule        string
+	FieldRule        string
+	TypeConstructors []TypeConstructor
+	StructuralTypes  bool
 }
 
+// struct to store data on an assignment.
+//
+// value types are the possible types of the assigned expression when they have been computed from the tree.
+// Without them the terms are combined with the operator from left to right
 type AssignmentData struct {
 	ResultData Symbol
 	Terms      []Symbol
 	Operator   Symbol
 	Assignment Symbol
+	ValueTypes []string
 }
 
 // Name: CreateEmptySymbolTable
@@ -72,6 +218,8 @@ func CreateEmptySymbolTable() *SymbolTable {
 		SymbolScopes: []map[string]Symbol{
 			make(map[string]Symbol),
 		},
+		ScopeIDs: []int{0},
+		Types:    make(map[string]*Type),
 	}
 }
 
@@ -81,11 +229,15 @@ func CreateEmptySymbolTable() *SymbolTable {
 //
 // Return: *SymbolTableArtefact
 //
-// Create an empty symbol table artefact with an empty scope data and return the memory address to it
+// Create an empty symbol table artefact with only the global scope and return the memory address to it
 func CreateEmptySymbolTableArtefact() *SymbolTableArtefact {
 
 	return &SymbolTableArtefact{
 		SymbolScopes: []Symbol{},
+		Scopes: []Scope{
+			{ID: 0, Parent: -1, Kind: "global", Node: 0},
+		},
+		References: []Reference{},
 	}
 
 }
@@ -155,9 +307,171 @@ func ExitScope(symbol_table *SymbolTable) error {
 	}
 
 	symbol_table.SymbolScopes = symbol_table.SymbolScopes[:len(symbol_table.SymbolScopes)-1]
+	if len(symbol_table.ScopeIDs) > 0 {
+		symbol_table.ScopeIDs = symbol_table.ScopeIDs[:len(symbol_table.ScopeIDs)-1]
+	}
 	return nil
 }
 
+// Name: OpenScope
+//
+// Parameters: *SymbolTable, *SymbolTableArtefact, string, *TreeNode
+//
+// Return: none
+//
+// Enters a new scope and adds it to the scope tree of the artefact as a child of the current scope
+func OpenScope(symbol_table *SymbolTable, symbol_table_artefact *SymbolTableArtefact, kind string, node *TreeNode) {
+
+	scope := Scope{
+		ID:     len(symbol_table_artefact.Scopes),
+		Parent: CurrentScopeID(symbol_table),
+		Kind:   kind,
+		Node:   symbol_table.Nodes[node],
+	}
+
+	EnterNewScope(symbol_table)
+	symbol_table.ScopeIDs = append(symbol_table.ScopeIDs, scope.ID)
+	symbol_table_artefact.Scopes = append(symbol_table_artefact.Scopes, scope)
+}
+
+// Name: CurrentScopeID
+//
+// Parameters: *SymbolTable
+//
+// Return: int
+//
+// Returns the id of the innermost open scope, the global scope having id 0
+func CurrentScopeID(symbol_table *SymbolTable) int {
+
+	if len(symbol_table.ScopeIDs) == 0 {
+		return 0
+	}
+
+	return symbol_table.ScopeIDs[len(symbol_table.ScopeIDs)-1]
+}
+
+// Name: RecordReference
+//
+// Parameters: *SymbolTable, *SymbolTableArtefact, Symbol, *TreeNode, bool
+//
+// Return: none
+//
+// Records the use of a declared symbol at the given node in the current scope, either as the target of an assignment or as a read
+func RecordReference(symbol_table *SymbolTable, symbol_table_artefact *SymbolTableArtefact, symbol Symbol, node *TreeNode, assign bool) {
+
+	symbol_table_artefact.References = append(symbol_table_artefact.References, Reference{
+		Name:        symbol.Name,
+		Node:        symbol_table.Nodes[node],
+		Scope:       CurrentScopeID(symbol_table),
+		Declaration: symbol.Node,
+		Assign:      assign,
+	})
+
+	DecorateNode(symbol_table, node, symbol.Type, symbol.Node)
+}
+
+// Name: NumberTreeNodes
+//
+// Parameters: *TreeNode
+//
+// Return: map[*TreeNode]int
+//
+// Numbers the nodes of the tree in pre-order, the same order used for the node identifiers of the DOT export
+func NumberTreeNodes(root *TreeNode) map[*TreeNode]int {
+
+	numbers := make(map[*TreeNode]int)
+	stack := []*TreeNode{root}
+
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if node == nil {
+			continue
+		}
+
+		numbers[node] = len(numbers)
+		for i := len(node.Children) - 1; i >= 0; i-- {
+			stack = append(stack, node.Children[i])
+		}
+	}
+
+	return numbers
+}
+
+// Name: FindTreePaths
+//
+// Parameters: *TreeNode
+//
+// Return: map[*TreeNode][]int
+//
+// Returns the path of every node of the tree as the index of the child followed at each level from the root
+func FindTreePaths(root *TreeNode) map[*TreeNode][]int {
+
+	paths := make(map[*TreeNode][]int)
+	if root == nil {
+		return paths
+	}
+
+	paths[root] = []int{}
+	stack := []*TreeNode{root}
+
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		for i, child := range node.Children {
+			if child == nil {
+				continue
+			}
+			path := append(append([]int{}, paths[node]...), i)
+			paths[child] = path
+			stack = append(stack, child)
+		}
+	}
+
+	return paths
+}
+
+// Name: RecordDiagnostic
+//
+// Parameters: *SymbolTable, string, string, *TreeNode, string
+//
+// Return: none
+//
+// Adds a diagnostic for the node to the symbol table
+func RecordDiagnostic(symbol_table *SymbolTable, code string, symbol_name string, node *TreeNode, message string) {
+
+	path := symbol_table.Paths[node]
+	if path == nil {
+		path = []int{}
+	}
+
+	symbol_table.Diagnostics = append(symbol_table.Diagnostics, Diagnostic{
+		Code:    code,
+		Message: message,
+		Symbol:  symbol_name,
+		Path:    path,
+	})
+}
+
+// Name: DiagnosticsError
+//
+// Parameters: []Diagnostic
+//
+// Return: error
+//
+// Joins the messages of the diagnostics into one error, or returns nil when there are none
+func DiagnosticsError(diagnostics []Diagnostic) error {
+
+	errs := []error{}
+	for _, diagnostic := range diagnostics {
+		errs = append(errs, errors.New(diagnostic.Message))
+	}
+
+	return errors.Join(errs...)
+}
+
 // Name: HandleFunctionScope
 //
 // Parameters: *Symbol,*TreeNode,*SymbolTable,*SymbolTableArtefact,string,string,string,string
@@ -203,6 +517,13 @@ func HandleFunctionScope(new_symbol *Symbol, child *TreeNode, symbol_table *Symb
 			}
 		}
 
+		if IsTypeNode(function_child, rules) {
+			err := ApplyDeclaredType(new_symbol, function_child, symbol_table, rules)
+			if err != nil {
+				return err
+			}
+		}
+
 		if function_child.Symbol == rules.ParameterRule {
 
 			parameter_symbol := Symbol{}
@@ -226,6 +547,13 @@ func HandleFunctionScope(new_symbol *Symbol, child *TreeNode, symbol_table *Symb
 
 					}
 
+					if IsTypeNode(current_child, rules) {
+						err := ApplyDeclaredType(&parameter_symbol, current_child, symbol_table, rules)
+						if err != nil {
+							return err
+						}
+					}
+
 					if current_child.Symbol == rules.VariableRule {
 
 						if current_child.Value == "" {
@@ -249,19 +577,31 @@ func HandleFunctionScope(new_symbol *Symbol, child *TreeNode, symbol_table *Symb
 
 			}
 
+			// the parameters are bound when the scope of the function body is opened
 			if parameter_symbol.Name != "" && parameter_symbol.Type != "" {
-				parameter_symbol.Scope = len(symbol_table.SymbolScopes)
-				parameter_symbol.IsParam = true
-				err := BindSymbol(symbol_table, parameter_symbol)
-				if err != nil {
-					return err
+				for _, parameter := range new_symbol.Parameters {
+					if parameter.Name == parameter_symbol.Name {
+						return fmt.Errorf("symbol already declared in scope: %v", parameter_symbol.Name)
+					}
 				}
-				symbol_table_artefact.SymbolScopes = append(symbol_table_artefact.SymbolScopes, parameter_symbol)
+
+				parameter_symbol.Node = symbol_table.Nodes[function_child]
+				parameter_symbol.IsParam = true
 				new_symbol.Parameters = append(new_symbol.Parameters, parameter_symbol)
+
+				DecorateNode(symbol_table, function_child, parameter_symbol.Type, parameter_symbol.Node)
 			}
 		}
 	}
 
+	if new_symbol.Type != "" {
+		parameter_types := []*Type{}
+		for _, parameter := range new_symbol.Parameters {
+			parameter_types = append(parameter_types, SymbolType(parameter))
+		}
+		new_symbol.TypeInfo = FunctionType(parameter_types, SymbolType(*new_symbol))
+	}
+
 	return nil
 }
 
@@ -307,11 +647,6 @@ func HandleVariableScope(new_symbol *Symbol, child *TreeNode, type_rule string,
 		} else {
 			new_symbol.Type = child.Value
 		}
-
-		//account for system must determine type of IDENTIFIER on its own -> type inference
-		/*if new_symbol.Type == "" {
-
-		}*/
 	}
 
 	return nil
@@ -405,7 +740,8 @@ func FindTerms(last_symbol *Symbol, term_node TreeNode, operator_symbol string,
 //
 // Return: error
 //
-// Perform scope and type check for assignment statements
+// Perform scope and type check for assignment statements. The assignment is valid when a type of the assigned
+// value can be assigned to the declared type, directly, by a type rule without an operator or by promotion
 func HandleAssignment(assignment_data AssignmentData, symbol_table SymbolTable, type_rules []TypeRule) error {
 
 	if assignment_data.ResultData.Type == "" {
@@ -414,85 +750,562 @@ func HandleAssignment(assignment_data AssignmentData, symbol_table SymbolTable,
 	if assignment_data.Assignment.Type == "" {
 		return fmt.Errorf("error: no assignment symbol used: %v", assignment_data.ResultData.Name)
 	}
-	if assignment_data.Operator.Type == "" && len(assignment_data.Terms) > 1 {
-		return fmt.Errorf("error: No operator indicated for multiple terms in assignment: %v", assignment_data.ResultData.Name)
+
+	value_types := assignment_data.ValueTypes
+
+	if value_types == nil {
+		if assignment_data.Operator.Type == "" && len(assignment_data.Terms) > 1 {
+			return fmt.Errorf("error: No operator indicated for multiple terms in assignment: %v", assignment_data.ResultData.Name)
+		}
+		if len(assignment_data.Terms) == 0 {
+			return fmt.Errorf("error: no terms identified for assignment: %v", assignment_data.ResultData.Name)
+		}
+		if assignment_data.Operator.Type != "" && len(assignment_data.Terms) < 2 {
+			return fmt.Errorf("error: not enough terms identified for operator in assignment: %v", assignment_data.ResultData.Name)
+		}
+
+		term_types := []string{}
+		for _, term := range assignment_data.Terms {
+			symbol, err := LookupName(&symbol_table, term.Type)
+			if err == nil {
+				term_types = append(term_types, symbol.Type)
+			} else {
+				term_types = append(term_types, term.Type)
+			}
+		}
+
+		value_types = []string{term_types[0]}
+		for _, term_type := range term_types[1:] {
+			value_types = OperationTypes(assignment_data.Operator.Type, value_types, []string{term_type}, type_rules, symbol_table.Promotions)
+		}
 	}
-	if len(assignment_data.Terms) == 0 {
-		return fmt.Errorf("error: no terms identified for assignment: %v", assignment_data.ResultData.Name)
+
+	for _, value_type := range value_types {
+		if value_type == "" || IsAssignable(assignment_data.ResultData.Type, value_type, type_rules, symbol_table.Promotions) {
+			return nil
+		}
 	}
-	if assignment_data.Operator.Type != "" && len(assignment_data.Terms) < 2 {
-		return fmt.Errorf("error: not enough terms identified for operator in assignment: %v", assignment_data.ResultData.Name)
+
+	return fmt.Errorf("error: invalid types assigned to: %v %v", assignment_data.ResultData.Type, assignment_data.ResultData.Name)
+}
+
+// Name: InferType
+//
+// Parameters: AssignmentData, *SymbolTable, []TypeRule
+//
+// Return: string, error
+//
+// Infers the type of an untyped declaration as the result type of the first type rule its assignment satisfies.
+// Terms that are declared names take the type of their symbol, so inferred symbols can be used in later inferences.
+// When the types of the assigned value have been computed, the first one that is the result of a type rule is
+// inferred, converting a token type such as INTEGER by a type rule without an operator
+func InferType(assignment_data AssignmentData, symbol_table *SymbolTable, type_rules []TypeRule) (string, error) {
+
+	if assignment_data.ValueTypes != nil {
+		for _, value_type := range assignment_data.ValueTypes {
+			for _, rule := range type_rules {
+				if value_type != "" && rule.ResultData == value_type {
+					return value_type, nil
+				}
+			}
+			for _, rule := range type_rules {
+				if value_type != "" && len(rule.Operator) == 0 && rule.LHSData == value_type {
+					return rule.ResultData, nil
+				}
+			}
+		}
+
+		return "", fmt.Errorf("error: could not infer type for: %v", assignment_data.ResultData.Name)
 	}
 
-	valid_assignment := false
+	term_types := []string{}
+
+	for _, term := range assignment_data.Terms {
+		symbol, err := LookupName(symbol_table, term.Type)
+		if err == nil && symbol.Type != "" {
+			term_types = append(term_types, symbol.Type)
+		} else {
+			term_types = append(term_types, term.Type)
+		}
+	}
 
 	for _, rule := range type_rules {
-		if assignment_data.ResultData.Type == rule.ResultData {
+		if assignment_data.Operator.Type == "" {
+			if ContainsSymbol(term_types, rule.LHSData) {
+				return rule.ResultData, nil
+			}
+			continue
+		}
 
-			if assignment_data.Operator.Type != "" {
+		if ContainsSymbol(rule.Operator, assignment_data.Operator.Type) && ContainsSymbol(term_types, rule.LHSData) && ContainsSymbol(term_types, rule.RHSData) {
+			return rule.ResultData, nil
+		}
+	}
 
-				for _, operator := range rule.Operator {
-					if assignment_data.Operator.Type == operator {
+	return "", fmt.Errorf("error: could not infer type for: %v", assignment_data.ResultData.Name)
+}
 
-						rhs_term_found := false
-						lhs_term_found := false
-						for _, term := range assignment_data.Terms {
+// Name: FindCallArguments
+//
+// Parameters: *TreeNode, GrammarRules
+//
+// Return: []*TreeNode
+//
+// Returns the argument nodes of a call in order. Nested calls and the arguments themselves are not searched
+func FindCallArguments(call_node *TreeNode, rules GrammarRules) []*TreeNode {
 
-							if term.Type == rule.LHSData {
-								lhs_term_found = true
-							}
-							if term.Type == rule.RHSData {
-								rhs_term_found = true
-							}
+	argument_rule := rules.ArgumentRule
+	if argument_rule == "" {
+		argument_rule = rules.TermRule
+	}
 
-							symbol, err := LookupName(&symbol_table, term.Type)
-							if err == nil {
-								if symbol.Type == rule.LHSData {
-									lhs_term_found = true
-								}
-								if symbol.Type == rule.RHSData {
-									rhs_term_found = true
-								}
-							}
+	arguments := []*TreeNode{}
 
-						}
+	for _, child := range call_node.Children {
+		if child.Symbol == argument_rule {
+			arguments = append(arguments, child)
+		} else if child.Symbol != rules.CallRule {
+			arguments = append(arguments, FindCallArguments(child, rules)...)
+		}
+	}
 
-						if rhs_term_found && lhs_term_found {
-							valid_assignment = true
-						}
+	return arguments
+}
 
-					}
-				}
-			} else {
-				lhs_term_found := false
+// Name: ArgumentType
+//
+// Parameters: *TreeNode, *SymbolTable, string
+//
+// Return: string
+//
+// Returns the type of an argument, which is the type of its symbol when it is a declared name and
+// otherwise the symbol of the token, such as INTEGER
+func ArgumentType(argument_node *TreeNode, symbol_table *SymbolTable, variable_rule string) string {
 
-				for _, term := range assignment_data.Terms {
+	for argument_node.Value == "" && len(argument_node.Children) > 0 {
+		argument_node = argument_node.Children[0]
+	}
 
-					if term.Type == rule.LHSData {
-						lhs_term_found = true
-					}
+	if argument_node.Symbol == variable_rule {
+		symbol, err := LookupName(symbol_table, argument_node.Value)
+		if err == nil {
+			return symbol.Type
+		}
+		return argument_node.Value
+	}
 
-					symbol, err := LookupName(&symbol_table, term.Type)
-					if err == nil {
-						if symbol.Type == rule.LHSData {
-							lhs_term_found = true
-						}
-					}
-				}
+	return argument_node.Symbol
+}
 
-				if lhs_term_found {
-					valid_assignment = true
-				}
+// Name: IsAssignable
+//
+// Parameters: string, string, []TypeRule, []Promotion
+//
+// Return: bool
+//
+// Determines if a value of the given type can be assigned to the target type, either directly, by promotion or by a
+// type rule without an operator whose types can be reached by promotion
+func IsAssignable(target_type string, value_type string, type_rules []TypeRule, promotions []Promotion) bool {
+
+	if target_type == value_type || IsPromotable(value_type, target_type, promotions) {
+		return true
+	}
+
+	for _, rule := range type_rules {
+		if len(rule.Operator) != 0 {
+			continue
+		}
+
+		lhs_matches := rule.LHSData == value_type || IsPromotable(value_type, rule.LHSData, promotions)
+		result_matches := rule.ResultData == target_type || IsPromotable(rule.ResultData, target_type, promotions)
+
+		if lhs_matches && result_matches {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Name: CheckCall
+//
+// Parameters: *TreeNode, *SymbolTable, GrammarRules, []TypeRule
+//
+// Return: none
+//
+// Checks that the called function is declared and that the number and types of the arguments match
+// its parameters. Records a diagnostic for each mismatch
+func CheckCall(call_node *TreeNode, symbol_table *SymbolTable, rules GrammarRules, type_rules []TypeRule) {
+
+	function_name := ""
+
+	for _, child := range call_node.Children {
+		if child.Symbol == rules.VariableRule {
+			name_node := child
+			for name_node.Value == "" && len(name_node.Children) > 0 {
+				name_node = name_node.Children[0]
 			}
+			function_name = name_node.Value
+			break
 		}
 	}
 
-	if !valid_assignment {
+	if function_name == "" {
+		RecordDiagnostic(symbol_table, code_missing_name, "", call_node, "error: function call has no name")
+		return
+	}
 
-		return fmt.Errorf("error: invalid types assigned to: %v %v", assignment_data.ResultData.Type, assignment_data.ResultData.Name)
+	function_symbol, err := LookupName(symbol_table, function_name)
+	if err != nil {
+		RecordDiagnostic(symbol_table, code_missing_function, function_name, call_node, fmt.Sprintf("error: function not declared: %v", function_name))
+		return
 	}
 
-	return nil
+	arguments := FindCallArguments(call_node, rules)
+
+	// a value of a function type has no parameter names, so its parameters are named by position
+	parameters := function_symbol.Parameters
+	if !function_symbol.IsFunction && function_symbol.TypeInfo != nil && function_symbol.TypeInfo.Kind == type_function {
+		parameters = []Symbol{}
+		for i, parameter_type := range function_symbol.TypeInfo.Parameters {
+			parameters = append(parameters, Symbol{Name: fmt.Sprintf("%v", i+1), Type: TypeKey(parameter_type, symbol_table.StructuralTypes)})
+		}
+	}
+
+	if len(arguments) != len(parameters) {
+		RecordDiagnostic(symbol_table, code_argument_count, function_name, call_node, fmt.Sprintf("error: function %v expects %v arguments but %v were given", function_name, len(parameters), len(arguments)))
+		return
+	}
+
+	for i, argument := range arguments {
+		parameter := parameters[i]
+		argument_type := ArgumentType(argument, symbol_table, rules.VariableRule)
+
+		if !IsAssignable(parameter.Type, argument_type, type_rules, symbol_table.Promotions) {
+			RecordDiagnostic(symbol_table, code_argument_type, function_name, argument, fmt.Sprintf("error: argument %v of %v has type %v but parameter %v expects %v", i+1, function_name, argument_type, parameter.Name, parameter.Type))
+		}
+	}
+}
+
+// Name: OpenRuleScope
+//
+// Parameters: *SymbolTable, *SymbolTableArtefact, *ScopeRule, *TreeNode
+//
+// Return: none
+//
+// Opens the scope of a scope rule at the node and records the rule on the stack of open scopes. The first scope
+// opened after a function declaration is the body of the function, and its kind is function unless the rule names one
+func OpenRuleScope(symbol_table *SymbolTable, symbol_table_artefact *SymbolTableArtefact, rule *ScopeRule, node *TreeNode) {
+
+	function_body := symbol_table.FunctionScope
+	symbol_table.FunctionScope = false
+
+	kind := rule.Kind
+	if kind == "" {
+		kind = "block"
+		if function_body {
+			kind = "function"
+		}
+	}
+
+	OpenScope(symbol_table, symbol_table_artefact, kind, node)
+	symbol_table.OpenRules = append(symbol_table.OpenRules, rule)
+
+	if function_body && symbol_table.DeclaredFunction != nil {
+		BindParameters(symbol_table, symbol_table_artefact, symbol_table.DeclaredFunction.Symbol)
+		symbol_table.DeclaredFunction.ScopeID = CurrentScopeID(symbol_table)
+		symbol_table.Functions = append(symbol_table.Functions, *symbol_table.DeclaredFunction)
+		symbol_table.DeclaredFunction = nil
+	}
+}
+
+// Name: BindParameters
+//
+// Parameters: *SymbolTable, *SymbolTableArtefact, Symbol
+//
+// Return: none
+//
+// Binds the parameters of the function in the current scope, which is the scope of its body, so that they are
+// not visible after the body is closed. The parameters are listed in the artefact before the function
+func BindParameters(symbol_table *SymbolTable, symbol_table_artefact *SymbolTableArtefact, function Symbol) {
+
+	parameter_nodes := make(map[int]bool)
+	parameters := []Symbol{}
+
+	for i := range function.Parameters {
+		parameter := &function.Parameters[i]
+		parameter.Scope = len(symbol_table.SymbolScopes) - 1
+		parameter.ScopeID = CurrentScopeID(symbol_table)
+
+		err := BindSymbol(symbol_table, *parameter)
+		if err != nil {
+			continue
+		}
+		parameters = append(parameters, *parameter)
+		parameter_nodes[parameter.Node] = true
+	}
+
+	position := len(symbol_table_artefact.SymbolScopes)
+	for i, symbol := range symbol_table_artefact.SymbolScopes {
+		if symbol.IsFunction && symbol.Node == function.Node {
+			position = i
+			break
+		}
+	}
+	symbol_table_artefact.SymbolScopes = append(symbol_table_artefact.SymbolScopes[:position], append(parameters, symbol_table_artefact.SymbolScopes[position:]...)...)
+
+	for tree_node, decorated_node := range symbol_table.Decorated {
+		if parameter_nodes[symbol_table.Nodes[tree_node]] {
+			decorated_node.ScopeID = CurrentScopeID(symbol_table)
+		}
+	}
+}
+
+// Name: LeaveRuleScope
+//
+// Parameters: *SymbolTable, GrammarRules
+//
+// Return: none
+//
+// Exits the innermost scope opened by a scope rule and removes its rule from the stack of open scopes
+func LeaveRuleScope(symbol_table *SymbolTable, rules GrammarRules) {
+
+	if len(symbol_table.OpenRules) == 0 {
+		return
+	}
+
+	CloseFunction(symbol_table, rules)
+	CloseBranch(symbol_table)
+	ExitScope(symbol_table)
+	symbol_table.OpenRules = symbol_table.OpenRules[:len(symbol_table.OpenRules)-1]
+}
+
+// Name: CloseTokenScope
+//
+// Parameters: *SymbolTable, GrammarRules, *TreeNode
+//
+// Return: none
+//
+// Closes the innermost scope when it was opened by a rule ending with the value of the node. Records a diagnostic
+// when the end value belongs to an outer scope, so the scopes are interleaved, or to no open scope at all
+func CloseTokenScope(symbol_table *SymbolTable, rules GrammarRules, node *TreeNode) {
+
+	open_rules := symbol_table.OpenRules
+
+	if len(open_rules) > 0 {
+		innermost := open_rules[len(open_rules)-1]
+		if innermost.Node == "" && innermost.End == node.Value {
+			LeaveRuleScope(symbol_table, rules)
+			return
+		}
+
+		for _, rule := range open_rules {
+			if rule.Node == "" && rule.End == node.Value {
+				RecordDiagnostic(symbol_table, code_mismatched_scope, "", node, fmt.Sprintf("end scope symbol %v does not close the innermost scope, opened by %v", node.Value, ScopeRuleOpener(innermost)))
+				return
+			}
+		}
+	}
+
+	RecordDiagnostic(symbol_table, code_unopened_scope, "", node, "end scope symbol found without starting scope, please recheck source code")
+}
+
+// Name: CloseNodeScope
+//
+// Parameters: *SymbolTable, GrammarRules, *ScopeRule, *TreeNode
+//
+// Return: none
+//
+// Closes the scope the node opened for its subtree. Scopes opened by start values in the subtree that were not
+// ended are closed with it and reported
+func CloseNodeScope(symbol_table *SymbolTable, rules GrammarRules, rule *ScopeRule, node *TreeNode) {
+
+	for len(symbol_table.OpenRules) > 0 {
+		innermost := symbol_table.OpenRules[len(symbol_table.OpenRules)-1]
+		if innermost != rule {
+			RecordDiagnostic(symbol_table, code_unclosed_scope, "", node, "end scope symbol not found for start scope, please recheck source code")
+		}
+
+		LeaveRuleScope(symbol_table, rules)
+		if innermost == rule {
+			return
+		}
+	}
+}
+
+// Name: ScopeRuleOpener
+//
+// Parameters: *ScopeRule
+//
+// Return: string
+//
+// Returns what opens the scopes of the rule: its node symbol or its start value
+func ScopeRuleOpener(rule *ScopeRule) string {
+
+	if rule.Node != "" {
+		return rule.Node
+	}
+
+	return rule.Start
+}
+
+// Name: CheckReturn
+//
+// Parameters: *TreeNode, *SymbolTable, GrammarRules, []TypeRule
+//
+// Return: none
+//
+// Checks a return statement against the function it is in: the returned value must be assignable to the type
+// of the function, and functions of type void return no value. Records a diagnostic for a return outside any function
+func CheckReturn(return_node *TreeNode, symbol_table *SymbolTable, rules GrammarRules, type_rules []TypeRule) {
+
+	if len(symbol_table.Functions) == 0 {
+		RecordDiagnostic(symbol_table, code_return_outside, "", return_node, "error: return statement outside a function")
+		return
+	}
+
+	// a return in a branch without its own scope might not be run, so it does not mark the scope
+	conditionals := symbol_table.Conditionals
+	if len(conditionals) == 0 || conditionals[len(conditionals)-1].ScopeID != CurrentScopeID(symbol_table) {
+		MarkReturning(symbol_table, CurrentScopeID(symbol_table))
+	}
+
+	function := symbol_table.Functions[len(symbol_table.Functions)-1]
+
+	function_name := function.Symbol.Name
+	function_type := function.Symbol.Type
+	if function_type == "" {
+		// the function declaration has already been reported
+		return
+	}
+
+	value_types, err := ExpressionTypes(return_node.Children, symbol_table, rules, type_rules)
+	if err != nil {
+		RecordDiagnostic(symbol_table, code_invalid_expression, function_name, return_node, err.Error())
+		return
+	}
+
+	if _, err := FoldConstant(return_node.Children, symbol_table, rules, type_rules); err != nil {
+		RecordDiagnostic(symbol_table, code_invalid_constant, function_name, return_node, err.Error())
+		return
+	}
+
+	if len(value_types) == 0 {
+		if function_type != void_type {
+			RecordDiagnostic(symbol_table, code_return_type, function_name, return_node, fmt.Sprintf("error: function %v must return a value of type %v", function_name, function_type))
+		}
+		return
+	}
+
+	if function_type == void_type {
+		RecordDiagnostic(symbol_table, code_return_type, function_name, return_node, fmt.Sprintf("error: function %v of type void cannot return a value", function_name))
+		return
+	}
+
+	for _, value_type := range value_types {
+		if value_type == "" || IsAssignable(function_type, value_type, type_rules, symbol_table.Promotions) {
+			return
+		}
+	}
+
+	RecordDiagnostic(symbol_table, code_return_type, function_name, return_node, fmt.Sprintf("error: function %v returns %v but is declared as %v", function_name, strings.Join(value_types, "|"), function_type))
+}
+
+// Name: CloseFunction
+//
+// Parameters: *SymbolTable, GrammarRules
+//
+// Return: none
+//
+// Called before the current scope is exited. When the scope is the body of a function, the function is no longer
+// the enclosing one, and a diagnostic is recorded if a return is configured and the body does not return on every
+// path. Functions of type void need no return
+func CloseFunction(symbol_table *SymbolTable, rules GrammarRules) {
+
+	if len(symbol_table.Functions) == 0 {
+		return
+	}
+
+	function := symbol_table.Functions[len(symbol_table.Functions)-1]
+	if function.ScopeID != CurrentScopeID(symbol_table) {
+		return
+	}
+
+	symbol_table.Functions = symbol_table.Functions[:len(symbol_table.Functions)-1]
+
+	if rules.ReturnRule == "" || symbol_table.Returning[function.ScopeID] || function.Symbol.Type == "" || function.Symbol.Type == void_type {
+		return
+	}
+
+	RecordDiagnostic(symbol_table, code_missing_return, function.Symbol.Name, function.Node, fmt.Sprintf("error: function %v does not return a value on every path", function.Symbol.Name))
+}
+
+// Name: MarkReturning
+//
+// Parameters: *SymbolTable, int
+//
+// Return: none
+//
+// Marks the scope as returning on every path
+func MarkReturning(symbol_table *SymbolTable, scope_id int) {
+
+	if symbol_table.Returning == nil {
+		symbol_table.Returning = make(map[int]bool)
+	}
+
+	symbol_table.Returning[scope_id] = true
+}
+
+// Name: CloseBranch
+//
+// Parameters: *SymbolTable
+//
+// Return: none
+//
+// Called before the current scope is exited. When the scope was opened directly in the innermost conditional, it is
+// a branch of the conditional, and whether it returns on every path is recorded
+func CloseBranch(symbol_table *SymbolTable) {
+
+	if len(symbol_table.Conditionals) == 0 || len(symbol_table.ScopeIDs) == 0 {
+		return
+	}
+
+	parent_id := 0
+	if len(symbol_table.ScopeIDs) > 1 {
+		parent_id = symbol_table.ScopeIDs[len(symbol_table.ScopeIDs)-2]
+	}
+
+	conditional := &symbol_table.Conditionals[len(symbol_table.Conditionals)-1]
+	if conditional.ScopeID == parent_id {
+		conditional.Branches = append(conditional.Branches, symbol_table.Returning[CurrentScopeID(symbol_table)])
+	}
+}
+
+// Name: CloseConditional
+//
+// Parameters: *SymbolTable
+//
+// Return: none
+//
+// Called after the branches of the innermost conditional are analysed. The conditional returns on every path when it
+// has an else and all its branches return. A conditional nested directly in another one is a branch of it, and
+// otherwise a returning conditional marks the scope it is in as returning
+func CloseConditional(symbol_table *SymbolTable) {
+
+	conditional := symbol_table.Conditionals[len(symbol_table.Conditionals)-1]
+	symbol_table.Conditionals = symbol_table.Conditionals[:len(symbol_table.Conditionals)-1]
+
+	returns := conditional.Else && len(conditional.Branches) >= 2
+	for _, branch := range conditional.Branches {
+		returns = returns && branch
+	}
+
+	outer := symbol_table.Conditionals
+	if len(outer) > 0 && outer[len(outer)-1].ScopeID == CurrentScopeID(symbol_table) {
+		outer[len(outer)-1].Branches = append(outer[len(outer)-1].Branches, returns)
+	} else if returns {
+		MarkReturning(symbol_table, CurrentScopeID(symbol_table))
+	}
 }
 
 // Name: TraverseSyntaxTree
@@ -502,39 +1315,118 @@ func HandleAssignment(assignment_data AssignmentData, symbol_table SymbolTable,
 // Return: error
 //
 // Function used to recursively traverse the syntax tree and build the symbol table.
-// Performs the scope check and type check.
+// Performs the scope check and type check. The traversal continues after an error, and the
+// messages of all the diagnostics found are returned as one error
 func TraverseSyntaxTree(scope_rules []*ScopeRule, current_tree_node *TreeNode, symbol_table *SymbolTable, symbol_table_artefact *SymbolTableArtefact, rules GrammarRules, type_rules []TypeRule) error {
 
+	reported := len(symbol_table.Diagnostics)
+
+	AnalyseNode(scope_rules, current_tree_node, symbol_table, symbol_table_artefact, rules, type_rules)
+
+	return DiagnosticsError(symbol_table.Diagnostics[reported:])
+}
+
+// Name: AnalyseNode
+//
+// Parameters: []ScopeRule,*TreeNode,*SymbolTable,*SymbolTableArtefact,GrammarRules,[]TypeRule
+//
+// Return: none
+//
+// Recursively scope and type checks the node and its children, recording a diagnostic in the symbol table for every error
+func AnalyseNode(scope_rules []*ScopeRule, current_tree_node *TreeNode, symbol_table *SymbolTable, symbol_table_artefact *SymbolTableArtefact, rules GrammarRules, type_rules []TypeRule) {
+
 	if current_tree_node == nil {
-		return nil
+		return
+	}
+
+	if symbol_table.Nodes == nil {
+		symbol_table.Nodes = NumberTreeNodes(current_tree_node)
+		symbol_table.Paths = FindTreePaths(current_tree_node)
+		_, symbol_table.Decorated = CreateDecoratedTree(current_tree_node)
+		symbol_table.StructuralTypes = rules.StructuralTypes
+		symbol_table.TypeRules = type_rules
 	}
 
 	for _, rule := range scope_rules {
-		if current_tree_node.Value == rule.Start {
-			EnterNewScope(symbol_table)
-			rule.Entered = true
+		if rule.Node == "" && rule.Start != "" && current_tree_node.Value == rule.Start {
+			OpenRuleScope(symbol_table, symbol_table_artefact, rule, current_tree_node)
+			break
+		}
+	}
+
+	if decorated_node, found := symbol_table.Decorated[current_tree_node]; found {
+		decorated_node.ScopeID = CurrentScopeID(symbol_table)
+	}
+
+	// a record declares a type rather than a symbol, so its fields are not analysed as declarations
+	if constructor := FindTypeConstructor(current_tree_node, rules); constructor != nil && constructor.Kind == type_record {
+		DeclareRecord(current_tree_node, symbol_table, symbol_table_artefact, rules)
+		return
+	}
+
+	if IsAccessNode(current_tree_node, rules) {
+		_, err := AccessType(current_tree_node, symbol_table, rules, type_rules)
+		if err != nil {
+			RecordDiagnostic(symbol_table, code_invalid_access, "", current_tree_node, err.Error())
+		}
+
+		// the name of the accessed field is not a declared symbol, so only the record is analysed
+		if current_tree_node.Symbol == rules.FieldRule {
+			AnalyseNode(scope_rules, current_tree_node.Children[0], symbol_table, symbol_table_artefact, rules, type_rules)
+			return
 		}
 	}
 
+	is_call := rules.CallRule != "" && current_tree_node.Symbol == rules.CallRule
+	if is_call {
+		CheckCall(current_tree_node, symbol_table, rules, type_rules)
+	}
+
+	if rules.ReturnRule != "" && current_tree_node.Symbol == rules.ReturnRule {
+		CheckReturn(current_tree_node, symbol_table, rules, type_rules)
+	}
+
 	new_symbol := Symbol{}
+	var name_node *TreeNode
+	var access_target *TreeNode
+	is_target := false
+	type_failed := false
 
 	assignment_data := AssignmentData{}
+	var expression []*TreeNode
 
 	for _, child := range current_tree_node.Children {
+		if new_symbol.Assign {
+			expression = append(expression, child)
+		}
+
 		switch child.Symbol {
 
 		case rules.FunctionRule:
 			err := HandleFunctionScope(&new_symbol, child, symbol_table, symbol_table_artefact, rules)
 
 			if err != nil {
-				return err
+				code := code_missing_name
+				if strings.HasPrefix(err.Error(), "symbol already declared") {
+					code = code_redeclared_symbol
+				}
+				RecordDiagnostic(symbol_table, code, new_symbol.Name, child, err.Error())
 			}
+			new_symbol.IsFunction = true
+			symbol_table.FunctionScope = true
 
 		case rules.TypeRule, rules.VariableRule:
 			err := HandleVariableScope(&new_symbol, child, rules.TypeRule, rules.VariableRule)
 
 			if err != nil {
-				return err
+				RecordDiagnostic(symbol_table, code_missing_name, "", child, err.Error())
+			}
+			if child.Symbol == rules.TypeRule {
+				ApplyDeclaredType(&new_symbol, child, symbol_table, rules)
+			}
+			if child.Symbol == rules.VariableRule {
+				name_node = child
+				is_target = !new_symbol.Assign
 			}
 
 		case rules.AssignmentRule:
@@ -551,6 +1443,19 @@ func TraverseSyntaxTree(scope_rules []*ScopeRule, current_tree_node *TreeNode, s
 
 		default:
 
+			if IsTypeNode(child, rules) {
+				err := ApplyDeclaredType(&new_symbol, child, symbol_table, rules)
+				if err != nil {
+					RecordDiagnostic(symbol_table, code_invalid_type, "", child, err.Error())
+					type_failed = true
+				}
+				continue
+			}
+			if IsAccessNode(child, rules) && !new_symbol.Assign {
+				access_target = child
+				continue
+			}
+
 			term_symbols, operator_symbol := FindTerms(&new_symbol, *child, rules.OperatorRule, rules.TermRule, rules.VariableRule)
 			if len(term_symbols) > 0 {
 				assignment_data.Terms = term_symbols
@@ -560,87 +1465,282 @@ func TraverseSyntaxTree(scope_rules []*ScopeRule, current_tree_node *TreeNode, s
 
 	}
 
+	expression_failed := false
+
+	if new_symbol.Assign && len(expression) > 0 {
+		value_types, err := ExpressionTypes(expression, symbol_table, rules, type_rules)
+		if err != nil {
+			RecordDiagnostic(symbol_table, code_invalid_expression, new_symbol.Name, current_tree_node, err.Error())
+			expression_failed = true
+		} else {
+			assignment_data.ValueTypes = value_types
+
+			constant, err := FoldConstant(expression, symbol_table, rules, type_rules)
+			if err != nil {
+				RecordDiagnostic(symbol_table, code_invalid_constant, new_symbol.Name, current_tree_node, err.Error())
+			} else if constant != nil {
+				new_symbol.Constant = FormatConstant(constant)
+			}
+		}
+	}
+
+	inference_failed := false
+
+	// an assignment to a name that is not declared yet declares it with the inferred type
+	if new_symbol.Type == "" && new_symbol.Name != "" && new_symbol.Assign && (len(assignment_data.Terms) > 0 || len(assignment_data.ValueTypes) > 0 || expression_failed) {
+		_, err := LookupName(symbol_table, new_symbol.Name)
+		if err != nil && expression_failed {
+			// the invalid expression has already been reported
+			inference_failed = true
+		} else if err != nil {
+			assignment_data.ResultData = new_symbol
+
+			inferred_type, err := InferType(assignment_data, symbol_table, type_rules)
+			if err != nil {
+				RecordDiagnostic(symbol_table, code_uninferable_type, new_symbol.Name, current_tree_node, err.Error())
+				inference_failed = true
+			} else {
+				new_symbol.Type = inferred_type
+				new_symbol.Inferred = true
+			}
+		}
+	}
+
 	if new_symbol.Name != "" && new_symbol.Type != "" {
 		new_symbol.Scope = len(symbol_table.SymbolScopes) - 1
+		new_symbol.ScopeID = CurrentScopeID(symbol_table)
+		new_symbol.Node = symbol_table.Nodes[current_tree_node]
 
 		err := BindSymbol(symbol_table, new_symbol)
 		if err != nil {
-			return err
+			RecordDiagnostic(symbol_table, code_redeclared_symbol, new_symbol.Name, current_tree_node, err.Error())
+		} else {
+			symbol_table_artefact.SymbolScopes = append(symbol_table_artefact.SymbolScopes, new_symbol)
+
+			DecorateNode(symbol_table, current_tree_node, new_symbol.Type, new_symbol.Node)
+			if name_node != nil {
+				DecorateNode(symbol_table, name_node, new_symbol.Type, new_symbol.Node)
+			}
 		}
-		symbol_table_artefact.SymbolScopes = append(symbol_table_artefact.SymbolScopes, new_symbol)
 
 		assignment_data.ResultData = new_symbol
 
 	}
 
-	if new_symbol.Type == "" && new_symbol.Name != "" {
-		_, err := LookupName(symbol_table, new_symbol.Name)
-		if err != nil {
-			return fmt.Errorf("variable not declared within it's scope: %v", new_symbol.Name)
+	if new_symbol.IsFunction {
+		symbol_table.DeclaredFunction = &FunctionContext{Symbol: new_symbol, Node: current_tree_node}
+	}
+
+	if inference_failed || type_failed {
+		// the missing type has already been reported
+	} else if new_symbol.Type == "" && new_symbol.Name != "" {
+		// a call to a function that is not declared has already been reported by the call check
+		declared_symbol, err := LookupName(symbol_table, new_symbol.Name)
+		if err != nil && !is_call {
+			RecordDiagnostic(symbol_table, code_undeclared_symbol, new_symbol.Name, name_node, fmt.Sprintf("variable not declared within it's scope: %v", new_symbol.Name))
+		} else if err == nil {
+			RecordReference(symbol_table, symbol_table_artefact, declared_symbol, name_node, new_symbol.Assign && is_target)
 		}
-	} else if new_symbol.Assign {
+	} else if access_target != nil {
+		// errors in the target itself are reported when it is analysed
+		target_type, err := AccessType(access_target, symbol_table, rules, type_rules)
+		if err == nil && target_type != nil && new_symbol.Assign && !expression_failed {
+			target_key := TypeKey(target_type, symbol_table.StructuralTypes)
+
+			valid := len(assignment_data.ValueTypes) == 0
+			for _, value_type := range assignment_data.ValueTypes {
+				if value_type == "" || IsAssignable(target_key, value_type, type_rules, symbol_table.Promotions) {
+					valid = true
+				}
+			}
+			if !valid {
+				RecordDiagnostic(symbol_table, code_invalid_assignment, "", current_tree_node, fmt.Sprintf("error: invalid types assigned to: %v %v", target_key, ExpressionText([]*TreeNode{access_target})))
+			}
+		}
+	} else if new_symbol.Assign && !expression_failed {
 		err := HandleAssignment(assignment_data, *symbol_table, type_rules)
 		if err != nil {
-			return fmt.Errorf("%v", err)
+			RecordDiagnostic(symbol_table, code_invalid_assignment, new_symbol.Name, current_tree_node, err.Error())
+		}
+	}
+
+	// a scope opened by the node holds its subtree, but not the symbol the node itself declares
+	var node_rule *ScopeRule
+	for _, rule := range scope_rules {
+		if rule.Node != "" && current_tree_node.Symbol == rule.Node {
+			node_rule = rule
+			OpenRuleScope(symbol_table, symbol_table_artefact, rule, current_tree_node)
+			break
 		}
 	}
 
+	is_conditional := rules.IfRule != "" && current_tree_node.Symbol == rules.IfRule
+	if is_conditional {
+		conditional := ConditionalContext{ScopeID: CurrentScopeID(symbol_table)}
+		for _, child := range current_tree_node.Children {
+			if rules.ElseRule != "" && child.Symbol == rules.ElseRule {
+				conditional.Else = true
+			}
+		}
+		symbol_table.Conditionals = append(symbol_table.Conditionals, conditional)
+	}
+
 	for _, child := range current_tree_node.Children {
 		if child.Symbol != rules.FunctionRule {
-			err := TraverseSyntaxTree(scope_rules, child, symbol_table, symbol_table_artefact, rules, type_rules)
-			if err != nil {
-				return err
-			}
+			AnalyseNode(scope_rules, child, symbol_table, symbol_table_artefact, rules, type_rules)
 		}
 
 	}
 
-	for _, rule := range scope_rules {
-		if current_tree_node.Value == rule.End {
-			if rule.Entered {
+	if node_rule != nil {
+		CloseNodeScope(symbol_table, rules, node_rule, current_tree_node)
+	}
 
-				ExitScope(symbol_table)
-				rule.Entered = false
+	if is_conditional {
+		CloseConditional(symbol_table)
+	}
 
-			} else {
-				return fmt.Errorf("end scope symbol found without starting scope, please recheck source code")
-			}
+	for _, rule := range scope_rules {
+		if rule.Node == "" && rule.End != "" && current_tree_node.Value == rule.End {
+			CloseTokenScope(symbol_table, rules, current_tree_node)
+			break
 		}
 	}
-
-	return nil
 }
 
 // Name: Analyse
 //
 // Parameters: []ScopeRule,SyntaxTree,GrammarRules,[]TypeRule
 //
-// Return: SymbolTable, error
+// Return: SymbolTableArtefact, DecoratedTree, error
+//
+// Receive a syntax tree and scope rules to scope check the parse tree, and create and return a symbol table and semantically verified tree
+// decorated with the type, scope and declaration of every node, or error
+func Analyse(scope_rules []*ScopeRule, syntax_tree SyntaxTree, rules GrammarRules, type_rules []TypeRule) (SymbolTableArtefact, DecoratedTree, error) {
+
+	symbol_table_artefact, verified_tree, diagnostics, err := AnalyseWithDiagnostics(scope_rules, syntax_tree, rules, type_rules, nil)
+	if err != nil {
+		return symbol_table_artefact, verified_tree, err
+	}
+
+	return symbol_table_artefact, verified_tree, DiagnosticsError(diagnostics)
+}
+
+// Name: AnalyseWithDiagnostics
+//
+// Parameters: []ScopeRule,SyntaxTree,GrammarRules,[]TypeRule,[]Promotion
+//
+// Return: SymbolTableArtefact, DecoratedTree, []Diagnostic, error
 //
-// Receive a syntax tree and scope rules to scope check the parse tree, and create and return a symbol table and semantically verified syntax tree or error
-func Analyse(scope_rules []*ScopeRule, syntax_tree SyntaxTree, rules GrammarRules, type_rules []TypeRule) (SymbolTableArtefact, SyntaxTree, error) {
+// Scope and type check the whole syntax tree, collecting a diagnostic for every error instead of stopping at the first.
+// The promotions are the implicit conversions allowed between types when checking expressions and assignments.
+// The symbol table built so far is returned with the diagnostics, and the decorated tree only when there are none
+func AnalyseWithDiagnostics(scope_rules []*ScopeRule, syntax_tree SyntaxTree, rules GrammarRules, type_rules []TypeRule, promotions []Promotion) (SymbolTableArtefact, DecoratedTree, []Diagnostic, error) {
 
 	if syntax_tree.Root == nil {
-		return SymbolTableArtefact{}, SyntaxTree{}, fmt.Errorf("syntax tree is empty")
+		return SymbolTableArtefact{}, DecoratedTree{}, nil, fmt.Errorf("syntax tree is empty")
 	}
 
 	symbol_table := CreateEmptySymbolTable()
+	symbol_table.Promotions = promotions
 
 	symbol_table_artefact := CreateEmptySymbolTableArtefact()
 
-	err := TraverseSyntaxTree(scope_rules, syntax_tree.Root, symbol_table, symbol_table_artefact, rules, type_rules)
-	if err != nil {
-		return *symbol_table_artefact, SyntaxTree{}, err
+	AnalyseNode(scope_rules, syntax_tree.Root, symbol_table, symbol_table_artefact, rules, type_rules)
+
+	for range symbol_table.OpenRules {
+		RecordDiagnostic(symbol_table, code_unclosed_scope, "", syntax_tree.Root, "end scope symbol not found for start scope, please recheck source code")
 	}
 
-	for _, rule := range scope_rules {
-		if rule.Entered {
-			return *symbol_table_artefact, SyntaxTree{}, fmt.Errorf("end scope symbol not found for start scope, please recheck source code")
+	if len(symbol_table.Diagnostics) > 0 {
+		return *symbol_table_artefact, DecoratedTree{}, symbol_table.Diagnostics, nil
+	}
+
+	decorated_tree := DecoratedTree{Root: symbol_table.Decorated[syntax_tree.Root]}
+	FillDecoratedScopes(decorated_tree.Root, 0)
+
+	return *symbol_table_artefact, decorated_tree, []Diagnostic{}, nil
+}
+
+// Name: FindWarnings
+//
+// Parameters: SymbolTableArtefact, SyntaxTree, WarningOptions
+//
+// Return: []Diagnostic
+//
+// Finds the enabled warnings in the symbol table artefact: symbols and parameters that are never used,
+// symbols shadowing a symbol of an enclosing scope and variables read before they are assigned
+func FindWarnings(symbol_table_artefact SymbolTableArtefact, syntax_tree SyntaxTree, options WarningOptions) []Diagnostic {
+
+	warnings := &SymbolTable{Paths: make(map[*TreeNode][]int)}
+	nodes := make(map[int]*TreeNode)
+
+	if syntax_tree.Root != nil {
+		warnings.Paths = FindTreePaths(syntax_tree.Root)
+		for node, number := range NumberTreeNodes(syntax_tree.Root) {
+			nodes[number] = node
+		}
+	}
+
+	parents := make(map[int]int)
+	for _, scope := range symbol_table_artefact.Scopes {
+		parents[scope.ID] = scope.Parent
+	}
+
+	for _, symbol := range symbol_table_artefact.SymbolScopes {
+		references := []Reference{}
+		for _, reference := range symbol_table_artefact.References {
+			if reference.Name == symbol.Name && reference.Declaration == symbol.Node {
+				references = append(references, reference)
+			}
+		}
+
+		if len(references) == 0 {
+			if symbol.IsParam && options.UnusedParameters {
+				RecordDiagnostic(warnings, code_unused_parameter, symbol.Name, nodes[symbol.Node], fmt.Sprintf("warning: parameter never used: %v", symbol.Name))
+			} else if !symbol.IsParam && options.Unused {
+				RecordDiagnostic(warnings, code_unused_symbol, symbol.Name, nodes[symbol.Node], fmt.Sprintf("warning: symbol declared but never used: %v", symbol.Name))
+			}
+		}
+
+		if options.Shadowed {
+			for _, outer := range symbol_table_artefact.SymbolScopes {
+				if outer.Name != symbol.Name || outer.Node >= symbol.Node {
+					continue
+				}
+
+				// parameters are in the scope of the function body, so a body declaration of the same name shadows them
+				shadows := outer.IsParam && !symbol.IsParam && outer.ScopeID == symbol.ScopeID
+				for scope := parents[symbol.ScopeID]; !shadows && scope >= 0; scope = parents[scope] {
+					shadows = scope == outer.ScopeID
+					if scope == 0 {
+						break
+					}
+				}
+
+				if shadows {
+					RecordDiagnostic(warnings, code_shadowed_symbol, symbol.Name, nodes[symbol.Node], fmt.Sprintf("warning: %v shadows a symbol declared in an outer scope", symbol.Name))
+					break
+				}
+			}
 		}
 
+		if options.Uninitialised && !symbol.Assign && !symbol.IsParam && !symbol.IsFunction {
+			for _, reference := range references {
+				if reference.Assign {
+					break
+				}
+				RecordDiagnostic(warnings, code_uninitialised_read, symbol.Name, nodes[reference.Node], fmt.Sprintf("warning: %v is read before it is assigned", symbol.Name))
+				break
+			}
+		}
+	}
+
+	if warnings.Diagnostics == nil {
+		return []Diagnostic{}
 	}
 
-	return *symbol_table_artefact, syntax_tree, nil
+	return warnings.Diagnostics
 }
 
 // Name: StringifySymbolTable
@@ -649,16 +1749,52 @@ func Analyse(scope_rules []*ScopeRule, syntax_tree SyntaxTree, rules GrammarRule
 //
 // Return: string
 //
-// Returns a string that converts the symbol table to a string
+// Returns a string that renders the symbols, the scope tree and the references of the symbol table as tables
 func StringifySymbolTable(symbol_table SymbolTableArtefact) string {
 
-	output := "-------------------------------------------------------------------------- \nSYMBOL TABLE\n"
+	var output strings.Builder
+	output.WriteString("-------------------------------------------------------------------------- \nSYMBOL TABLE\n")
+
+	table := tabwriter.NewWriter(&output, 0, 0, 2, ' ', 0)
 
+	fmt.Fprintf(table, "  Name\tType\tScope\tNode\tParameter\tInferred\n")
 	for _, symbol := range symbol_table.SymbolScopes {
-		output += fmt.Sprintf("  Name: %v  Type: %v  Scope: %v\n", symbol.Name, symbol.Type, symbol.Scope)
+		symbol_type := symbol.Type
+		if symbol.TypeInfo != nil {
+			symbol_type = TypeString(symbol.TypeInfo)
+		}
+		fmt.Fprintf(table, "  %v\t%v\t%v\t%v\t%v\t%v\n", symbol.Name, symbol_type, symbol.ScopeID, symbol.Node, symbol.IsParam, symbol.Inferred)
+	}
+	table.Flush()
+
+	if len(symbol_table.Types) > 0 {
+		output.WriteString("TYPES\n")
+		fmt.Fprintf(table, "  Name\tType\n")
+		for _, declared_type := range symbol_table.Types {
+			fmt.Fprintf(table, "  %v\t%v\n", declared_type.Name, TypeString(declared_type))
+		}
+		table.Flush()
+	}
+
+	output.WriteString("SCOPES\n")
+	fmt.Fprintf(table, "  ID\tParent\tKind\tNode\n")
+	for _, scope := range symbol_table.Scopes {
+		parent := "-"
+		if scope.Parent >= 0 {
+			parent = fmt.Sprintf("%v", scope.Parent)
+		}
+		fmt.Fprintf(table, "  %v\t%v\t%v\t%v\n", scope.ID, parent, scope.Kind, scope.Node)
+	}
+	table.Flush()
+
+	output.WriteString("REFERENCES\n")
+	fmt.Fprintf(table, "  Name\tNode\tScope\tDeclaration\n")
+	for _, reference := range symbol_table.References {
+		fmt.Fprintf(table, "  %v\t%v\t%v\t%v\n", reference.Name, reference.Node, reference.Scope, reference.Declaration)
 	}
-	output += "--------------------------------------------------------------------------\n"
+	table.Flush()
 
-	return output
+	output.WriteString("--------------------------------------------------------------------------\n")
 
+	return output.String()
 }
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"fmt"
+)
+
+// Struct for the annotation of a grammar variable when building the abstract syntax tree
+//
+// keep lists the child symbols that remain in the tree, promote names the child that replaces the node
+// and name is the symbol given to the resulting node
+type ASTRule struct {
+	Symbol  string   `json:"symbol"`
+	Keep    []string `json:"keep"`
+	Promote string   `json:"promote"`
+	Name    string   `json:"name"`
+}
+
+// Name: ReadASTRules
+//
+// Parameters: []ASTRule
+//
+// Return: map[string]ASTRule, error
+//
+// Validate the abstract syntax tree rules and index them by the variable they annotate
+func ReadASTRules(rules []ASTRule) (map[string]ASTRule, error) {
+
+	annotations := make(map[string]ASTRule)
+
+	for _, rule := range rules {
+		if rule.Symbol == "" {
+			return nil, fmt.Errorf("abstract syntax tree rule has no symbol")
+		}
+
+		if _, exists := annotations[rule.Symbol]; exists {
+			return nil, fmt.Errorf("more than one abstract syntax tree rule for symbol: %v", rule.Symbol)
+		}
+
+		if rule.Promote != "" && len(rule.Keep) > 0 && !ContainsSymbol(rule.Keep, rule.Promote) {
+			return nil, fmt.Errorf("promoted symbol %v is not kept for symbol: %v", rule.Promote, rule.Symbol)
+		}
+
+		annotations[rule.Symbol] = rule
+	}
+
+	return annotations, nil
+}
+
+// Name: CreateAbstractSyntaxTree
+//
+// Parameters: SyntaxTree, []ASTRule
+//
+// Return: SyntaxTree, error
+//
+// Derive a simplified tree from the parse tree. Nodes annotated by a rule are shaped by that rule,
+// all other nodes with a single child are collapsed into that child and empty variables are removed
+func CreateAbstractSyntaxTree(tree SyntaxTree, rules []ASTRule) (SyntaxTree, error) {
+
+	if tree.Root == nil {
+		return SyntaxTree{}, fmt.Errorf("syntax tree is empty")
+	}
+
+	annotations, err := ReadASTRules(rules)
+	if err != nil {
+		return SyntaxTree{}, err
+	}
+
+	root := BuildAbstractNode(tree.Root, annotations)
+
+	if root == nil {
+		return SyntaxTree{}, fmt.Errorf("abstract syntax tree is empty")
+	}
+
+	return SyntaxTree{Root: root}, nil
+}
+
+// Name: BuildAbstractNode
+//
+// Parameters: *TreeNode, map[string]ASTRule
+//
+// Return: *TreeNode
+//
+// Recursively build the abstract node for a parse tree node. Returns nil when the node is removed
+func BuildAbstractNode(node *TreeNode, annotations map[string]ASTRule) *TreeNode {
+
+	if node == nil {
+		return nil
+	}
+
+	if len(node.Children) == 0 {
+		if node.Value == "" {
+			return nil
+		}
+		return &TreeNode{Symbol: node.Symbol, Value: node.Value}
+	}
+
+	rule, annotated := annotations[node.Symbol]
+
+	children := []*TreeNode{}
+	promoted_index := -1
+
+	for _, child := range node.Children {
+		if annotated && len(rule.Keep) > 0 && !ContainsSymbol(rule.Keep, child.Symbol) {
+			continue
+		}
+
+		abstract_child := BuildAbstractNode(child, annotations)
+		if abstract_child == nil {
+			continue
+		}
+
+		if annotated && promoted_index < 0 && rule.Promote != "" && child.Symbol == rule.Promote {
+			promoted_index = len(children)
+		}
+		children = append(children, abstract_child)
+	}
+
+	if !annotated {
+		if len(children) == 0 {
+			return nil
+		}
+		if len(children) == 1 {
+			return children[0]
+		}
+		return &TreeNode{Symbol: node.Symbol, Value: node.Value, Children: children}
+	}
+
+	result := &TreeNode{Symbol: node.Symbol, Value: node.Value, Children: children}
+
+	if promoted_index >= 0 {
+		promoted := children[promoted_index]
+
+		others := append([]*TreeNode{}, children[:promoted_index]...)
+		others = append(others, children[promoted_index+1:]...)
+
+		result = &TreeNode{
+			Symbol:   promoted.Symbol,
+			Value:    promoted.Value,
+			Children: append(append([]*TreeNode{}, promoted.Children...), others...),
+		}
+	}
+
+	if rule.Name != "" {
+		result.Symbol = rule.Name
+	}
+
+	if len(result.Children) == 0 {
+		result.Children = nil
+		if result.Value == "" && rule.Name == "" {
+			return nil
+		}
+	}
+
+	return result
+}
+
+// Name: ContainsSymbol
+//
+// Parameters: []string, string
+//
+// Return: bool
+//
+// Determines if the symbol is in the list of symbols
+func ContainsSymbol(symbols []string, symbol string) bool {
+
+	for _, current := range symbols {
+		if current == symbol {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,675 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// struct to store a parsed expression of an attribute equation.
+//
+// kind is number, string, bool, reference, unary, binary, conditional or call. References name the attribute
+// of the symbol at a position of the production, 0 being the left-hand side and 1 the first symbol of the output
+type AttributeExpression struct {
+	Kind      string
+	Value     interface{}
+	Operator  string
+	Position  int
+	Attribute string
+	Operands  []*AttributeExpression
+}
+
+// struct to store a token of an attribute expression
+type AttributeToken struct {
+	Kind     string
+	Text     string
+	Position int
+}
+
+// struct to track the parsing of an attribute expression
+type AttributeParser struct {
+	Tokens   []AttributeToken
+	Position int
+}
+
+// Attribute holding the source text of a node, available on every node without an equation
+const lexeme_attribute = "lexeme"
+
+// Functions that can be called in an attribute expression, with the number of arguments they take
+var attribute_functions = map[string]int{
+	"str":    1,
+	"num":    1,
+	"len":    1,
+	"min":    2,
+	"max":    2,
+	"concat": -1,
+}
+
+// Name: TokeniseAttributeExpression
+//
+// Parameters: string
+//
+// Return: []AttributeToken, error
+//
+// Splits the text of an attribute expression into numbers, strings, names, references such as $1.value and operators
+func TokeniseAttributeExpression(text string) ([]AttributeToken, error) {
+
+	characters := []rune(text)
+	tokens := []AttributeToken{}
+	position := 0
+
+	for position < len(characters) {
+		character := characters[position]
+		start := position
+
+		switch {
+		case unicode.IsSpace(character):
+			position++
+			continue
+
+		case unicode.IsDigit(character):
+			for position < len(characters) && (unicode.IsDigit(characters[position]) || characters[position] == '.') {
+				position++
+			}
+			tokens = append(tokens, AttributeToken{Kind: "number", Text: string(characters[start:position]), Position: start})
+
+		case unicode.IsLetter(character) || character == '_':
+			for position < len(characters) && (unicode.IsLetter(characters[position]) || unicode.IsDigit(characters[position]) || characters[position] == '_') {
+				position++
+			}
+			tokens = append(tokens, AttributeToken{Kind: "name", Text: string(characters[start:position]), Position: start})
+
+		case character == '$':
+			position++
+			for position < len(characters) && unicode.IsDigit(characters[position]) {
+				position++
+			}
+			if position == start+1 || position >= len(characters) || characters[position] != '.' {
+				return nil, fmt.Errorf("invalid reference at position %v: references are written as $1.attribute", start)
+			}
+			position++
+			name_start := position
+			for position < len(characters) && (unicode.IsLetter(characters[position]) || unicode.IsDigit(characters[position]) || characters[position] == '_') {
+				position++
+			}
+			if position == name_start {
+				return nil, fmt.Errorf("invalid reference at position %v: no attribute name", start)
+			}
+			tokens = append(tokens, AttributeToken{Kind: "reference", Text: string(characters[start:position]), Position: start})
+
+		case character == '"' || character == '\'':
+			position++
+			for position < len(characters) && characters[position] != character {
+				position++
+			}
+			if position >= len(characters) {
+				return nil, fmt.Errorf("unterminated string at position %v", start)
+			}
+			position++
+			tokens = append(tokens, AttributeToken{Kind: "string", Text: string(characters[start+1 : position-1]), Position: start})
+
+		default:
+			operator := string(character)
+			if position+1 < len(characters) {
+				pair := string(characters[position : position+2])
+				if pair == "==" || pair == "!=" || pair == "<=" || pair == ">=" || pair == "&&" || pair == "||" {
+					operator = pair
+				}
+			}
+			if len(operator) == 1 && !strings.ContainsRune("+-*/%<>!()?:,", character) {
+				return nil, fmt.Errorf("unexpected character '%v' at position %v", operator, start)
+			}
+			position += len(operator)
+			tokens = append(tokens, AttributeToken{Kind: "operator", Text: operator, Position: start})
+		}
+	}
+
+	return tokens, nil
+}
+
+// Name: ParseAttributeExpression
+//
+// Parameters: string
+//
+// Return: *AttributeExpression, error
+//
+// Parses the text of an attribute expression. The language has numbers, strings, true and false, references to
+// attributes such as $1.value, arithmetic, comparison and logical operators, the conditional c ? a : b and the
+// functions str, num, len, min, max and concat. It has no assignments or loops, so evaluation always ends
+func ParseAttributeExpression(text string) (*AttributeExpression, error) {
+
+	tokens, err := TokeniseAttributeExpression(text)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty attribute expression")
+	}
+
+	parser := &AttributeParser{Tokens: tokens}
+
+	expression, err := parser.ParseConditional()
+	if err != nil {
+		return nil, err
+	}
+
+	if parser.Position < len(parser.Tokens) {
+		token := parser.Tokens[parser.Position]
+		return nil, fmt.Errorf("unexpected '%v' at position %v", token.Text, token.Position)
+	}
+
+	return expression, nil
+}
+
+// Name: ParseAttributeReference
+//
+// Parameters: string
+//
+// Return: int, string, error
+//
+// Splits a reference such as $0.value into the position of the symbol in the production and the attribute name
+func ParseAttributeReference(text string) (int, string, error) {
+
+	text = strings.TrimSpace(text)
+
+	dot := strings.Index(text, ".")
+	if !strings.HasPrefix(text, "$") || dot < 2 || dot == len(text)-1 {
+		return 0, "", fmt.Errorf("invalid reference: %v. References are written as $1.attribute", text)
+	}
+
+	position, err := strconv.Atoi(text[1:dot])
+	if err != nil || position < 0 {
+		return 0, "", fmt.Errorf("invalid reference: %v. References are written as $1.attribute", text)
+	}
+
+	attribute := text[dot+1:]
+	for _, character := range attribute {
+		if !unicode.IsLetter(character) && !unicode.IsDigit(character) && character != '_' {
+			return 0, "", fmt.Errorf("invalid attribute name: %v", attribute)
+		}
+	}
+
+	return position, attribute, nil
+}
+
+// Name: Peek (for AttributeParser)
+//
+// Parameters: none
+//
+// Return: string
+//
+// Returns the text of the next operator token, or an empty string when the next token is not an operator
+func (parser *AttributeParser) Peek() string {
+
+	if parser.Position >= len(parser.Tokens) || parser.Tokens[parser.Position].Kind != "operator" {
+		return ""
+	}
+
+	return parser.Tokens[parser.Position].Text
+}
+
+// Name: Expect (for AttributeParser)
+//
+// Parameters: string
+//
+// Return: error
+//
+// Consumes the next token when it is the given operator, and returns an error otherwise
+func (parser *AttributeParser) Expect(operator string) error {
+
+	if parser.Peek() != operator {
+		if parser.Position >= len(parser.Tokens) {
+			return fmt.Errorf("expected '%v' at the end of the expression", operator)
+		}
+		token := parser.Tokens[parser.Position]
+		return fmt.Errorf("expected '%v' at position %v but found '%v'", operator, token.Position, token.Text)
+	}
+
+	parser.Position++
+
+	return nil
+}
+
+// Name: ParseConditional (for AttributeParser)
+//
+// Parameters: none
+//
+// Return: *AttributeExpression, error
+//
+// Parses a conditional expression, the operator with the lowest precedence
+func (parser *AttributeParser) ParseConditional() (*AttributeExpression, error) {
+
+	condition, err := parser.ParseBinary(0)
+	if err != nil || parser.Peek() != "?" {
+		return condition, err
+	}
+	parser.Position++
+
+	when_true, err := parser.ParseConditional()
+	if err != nil {
+		return nil, err
+	}
+	if err := parser.Expect(":"); err != nil {
+		return nil, err
+	}
+	when_false, err := parser.ParseConditional()
+	if err != nil {
+		return nil, err
+	}
+
+	return &AttributeExpression{Kind: "conditional", Operands: []*AttributeExpression{condition, when_true, when_false}}, nil
+}
+
+// Binary operators of attribute expressions from the lowest to the highest precedence
+var attribute_precedence = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!="},
+	{"<", "<=", ">", ">="},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+// Name: ParseBinary (for AttributeParser)
+//
+// Parameters: int
+//
+// Return: *AttributeExpression, error
+//
+// Parses left associative binary operators from the given precedence level upwards
+func (parser *AttributeParser) ParseBinary(level int) (*AttributeExpression, error) {
+
+	if level == len(attribute_precedence) {
+		return parser.ParseUnary()
+	}
+
+	left, err := parser.ParseBinary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+
+	for ContainsSymbol(attribute_precedence[level], parser.Peek()) {
+		operator := parser.Peek()
+		parser.Position++
+
+		right, err := parser.ParseBinary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+
+		left = &AttributeExpression{Kind: "binary", Operator: operator, Operands: []*AttributeExpression{left, right}}
+	}
+
+	return left, nil
+}
+
+// Name: ParseUnary (for AttributeParser)
+//
+// Parameters: none
+//
+// Return: *AttributeExpression, error
+//
+// Parses negation and logical not
+func (parser *AttributeParser) ParseUnary() (*AttributeExpression, error) {
+
+	operator := parser.Peek()
+	if operator != "-" && operator != "!" {
+		return parser.ParsePrimary()
+	}
+	parser.Position++
+
+	operand, err := parser.ParseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	return &AttributeExpression{Kind: "unary", Operator: operator, Operands: []*AttributeExpression{operand}}, nil
+}
+
+// Name: ParsePrimary (for AttributeParser)
+//
+// Parameters: none
+//
+// Return: *AttributeExpression, error
+//
+// Parses a literal, a reference, a function call or an expression in brackets
+func (parser *AttributeParser) ParsePrimary() (*AttributeExpression, error) {
+
+	if parser.Position >= len(parser.Tokens) {
+		return nil, fmt.Errorf("unexpected end of the expression")
+	}
+
+	token := parser.Tokens[parser.Position]
+	parser.Position++
+
+	switch token.Kind {
+	case "number":
+		value, err := strconv.ParseFloat(token.Text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number at position %v: %v", token.Position, token.Text)
+		}
+		return &AttributeExpression{Kind: "number", Value: value}, nil
+
+	case "string":
+		return &AttributeExpression{Kind: "string", Value: token.Text}, nil
+
+	case "reference":
+		position, attribute, err := ParseAttributeReference(token.Text)
+		if err != nil {
+			return nil, err
+		}
+		return &AttributeExpression{Kind: "reference", Position: position, Attribute: attribute}, nil
+
+	case "name":
+		if token.Text == "true" || token.Text == "false" {
+			return &AttributeExpression{Kind: "bool", Value: token.Text == "true"}, nil
+		}
+
+		arity, found := attribute_functions[token.Text]
+		if !found {
+			return nil, fmt.Errorf("unknown function at position %v: %v", token.Position, token.Text)
+		}
+		if err := parser.Expect("("); err != nil {
+			return nil, err
+		}
+
+		arguments := []*AttributeExpression{}
+		for parser.Peek() != ")" {
+			if len(arguments) > 0 {
+				if err := parser.Expect(","); err != nil {
+					return nil, err
+				}
+			}
+			argument, err := parser.ParseConditional()
+			if err != nil {
+				return nil, err
+			}
+			arguments = append(arguments, argument)
+		}
+		parser.Position++
+
+		if arity >= 0 && len(arguments) != arity {
+			return nil, fmt.Errorf("function %v expects %v arguments but %v were given", token.Text, arity, len(arguments))
+		}
+
+		return &AttributeExpression{Kind: "call", Operator: token.Text, Operands: arguments}, nil
+
+	default:
+		if token.Text == "(" {
+			expression, err := parser.ParseConditional()
+			if err != nil {
+				return nil, err
+			}
+			if err := parser.Expect(")"); err != nil {
+				return nil, err
+			}
+			return expression, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unexpected '%v' at position %v", token.Text, token.Position)
+}
+
+// Name: FindAttributeReferences
+//
+// Parameters: *AttributeExpression
+//
+// Return: []*AttributeExpression
+//
+// Returns every reference in the expression, in the order they appear
+func FindAttributeReferences(expression *AttributeExpression) []*AttributeExpression {
+
+	if expression.Kind == "reference" {
+		return []*AttributeExpression{expression}
+	}
+
+	references := []*AttributeExpression{}
+	for _, operand := range expression.Operands {
+		references = append(references, FindAttributeReferences(operand)...)
+	}
+
+	return references
+}
+
+// Name: EvaluateAttributeExpression
+//
+// Parameters: *AttributeExpression, []*TreeNode, map[*TreeNode]map[string]interface{}
+//
+// Return: interface{}, error
+//
+// Evaluates the expression for one use of a production, whose symbols are the nodes in order from the left-hand
+// side. Values are numbers, strings or booleans, and the referenced attributes must already have been evaluated
+func EvaluateAttributeExpression(expression *AttributeExpression, nodes []*TreeNode, values map[*TreeNode]map[string]interface{}) (interface{}, error) {
+
+	switch expression.Kind {
+	case "number", "string", "bool":
+		return expression.Value, nil
+
+	case "reference":
+		if expression.Position >= len(nodes) {
+			return nil, fmt.Errorf("reference to missing symbol: $%v", expression.Position)
+		}
+		node := nodes[expression.Position]
+
+		value, found := values[node][expression.Attribute]
+		if !found && expression.Attribute == lexeme_attribute {
+			return ExpressionText([]*TreeNode{node}), nil
+		}
+		if !found {
+			return nil, fmt.Errorf("attribute %v of %v has no value", expression.Attribute, node.Symbol)
+		}
+		return value, nil
+
+	case "conditional":
+		condition, err := EvaluateAttributeExpression(expression.Operands[0], nodes, values)
+		if err != nil {
+			return nil, err
+		}
+		truth, is_bool := condition.(bool)
+		if !is_bool {
+			return nil, fmt.Errorf("condition is not a boolean: %v", condition)
+		}
+		if truth {
+			return EvaluateAttributeExpression(expression.Operands[1], nodes, values)
+		}
+		return EvaluateAttributeExpression(expression.Operands[2], nodes, values)
+	}
+
+	operands := []interface{}{}
+	for _, operand := range expression.Operands {
+		value, err := EvaluateAttributeExpression(operand, nodes, values)
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, value)
+	}
+
+	switch expression.Kind {
+	case "unary":
+		return ApplyUnaryAttributeOperator(expression.Operator, operands[0])
+	case "binary":
+		return ApplyBinaryAttributeOperator(expression.Operator, operands[0], operands[1])
+	case "call":
+		return CallAttributeFunction(expression.Operator, operands)
+	}
+
+	return nil, fmt.Errorf("unknown expression: %v", expression.Kind)
+}
+
+// Name: ApplyUnaryAttributeOperator
+//
+// Parameters: string, interface{}
+//
+// Return: interface{}, error
+//
+// Negates a number or a boolean
+func ApplyUnaryAttributeOperator(operator string, operand interface{}) (interface{}, error) {
+
+	if number, is_number := operand.(float64); is_number && operator == "-" {
+		return -number, nil
+	}
+	if truth, is_bool := operand.(bool); is_bool && operator == "!" {
+		return !truth, nil
+	}
+
+	return nil, fmt.Errorf("operator %v cannot be applied to %v", operator, FormatAttributeValue(operand))
+}
+
+// Name: ApplyBinaryAttributeOperator
+//
+// Parameters: string, interface{}, interface{}
+//
+// Return: interface{}, error
+//
+// Applies the operator to two values. Numbers support arithmetic and comparison, strings concatenation with +
+// and comparison, and booleans the logical operators. Values of any type can be compared for equality
+func ApplyBinaryAttributeOperator(operator string, left interface{}, right interface{}) (interface{}, error) {
+
+	switch operator {
+	case "==":
+		return left == right, nil
+	case "!=":
+		return left != right, nil
+	}
+
+	left_number, left_is_number := left.(float64)
+	right_number, right_is_number := right.(float64)
+
+	if left_is_number && right_is_number {
+		switch operator {
+		case "+":
+			return left_number + right_number, nil
+		case "-":
+			return left_number - right_number, nil
+		case "*":
+			return left_number * right_number, nil
+		case "/":
+			if right_number == 0 {
+				return nil, fmt.Errorf("division by zero")
+			}
+			return left_number / right_number, nil
+		case "%":
+			if right_number == 0 {
+				return nil, fmt.Errorf("division by zero")
+			}
+			return math.Mod(left_number, right_number), nil
+		case "<":
+			return left_number < right_number, nil
+		case "<=":
+			return left_number <= right_number, nil
+		case ">":
+			return left_number > right_number, nil
+		case ">=":
+			return left_number >= right_number, nil
+		}
+	}
+
+	left_string, left_is_string := left.(string)
+	right_string, right_is_string := right.(string)
+
+	if left_is_string && right_is_string {
+		switch operator {
+		case "+":
+			return left_string + right_string, nil
+		case "<":
+			return left_string < right_string, nil
+		case "<=":
+			return left_string <= right_string, nil
+		case ">":
+			return left_string > right_string, nil
+		case ">=":
+			return left_string >= right_string, nil
+		}
+	}
+
+	left_bool, left_is_bool := left.(bool)
+	right_bool, right_is_bool := right.(bool)
+
+	if left_is_bool && right_is_bool {
+		switch operator {
+		case "&&":
+			return left_bool && right_bool, nil
+		case "||":
+			return left_bool || right_bool, nil
+		}
+	}
+
+	return nil, fmt.Errorf("operator %v cannot be applied to %v and %v", operator, FormatAttributeValue(left), FormatAttributeValue(right))
+}
+
+// Name: CallAttributeFunction
+//
+// Parameters: string, []interface{}
+//
+// Return: interface{}, error
+//
+// Calls a function of the attribute expression language with the evaluated arguments
+func CallAttributeFunction(name string, arguments []interface{}) (interface{}, error) {
+
+	switch name {
+	case "str":
+		return FormatAttributeValue(arguments[0]), nil
+
+	case "num":
+		if number, is_number := arguments[0].(float64); is_number {
+			return number, nil
+		}
+		number, err := strconv.ParseFloat(strings.TrimSpace(FormatAttributeValue(arguments[0])), 64)
+		if err != nil {
+			return nil, fmt.Errorf("num cannot convert %v to a number", FormatAttributeValue(arguments[0]))
+		}
+		return number, nil
+
+	case "len":
+		text, is_string := arguments[0].(string)
+		if !is_string {
+			return nil, fmt.Errorf("len expects a string but was given %v", FormatAttributeValue(arguments[0]))
+		}
+		return float64(len([]rune(text))), nil
+
+	case "min", "max":
+		left, left_is_number := arguments[0].(float64)
+		right, right_is_number := arguments[1].(float64)
+		if !left_is_number || !right_is_number {
+			return nil, fmt.Errorf("%v expects numbers but was given %v and %v", name, FormatAttributeValue(arguments[0]), FormatAttributeValue(arguments[1]))
+		}
+		if name == "min" {
+			return math.Min(left, right), nil
+		}
+		return math.Max(left, right), nil
+
+	case "concat":
+		text := ""
+		for _, argument := range arguments {
+			text += FormatAttributeValue(argument)
+		}
+		return text, nil
+	}
+
+	return nil, fmt.Errorf("unknown function: %v", name)
+}
+
+// Name: FormatAttributeValue
+//
+// Parameters: interface{}
+//
+// Return: string
+//
+// Returns the text of an attribute value, writing whole numbers without a decimal point
+func FormatAttributeValue(value interface{}) string {
+
+	switch value := value.(type) {
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case string:
+		return value
+	case bool:
+		return strconv.FormatBool(value)
+	case nil:
+		return "nil"
+	}
+
+	return fmt.Sprintf("%v", value)
+}
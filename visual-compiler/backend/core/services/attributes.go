@@ -0,0 +1,496 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// struct to store an equation defining an attribute of a production.
+//
+// the target is a reference such as $0.value. Position 0 is the left-hand side, so an equation for $0 defines a
+// synthesised attribute and an equation for a symbol of the output an inherited attribute
+type AttributeEquation struct {
+	Target     string `json:"target"`
+	Expression string `json:"expression"`
+}
+
+// struct to store the attribute equations attached to a production of the grammar
+type AttributeRule struct {
+	Input     string              `json:"input"`
+	Output    []string            `json:"output"`
+	Equations []AttributeEquation `json:"equations"`
+}
+
+// struct to store an attribute grammar
+type AttributeGrammar struct {
+	Rules []AttributeRule `json:"rules"`
+}
+
+// struct to store a parsed attribute equation
+type CompiledEquation struct {
+	Position   int
+	Attribute  string
+	Expression *AttributeExpression
+}
+
+// struct to store an attribute of a node of the tree, the node being its pre-order number
+type AttributeInstance struct {
+	Node      int    `json:"node"`
+	Symbol    string `json:"symbol"`
+	Attribute string `json:"attribute"`
+}
+
+// struct to store a tree with the evaluated attributes of each node and the order they were evaluated in
+type AttributedTree struct {
+	Root  *AttributedNode     `json:"root"`
+	Order []AttributeInstance `json:"order"`
+}
+
+// struct to store a node of the attributed tree
+type AttributedNode struct {
+	Symbol     string                 `json:"symbol"`
+	Value      string                 `json:"value"`
+	Attributes map[string]interface{} `json:"attributes"`
+	Children   []*AttributedNode      `json:"children"`
+}
+
+// struct to store one use of an equation at a node of the tree
+type AttributeTask struct {
+	Instance     AttributeInstance
+	Equation     CompiledEquation
+	Nodes        []*TreeNode
+	Dependencies []int
+}
+
+// Name: CompileAttributeRule
+//
+// Parameters: AttributeRule
+//
+// Return: []CompiledEquation, error
+//
+// Parses the equations of a production and checks that each target and reference names a symbol of the production,
+// that no attribute is defined twice and that the equations of the production do not depend on each other in a cycle
+func CompileAttributeRule(rule AttributeRule) ([]CompiledEquation, error) {
+
+	production := FormatParsingRule(ParsingRule{Input: rule.Input, Output: rule.Output})
+	symbols := len(RemoveEmptySymbols(rule.Output))
+
+	equations := []CompiledEquation{}
+	defined := map[string]int{}
+
+	for _, equation := range rule.Equations {
+		position, attribute, err := ParseAttributeReference(equation.Target)
+		if err != nil {
+			return nil, fmt.Errorf("%v in %v", err, production)
+		}
+		if position > symbols {
+			return nil, fmt.Errorf("target %v refers to a symbol that %v does not have", equation.Target, production)
+		}
+		if attribute == lexeme_attribute {
+			return nil, fmt.Errorf("the %v attribute cannot be defined: %v", lexeme_attribute, production)
+		}
+
+		target := fmt.Sprintf("$%v.%v", position, attribute)
+		if _, found := defined[target]; found {
+			return nil, fmt.Errorf("attribute %v defined more than once in %v", target, production)
+		}
+
+		expression, err := ParseAttributeExpression(equation.Expression)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expression for %v in %v: %v", target, production, err)
+		}
+
+		for _, reference := range FindAttributeReferences(expression) {
+			if reference.Position > symbols {
+				return nil, fmt.Errorf("expression for %v refers to a symbol that %v does not have: $%v", target, production, reference.Position)
+			}
+		}
+
+		defined[target] = len(equations)
+		equations = append(equations, CompiledEquation{Position: position, Attribute: attribute, Expression: expression})
+	}
+
+	// equations of the same production may not depend on each other in a cycle
+	for start := range equations {
+		path := FindLocalAttributeCycle(equations, defined, start, []int{}, map[int]bool{})
+		if path != nil {
+			cycle := []string{}
+			for _, index := range path {
+				cycle = append(cycle, fmt.Sprintf("$%v.%v", equations[index].Position, equations[index].Attribute))
+			}
+			return nil, fmt.Errorf("circular attribute equations in %v: %v", production, strings.Join(cycle, " -> "))
+		}
+	}
+
+	return equations, nil
+}
+
+// Name: FindLocalAttributeCycle
+//
+// Parameters: []CompiledEquation, map[string]int, int, []int, map[int]bool
+//
+// Return: []int
+//
+// Searches the equations of one production for a cycle through the given equation, returning the equations of the
+// cycle ending with the first one again, or nil when there is none
+func FindLocalAttributeCycle(equations []CompiledEquation, defined map[string]int, current int, path []int, visiting map[int]bool) []int {
+
+	if len(path) > 0 && current == path[0] {
+		return append(path, current)
+	}
+	if visiting[current] {
+		return nil
+	}
+	visiting[current] = true
+	path = append(path, current)
+
+	for _, reference := range FindAttributeReferences(equations[current].Expression) {
+		dependency, found := defined[fmt.Sprintf("$%v.%v", reference.Position, reference.Attribute)]
+		if !found {
+			continue
+		}
+
+		cycle := FindLocalAttributeCycle(equations, defined, dependency, path, visiting)
+		if cycle != nil {
+			return cycle
+		}
+	}
+
+	return nil
+}
+
+// Name: RemoveEmptySymbols
+//
+// Parameters: []string
+//
+// Return: []string
+//
+// Returns the symbols of a rule output without ε, which has no node in the tree
+func RemoveEmptySymbols(output []string) []string {
+
+	symbols := []string{}
+	for _, symbol := range output {
+		if symbol != "ε" {
+			symbols = append(symbols, symbol)
+		}
+	}
+
+	return symbols
+}
+
+// Name: MatchesProduction
+//
+// Parameters: *TreeNode, AttributeRule
+//
+// Return: bool
+//
+// Determines if the node was created by the production: its symbol is the input and its children match the output in order.
+// Literal terminals match the value of the child
+func MatchesProduction(node *TreeNode, rule AttributeRule) bool {
+
+	output := RemoveEmptySymbols(rule.Output)
+
+	if node.Symbol != rule.Input || len(node.Children) != len(output) || (len(output) == 0 && node.Value != "") {
+		return false
+	}
+
+	for i, symbol := range output {
+		child := node.Children[i]
+		if child == nil {
+			return false
+		}
+		if child.Symbol != symbol && !MatchesTerminal(NormaliseLiteral(symbol), TypeValue{Type: child.Symbol, Value: child.Value}) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Name: EvaluateAttributes
+//
+// Parameters: SyntaxTree, AttributeGrammar
+//
+// Return: AttributedTree, error
+//
+// Evaluates the attribute grammar over the tree. The equations of each production are applied at every node the
+// production created, the attribute instances are ordered so that each is evaluated after the attributes it depends on,
+// and an error is returned for an attribute without an equation, an attribute defined twice or a circular dependency.
+// The lexeme attribute of every node is its source text
+func EvaluateAttributes(syntax_tree SyntaxTree, attribute_grammar AttributeGrammar) (AttributedTree, error) {
+
+	if syntax_tree.Root == nil {
+		return AttributedTree{}, fmt.Errorf("syntax tree is empty")
+	}
+
+	compiled := [][]CompiledEquation{}
+	for _, rule := range attribute_grammar.Rules {
+		equations, err := CompileAttributeRule(rule)
+		if err != nil {
+			return AttributedTree{}, err
+		}
+		compiled = append(compiled, equations)
+	}
+
+	numbers := NumberTreeNodes(syntax_tree.Root)
+	tasks, err := CreateAttributeTasks(syntax_tree.Root, attribute_grammar, compiled, numbers)
+	if err != nil {
+		return AttributedTree{}, err
+	}
+
+	order, err := OrderAttributeTasks(tasks)
+	if err != nil {
+		return AttributedTree{}, err
+	}
+
+	values := map[*TreeNode]map[string]interface{}{}
+	evaluated := []AttributeInstance{}
+
+	for _, index := range order {
+		task := tasks[index]
+
+		value, err := EvaluateAttributeExpression(task.Equation.Expression, task.Nodes, values)
+		if err != nil {
+			return AttributedTree{}, fmt.Errorf("could not evaluate %v.%v of node %v: %v", task.Instance.Symbol, task.Instance.Attribute, task.Instance.Node, err)
+		}
+
+		target := task.Nodes[task.Equation.Position]
+		if values[target] == nil {
+			values[target] = map[string]interface{}{}
+		}
+		values[target][task.Instance.Attribute] = value
+		evaluated = append(evaluated, task.Instance)
+	}
+
+	return AttributedTree{Root: CreateAttributedNode(syntax_tree.Root, values), Order: evaluated}, nil
+}
+
+// Name: CreateAttributeTasks
+//
+// Parameters: *TreeNode, AttributeGrammar, [][]CompiledEquation, map[*TreeNode]int
+//
+// Return: []AttributeTask, error
+//
+// Creates the attribute instances defined at every node of the tree, in pre-order, and links each one to the
+// instances it depends on
+func CreateAttributeTasks(root *TreeNode, attribute_grammar AttributeGrammar, compiled [][]CompiledEquation, numbers map[*TreeNode]int) ([]AttributeTask, error) {
+
+	tasks := []AttributeTask{}
+	defined := map[AttributeInstance]int{}
+	stack := []*TreeNode{root}
+
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if node == nil {
+			continue
+		}
+		for i := len(node.Children) - 1; i >= 0; i-- {
+			stack = append(stack, node.Children[i])
+		}
+
+		for r, rule := range attribute_grammar.Rules {
+			if !MatchesProduction(node, rule) {
+				continue
+			}
+
+			nodes := append([]*TreeNode{node}, node.Children...)
+
+			for _, equation := range compiled[r] {
+				target := nodes[equation.Position]
+				instance := AttributeInstance{Node: numbers[target], Symbol: target.Symbol, Attribute: equation.Attribute}
+
+				if _, found := defined[instance]; found {
+					return nil, fmt.Errorf("attribute %v of %v (node %v) is defined by more than one equation", instance.Attribute, instance.Symbol, instance.Node)
+				}
+
+				defined[instance] = len(tasks)
+				tasks = append(tasks, AttributeTask{Instance: instance, Equation: equation, Nodes: nodes})
+			}
+			break
+		}
+	}
+
+	for i := range tasks {
+		for _, reference := range FindAttributeReferences(tasks[i].Equation.Expression) {
+			if reference.Attribute == lexeme_attribute {
+				continue
+			}
+
+			node := tasks[i].Nodes[reference.Position]
+			dependency := AttributeInstance{Node: numbers[node], Symbol: node.Symbol, Attribute: reference.Attribute}
+
+			index, found := defined[dependency]
+			if !found {
+				return nil, fmt.Errorf("attribute %v of %v (node %v) has no equation", dependency.Attribute, dependency.Symbol, dependency.Node)
+			}
+			tasks[i].Dependencies = append(tasks[i].Dependencies, index)
+		}
+	}
+
+	return tasks, nil
+}
+
+// Name: OrderAttributeTasks
+//
+// Parameters: []AttributeTask
+//
+// Return: []int, error
+//
+// Orders the attribute instances so that each one comes after those it depends on. Instances that are ready at the
+// same time keep the pre-order of the tree. Returns an error naming the instances of a cycle when there is one
+func OrderAttributeTasks(tasks []AttributeTask) ([]int, error) {
+
+	remaining := make([]int, len(tasks))
+	dependants := make([][]int, len(tasks))
+
+	for i, task := range tasks {
+		remaining[i] = len(task.Dependencies)
+		for _, dependency := range task.Dependencies {
+			dependants[dependency] = append(dependants[dependency], i)
+		}
+	}
+
+	ready := []int{}
+	for i := range tasks {
+		if remaining[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	order := []int{}
+	for len(ready) > 0 {
+		sort.Ints(ready)
+		current := ready[0]
+		ready = ready[1:]
+		order = append(order, current)
+
+		for _, dependant := range dependants[current] {
+			remaining[dependant]--
+			if remaining[dependant] == 0 {
+				ready = append(ready, dependant)
+			}
+		}
+	}
+
+	if len(order) == len(tasks) {
+		return order, nil
+	}
+
+	// every instance left waits on another one left, so following dependencies from any of them reaches a cycle
+	current := -1
+	for i := range tasks {
+		if remaining[i] > 0 {
+			current = i
+			break
+		}
+	}
+
+	seen := map[int]int{}
+	path := []int{}
+	for {
+		if start, found := seen[current]; found {
+			path = append(path[start:], current)
+			break
+		}
+		seen[current] = len(path)
+		path = append(path, current)
+
+		for _, dependency := range tasks[current].Dependencies {
+			if remaining[dependency] > 0 {
+				current = dependency
+				break
+			}
+		}
+	}
+
+	cycle := []string{}
+	for _, index := range path {
+		instance := tasks[index].Instance
+		cycle = append(cycle, fmt.Sprintf("%v.%v (node %v)", instance.Symbol, instance.Attribute, instance.Node))
+	}
+
+	return nil, fmt.Errorf("circular attribute dependency: %v", strings.Join(cycle, " -> "))
+}
+
+// Name: CreateAttributedNode
+//
+// Parameters: *TreeNode, map[*TreeNode]map[string]interface{}
+//
+// Return: *AttributedNode
+//
+// Recursively copies the node and its children with their evaluated attributes
+func CreateAttributedNode(node *TreeNode, values map[*TreeNode]map[string]interface{}) *AttributedNode {
+
+	attributes := values[node]
+	if attributes == nil {
+		attributes = map[string]interface{}{}
+	}
+
+	attributed_node := &AttributedNode{
+		Symbol:     node.Symbol,
+		Value:      node.Value,
+		Attributes: attributes,
+		Children:   []*AttributedNode{},
+	}
+
+	for _, child := range node.Children {
+		if child != nil {
+			attributed_node.Children = append(attributed_node.Children, CreateAttributedNode(child, values))
+		}
+	}
+
+	return attributed_node
+}
+
+// Name: ConvertAttributedTreeToString
+//
+// Parameters: *AttributedNode, string, bool
+//
+// Return: string
+//
+// Recursively build a string of the attributed tree with the attributes of each node in brackets, sorted by name
+func ConvertAttributedTreeToString(node *AttributedNode, branch_indent string, is_leaf bool) string {
+
+	if node == nil {
+		return branch_indent + "\n"
+	}
+
+	names := []string{}
+	for name := range node.Attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	attributes := []string{}
+	for _, name := range names {
+		attributes = append(attributes, name+"="+FormatAttributeValue(node.Attributes[name]))
+	}
+
+	label := node.Symbol
+	if node.Value != "" {
+		label += ": " + node.Value
+	}
+	if len(attributes) > 0 {
+		label += " [" + strings.Join(attributes, ", ") + "]"
+	}
+
+	final_tree := ""
+	new_string := branch_indent
+
+	if is_leaf {
+		final_tree += fmt.Sprintf("%s%s %s\n", branch_indent, "└── ", label)
+		new_string += "    "
+	} else {
+		final_tree += fmt.Sprintf("%s%s %s\n", branch_indent, "├── ", label)
+		new_string += "│   "
+	}
+
+	for i, child := range node.Children {
+		final_tree += ConvertAttributedTreeToString(child, new_string, i == len(node.Children)-1)
+	}
+
+	return final_tree
+}
@@ -0,0 +1,574 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Struct for the tokens of the textual grammar notation
+type NotationToken struct {
+	Kind  string
+	Value string
+	Line  int
+}
+
+// Struct to track reading of the textual grammar notation
+type NotationReader struct {
+	Tokens    []NotationToken
+	Position  int
+	Lhs       string
+	Counter   map[string]int
+	Variables []string
+	Rules     []ParsingRule
+}
+
+// Struct for a helper variable created while desugaring EBNF
+type NotationHelper struct {
+	Kind  string
+	Rules [][]string
+}
+
+var helper_variable = regexp.MustCompile(`^(.+)_(OPT|REP|GRP)\d+$`)
+var notation_identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Name: ReadGrammarNotation
+//
+// Parameters: string
+//
+// Return: Grammar, error
+//
+// Receive a grammar written in BNF/EBNF notation and desugar it into the grammar struct.
+// The first production defines the start variable. Every name on the left of ::= is a variable,
+// every other name is a terminal and every quoted string is a literal terminal matching the token value
+func ReadGrammarNotation(input string) (Grammar, error) {
+
+	if strings.TrimSpace(input) == "" {
+		return Grammar{}, fmt.Errorf("no grammar entered")
+	}
+
+	tokens, err := TokeniseNotation(input)
+	if err != nil {
+		return Grammar{}, err
+	}
+
+	reader := &NotationReader{
+		Tokens:  tokens,
+		Counter: make(map[string]int),
+	}
+
+	declared := make(map[string]bool)
+	for i := 0; i+1 < len(tokens); i++ {
+		if tokens[i].Kind == "NAME" && tokens[i+1].Kind == "DEFINE" {
+			if !declared[tokens[i].Value] {
+				declared[tokens[i].Value] = true
+				reader.Variables = append(reader.Variables, tokens[i].Value)
+			}
+		}
+	}
+
+	if len(reader.Variables) == 0 {
+		return Grammar{}, fmt.Errorf("no productions found in grammar notation")
+	}
+
+	for reader.Position < len(reader.Tokens) {
+		err := reader.readProduction()
+		if err != nil {
+			return Grammar{}, err
+		}
+	}
+
+	terminals := []string{}
+	known_terminals := make(map[string]bool)
+	is_variable := make(map[string]bool)
+
+	for _, variable := range reader.Variables {
+		is_variable[variable] = true
+	}
+
+	for _, rule := range reader.Rules {
+		for i, symbol := range rule.Output {
+			if symbol == "ε" || is_variable[symbol] || IsLiteral(symbol) {
+				continue
+			}
+
+			// terminals are stored in upper case by the grammar, so the rules must match
+			rule.Output[i] = strings.ToUpper(symbol)
+			if !known_terminals[rule.Output[i]] {
+				known_terminals[rule.Output[i]] = true
+				terminals = append(terminals, rule.Output[i])
+			}
+		}
+	}
+
+	grammar := Grammar{
+		Variables: reader.Variables,
+		Terminals: terminals,
+		Start:     reader.Variables[0],
+		Rules:     reader.Rules,
+	}
+
+	return ValidateGrammar(grammar)
+}
+
+// Name: TokeniseNotation
+//
+// Parameters: string
+//
+// Return: []NotationToken, error
+//
+// Split the textual grammar into names, quoted literal terminals, the definition symbol and EBNF operators.
+// A name directly followed by a colon and a quoted string gives a literal constrained to that token type
+func TokeniseNotation(input string) ([]NotationToken, error) {
+
+	tokens := []NotationToken{}
+	characters := []rune(input)
+	line := 1
+
+	for i := 0; i < len(characters); {
+		character := characters[i]
+
+		switch {
+		case character == '\n':
+			line++
+			i++
+
+		case unicode.IsSpace(character):
+			i++
+
+		case character == '#':
+			for i < len(characters) && characters[i] != '\n' {
+				i++
+			}
+
+		case strings.HasPrefix(string(characters[i:]), "::="):
+			tokens = append(tokens, NotationToken{Kind: "DEFINE", Value: "::=", Line: line})
+			i += 3
+
+		case strings.HasPrefix(string(characters[i:]), "->"):
+			tokens = append(tokens, NotationToken{Kind: "DEFINE", Value: "->", Line: line})
+			i += 2
+
+		case strings.ContainsRune("|[]{}()", character):
+			tokens = append(tokens, NotationToken{Kind: string(character), Value: string(character), Line: line})
+			i++
+
+		case character == 'ε':
+			tokens = append(tokens, NotationToken{Kind: "EMPTY", Value: "ε", Line: line})
+			i++
+
+		case character == '"' || character == '\'':
+			value, end, err := ReadNotationQuoted(characters, i, line)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, NotationToken{Kind: "QUOTED", Value: FormatLiteral("", value), Line: line})
+			i = end
+
+		case character == '<':
+			end := i + 1
+			for end < len(characters) && characters[end] != '>' && characters[end] != '\n' {
+				end++
+			}
+			if end >= len(characters) || characters[end] != '>' {
+				return nil, fmt.Errorf("unterminated <name> on line %d", line)
+			}
+			name := strings.TrimSpace(string(characters[i+1 : end]))
+			if !notation_identifier.MatchString(name) {
+				return nil, fmt.Errorf("invalid name <%s> on line %d", name, line)
+			}
+			tokens = append(tokens, NotationToken{Kind: "NAME", Value: name, Line: line})
+			i = end + 1
+
+		case character == '_' || unicode.IsLetter(character) || unicode.IsDigit(character):
+			end := i
+			for end < len(characters) && (characters[end] == '_' || unicode.IsLetter(characters[end]) || unicode.IsDigit(characters[end])) {
+				end++
+			}
+			name := string(characters[i:end])
+
+			if end+1 < len(characters) && characters[end] == ':' && (characters[end+1] == '"' || characters[end+1] == '\'') {
+				value, quoted_end, err := ReadNotationQuoted(characters, end+1, line)
+				if err != nil {
+					return nil, err
+				}
+				tokens = append(tokens, NotationToken{Kind: "QUOTED", Value: FormatLiteral(name, value), Line: line})
+				i = quoted_end
+				continue
+			}
+
+			tokens = append(tokens, NotationToken{Kind: "NAME", Value: name, Line: line})
+			i = end
+
+		default:
+			return nil, fmt.Errorf("unexpected character '%c' on line %d", character, line)
+		}
+	}
+
+	return tokens, nil
+}
+
+// Name: ReadNotationQuoted
+//
+// Parameters: []rune, int, int
+//
+// Return: string, int, error
+//
+// Reads the quoted string starting at the given position. Returns its value and the position after the closing quote
+func ReadNotationQuoted(characters []rune, start int, line int) (string, int, error) {
+
+	quote := characters[start]
+	end := start + 1
+
+	for end < len(characters) && characters[end] != quote && characters[end] != '\n' {
+		end++
+	}
+	if end >= len(characters) || characters[end] != quote {
+		return "", 0, fmt.Errorf("unterminated quoted terminal on line %d", line)
+	}
+	if end == start+1 {
+		return "", 0, fmt.Errorf("empty quoted terminal on line %d", line)
+	}
+
+	return string(characters[start+1 : end]), end + 1, nil
+}
+
+// Name: readProduction
+//
+// Parameters: none
+//
+// Return: error
+//
+// Reads one production of the form NAME ::= alternatives and adds its rules
+func (reader *NotationReader) readProduction() error {
+
+	if reader.Position+1 >= len(reader.Tokens) || reader.Tokens[reader.Position].Kind != "NAME" || reader.Tokens[reader.Position+1].Kind != "DEFINE" {
+		token := reader.Tokens[reader.Position]
+		return fmt.Errorf("expected a production of the form NAME ::= ... on line %d", token.Line)
+	}
+
+	reader.Lhs = reader.Tokens[reader.Position].Value
+	reader.Position += 2
+
+	alternatives, err := reader.readAlternatives("")
+	if err != nil {
+		return err
+	}
+
+	for _, output := range alternatives {
+		reader.Rules = append(reader.Rules, ParsingRule{Input: reader.Lhs, Output: output})
+	}
+
+	return nil
+}
+
+// Name: readAlternatives
+//
+// Parameters: string
+//
+// Return: [][]string, error
+//
+// Reads alternatives separated by | until the closing bracket or the start of the next production
+func (reader *NotationReader) readAlternatives(closing string) ([][]string, error) {
+
+	alternatives := [][]string{}
+
+	for {
+		sequence, err := reader.readSequence()
+		if err != nil {
+			return nil, err
+		}
+
+		if len(sequence) == 0 {
+			sequence = []string{"ε"}
+		}
+		alternatives = append(alternatives, sequence)
+
+		if reader.Position < len(reader.Tokens) && reader.Tokens[reader.Position].Kind == "|" {
+			reader.Position++
+			continue
+		}
+
+		break
+	}
+
+	if closing != "" {
+		if reader.Position >= len(reader.Tokens) {
+			return nil, fmt.Errorf("missing closing '%s' in production %s", closing, reader.Lhs)
+		}
+		if reader.Tokens[reader.Position].Kind != closing {
+			token := reader.Tokens[reader.Position]
+			return nil, fmt.Errorf("expected '%s' but found '%s' on line %d", closing, token.Value, token.Line)
+		}
+		reader.Position++
+	}
+
+	return alternatives, nil
+}
+
+// Name: readSequence
+//
+// Parameters: none
+//
+// Return: []string, error
+//
+// Reads a sequence of symbols, desugaring [ ], { } and ( ) into helper variables
+func (reader *NotationReader) readSequence() ([]string, error) {
+
+	sequence := []string{}
+
+	for reader.Position < len(reader.Tokens) {
+		token := reader.Tokens[reader.Position]
+
+		if token.Kind == "NAME" && reader.Position+1 < len(reader.Tokens) && reader.Tokens[reader.Position+1].Kind == "DEFINE" {
+			break
+		}
+
+		switch token.Kind {
+		case "NAME", "QUOTED":
+			sequence = append(sequence, token.Value)
+			reader.Position++
+
+		case "EMPTY":
+			reader.Position++
+
+		case "[", "{", "(":
+			reader.Position++
+			closing := map[string]string{"[": "]", "{": "}", "(": ")"}[token.Kind]
+
+			alternatives, err := reader.readAlternatives(closing)
+			if err != nil {
+				return nil, err
+			}
+
+			if token.Kind == "(" && len(alternatives) == 1 {
+				for _, symbol := range alternatives[0] {
+					if symbol != "ε" {
+						sequence = append(sequence, symbol)
+					}
+				}
+				continue
+			}
+
+			sequence = append(sequence, reader.addHelper(token.Kind, alternatives))
+
+		case "DEFINE":
+			return nil, fmt.Errorf("unexpected '%s' on line %d", token.Value, token.Line)
+
+		default:
+			return sequence, nil
+		}
+	}
+
+	return sequence, nil
+}
+
+// Name: addHelper
+//
+// Parameters: string, [][]string
+//
+// Return: string
+//
+// Creates a helper variable for an optional, repeated or grouped part of a production
+func (reader *NotationReader) addHelper(bracket string, alternatives [][]string) string {
+
+	kind := map[string]string{"[": "OPT", "{": "REP", "(": "GRP"}[bracket]
+
+	name := ""
+	for name == "" || reader.isVariable(name) {
+		reader.Counter[reader.Lhs]++
+		name = fmt.Sprintf("%s_%s%d", reader.Lhs, kind, reader.Counter[reader.Lhs])
+	}
+
+	reader.Variables = append(reader.Variables, name)
+
+	for _, alternative := range alternatives {
+		output := alternative
+		if kind == "REP" {
+			output = []string{}
+			for _, symbol := range alternative {
+				if symbol != "ε" {
+					output = append(output, symbol)
+				}
+			}
+			output = append(output, name)
+		}
+		reader.Rules = append(reader.Rules, ParsingRule{Input: name, Output: output})
+	}
+
+	if kind != "GRP" {
+		reader.Rules = append(reader.Rules, ParsingRule{Input: name, Output: []string{"ε"}})
+	}
+
+	return name
+}
+
+// Name: isVariable
+//
+// Parameters: string
+//
+// Return: bool
+//
+// Determines if the name is already used by a variable
+func (reader *NotationReader) isVariable(name string) bool {
+
+	for _, variable := range reader.Variables {
+		if variable == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Name: ConvertGrammarToNotation
+//
+// Parameters: Grammar
+//
+// Return: string
+//
+// Pretty-print the grammar in EBNF notation. Helper variables created by ReadGrammarNotation are folded back into [ ], { } and ( )
+func ConvertGrammarToNotation(grammar Grammar) string {
+
+	order := []string{}
+	grouped := make(map[string][][]string)
+
+	if grammar.Start != "" {
+		order = append(order, grammar.Start)
+	}
+
+	for _, rule := range grammar.Rules {
+		if _, exists := grouped[rule.Input]; !exists && rule.Input != grammar.Start {
+			order = append(order, rule.Input)
+		}
+		grouped[rule.Input] = append(grouped[rule.Input], rule.Output)
+	}
+
+	helpers := make(map[string]NotationHelper)
+	for variable, outputs := range grouped {
+		if helper, valid := FindNotationHelper(variable, outputs); valid {
+			helpers[variable] = helper
+		}
+	}
+
+	var output strings.Builder
+
+	for _, variable := range order {
+		if _, is_helper := helpers[variable]; is_helper {
+			continue
+		}
+		if _, exists := grouped[variable]; !exists {
+			continue
+		}
+
+		alternatives := []string{}
+		for _, rule_output := range grouped[variable] {
+			alternatives = append(alternatives, FormatNotationSequence(rule_output, helpers))
+		}
+
+		output.WriteString(fmt.Sprintf("%s ::= %s\n", variable, strings.Join(alternatives, " | ")))
+	}
+
+	return output.String()
+}
+
+// Name: FindNotationHelper
+//
+// Parameters: string, [][]string
+//
+// Return: NotationHelper, bool
+//
+// Determines if the variable is a helper created while desugaring EBNF and recovers the bracketed alternatives
+func FindNotationHelper(variable string, outputs [][]string) (NotationHelper, bool) {
+
+	match := helper_variable.FindStringSubmatch(variable)
+	if match == nil {
+		return NotationHelper{}, false
+	}
+
+	helper := NotationHelper{Kind: match[2]}
+	has_empty := false
+
+	for _, output := range outputs {
+		if len(output) == 1 && output[0] == "ε" {
+			if has_empty {
+				return NotationHelper{}, false
+			}
+			has_empty = true
+			continue
+		}
+
+		if helper.Kind == "REP" {
+			if len(output) == 0 || output[len(output)-1] != variable {
+				return NotationHelper{}, false
+			}
+			output = output[:len(output)-1]
+		}
+
+		for _, symbol := range output {
+			if symbol == variable {
+				return NotationHelper{}, false
+			}
+		}
+
+		helper.Rules = append(helper.Rules, output)
+	}
+
+	if helper.Kind == "GRP" && has_empty {
+		helper.Rules = append(helper.Rules, []string{"ε"})
+	}
+
+	if helper.Kind != "GRP" && !has_empty {
+		return NotationHelper{}, false
+	}
+
+	return helper, len(helper.Rules) > 0
+}
+
+// Name: FormatNotationSequence
+//
+// Parameters: []string, map[string]NotationHelper
+//
+// Return: string
+//
+// Formats the right hand side of a rule. Literal terminals are already quoted, other terminals that
+// are not plain names are quoted
+func FormatNotationSequence(output []string, helpers map[string]NotationHelper) string {
+
+	symbols := []string{}
+
+	for _, symbol := range output {
+		if helper, is_helper := helpers[symbol]; is_helper {
+			alternatives := []string{}
+			for _, rule := range helper.Rules {
+				alternatives = append(alternatives, FormatNotationSequence(rule, helpers))
+			}
+			inner := strings.Join(alternatives, " | ")
+
+			switch helper.Kind {
+			case "OPT":
+				symbols = append(symbols, "[ "+inner+" ]")
+			case "REP":
+				symbols = append(symbols, "{ "+inner+" }")
+			default:
+				symbols = append(symbols, "( "+inner+" )")
+			}
+			continue
+		}
+
+		if symbol == "ε" || notation_identifier.MatchString(symbol) || IsLiteral(symbol) {
+			symbols = append(symbols, symbol)
+		} else if strings.Contains(symbol, `"`) {
+			symbols = append(symbols, "'"+symbol+"'")
+		} else {
+			symbols = append(symbols, `"`+symbol+`"`)
+		}
+	}
+
+	if len(symbols) == 0 {
+		return "ε"
+	}
+
+	return strings.Join(symbols, " ")
+}
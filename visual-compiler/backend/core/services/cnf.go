@@ -0,0 +1,588 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Struct for one step of a grammar transformation
+type GrammarStep struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Grammar     Grammar `json:"grammar"`
+}
+
+// Struct for a rule of a transformed grammar and the original rule it was derived from
+//
+// kept holds the positions of the source output that remain after removing ε rules, and chain holds the
+// unit rules that were removed in front of this rule. Helper rules have no source
+type cnfRule struct {
+	rule   ParsingRule
+	source *ParsingRule
+	kept   []int
+	chain  []*cnfRule
+}
+
+// Struct for a grammar during the conversion to Chomsky normal form
+type cnfGrammar struct {
+	original  Grammar
+	start     string
+	terminals map[string]bool
+	rules     []*cnfRule
+	helpers   map[string]bool
+	names     map[string]bool
+}
+
+// Name: ConvertToCNF
+//
+// Parameters: Grammar
+//
+// Return: Grammar, []GrammarStep, error
+//
+// Convert the grammar to Chomsky normal form. A new start variable is added when the start appears
+// in a rule, then ε rules, unit rules and useless symbols are removed and the remaining rules are split
+// into rules with two variables or one terminal. Every intermediate grammar is returned as a step
+func ConvertToCNF(grammar Grammar) (Grammar, []GrammarStep, error) {
+
+	cnf, steps, err := convertToCNF(grammar)
+	if err != nil {
+		return Grammar{}, nil, err
+	}
+
+	return cnf.snapshot(), steps, nil
+}
+
+// Name: convertToCNF
+//
+// Parameters: Grammar
+//
+// Return: *cnfGrammar, []GrammarStep, error
+//
+// Runs each step of the conversion, keeping the origin of every rule so trees can be mapped back
+func convertToCNF(grammar Grammar) (*cnfGrammar, []GrammarStep, error) {
+
+	if grammar.Start == "" {
+		return nil, nil, fmt.Errorf("no start variable found")
+	}
+
+	if len(grammar.Rules) == 0 {
+		return nil, nil, fmt.Errorf("grammar has no rules")
+	}
+
+	cnf := &cnfGrammar{
+		original:  grammar,
+		start:     grammar.Start,
+		terminals: make(map[string]bool),
+		helpers:   make(map[string]bool),
+		names:     make(map[string]bool),
+	}
+
+	for _, terminal := range grammar.Terminals {
+		cnf.terminals[terminal] = true
+		cnf.names[terminal] = true
+	}
+	for _, variable := range grammar.Variables {
+		cnf.names[variable] = true
+	}
+
+	for i := range grammar.Rules {
+		source := &grammar.Rules[i]
+		output := []string{}
+		kept := []int{}
+
+		for position, symbol := range source.Output {
+			cnf.names[symbol] = true
+			if symbol != "ε" {
+				output = append(output, symbol)
+				kept = append(kept, position)
+			}
+		}
+
+		cnf.addRule(&cnfRule{
+			rule:   ParsingRule{Input: source.Input, Output: output},
+			source: source,
+			kept:   kept,
+		})
+	}
+
+	steps := []GrammarStep{}
+
+	cnf.addStart()
+	steps = append(steps, GrammarStep{
+		Name:        "start",
+		Description: "Add a new start variable when the start variable appears in the output of a rule",
+		Grammar:     cnf.snapshot(),
+	})
+
+	cnf.removeEmptyRules()
+	steps = append(steps, GrammarStep{
+		Name:        "epsilon",
+		Description: "Remove ε rules by adding every combination of the rules without the variables that derive ε",
+		Grammar:     cnf.snapshot(),
+	})
+
+	cnf.removeUnitRules()
+	steps = append(steps, GrammarStep{
+		Name:        "unit",
+		Description: "Remove unit rules by giving each variable the rules of the variables it derives through unit rules",
+		Grammar:     cnf.snapshot(),
+	})
+
+	cnf.removeUselessSymbols()
+	if len(cnf.rules) == 0 {
+		return nil, nil, fmt.Errorf("start variable does not derive any sentence")
+	}
+	steps = append(steps, GrammarStep{
+		Name:        "useless",
+		Description: "Remove variables that derive no sentence and symbols that cannot be reached from the start variable",
+		Grammar:     cnf.snapshot(),
+	})
+
+	cnf.splitRules()
+	steps = append(steps, GrammarStep{
+		Name:        "cnf",
+		Description: "Replace terminals in longer rules by new variables and split rules into rules with two variables",
+		Grammar:     cnf.snapshot(),
+	})
+
+	return cnf, steps, nil
+}
+
+// Name: addRule
+//
+// Parameters: *cnfRule
+//
+// Return: bool
+//
+// Adds the rule unless the same rule already exists, returning whether it was added
+func (c *cnfGrammar) addRule(rule *cnfRule) bool {
+
+	for _, existing := range c.rules {
+		if existing.rule.Input == rule.rule.Input && equalSymbols(existing.rule.Output, rule.rule.Output) {
+			return false
+		}
+	}
+
+	c.rules = append(c.rules, rule)
+
+	return true
+}
+
+// Name: newName
+//
+// Parameters: string
+//
+// Return: string
+//
+// Returns a symbol name based on the given name that is not yet used in the grammar
+func (c *cnfGrammar) newName(base string) string {
+
+	name := base
+	for count := 1; c.names[name]; count++ {
+		name = base + strconv.Itoa(count)
+	}
+
+	c.names[name] = true
+
+	return name
+}
+
+// Name: isVariable
+//
+// Parameters: string
+//
+// Return: bool
+//
+// Determines if the symbol is a variable of the grammar being converted
+func (c *cnfGrammar) isVariable(symbol string) bool {
+
+	if c.terminals[symbol] {
+		return false
+	}
+
+	if symbol == c.start || c.helpers[symbol] {
+		return true
+	}
+
+	for _, rule := range c.rules {
+		if rule.rule.Input == symbol {
+			return true
+		}
+	}
+
+	return ContainsSymbol(c.original.Variables, symbol)
+}
+
+// Name: addStart
+//
+// Parameters: none
+//
+// Return: none
+//
+// Adds a new start variable deriving the old start variable when the start is used in a rule output
+func (c *cnfGrammar) addStart() {
+
+	used := false
+	for _, rule := range c.rules {
+		if ContainsSymbol(rule.rule.Output, c.start) {
+			used = true
+			break
+		}
+	}
+
+	if !used {
+		return
+	}
+
+	start := c.newName(c.start + "_0")
+	c.helpers[start] = true
+
+	c.rules = append([]*cnfRule{{rule: ParsingRule{Input: start, Output: []string{c.start}}, kept: []int{0}}}, c.rules...)
+	c.start = start
+}
+
+// Name: nullable
+//
+// Parameters: none
+//
+// Return: map[string]bool
+//
+// Returns the variables that derive the empty string
+func (c *cnfGrammar) nullable() map[string]bool {
+
+	nullable := make(map[string]bool)
+
+	for changed := true; changed; {
+		changed = false
+
+		for _, rule := range c.rules {
+			if nullable[rule.rule.Input] {
+				continue
+			}
+
+			empty := true
+			for _, symbol := range rule.rule.Output {
+				if !nullable[symbol] {
+					empty = false
+					break
+				}
+			}
+
+			if empty {
+				nullable[rule.rule.Input] = true
+				changed = true
+			}
+		}
+	}
+
+	return nullable
+}
+
+// Name: removeEmptyRules
+//
+// Parameters: none
+//
+// Return: none
+//
+// Replaces each rule by all the combinations of its output without nullable variables and removes the
+// ε rules. The start variable keeps an ε rule when it derives the empty string
+func (c *cnfGrammar) removeEmptyRules() {
+
+	nullable := c.nullable()
+	rules := c.rules
+	c.rules = nil
+
+	for _, rule := range rules {
+		positions := []int{}
+		for i, symbol := range rule.rule.Output {
+			if nullable[symbol] {
+				positions = append(positions, i)
+			}
+		}
+
+		for mask := 0; mask < 1<<len(positions); mask++ {
+			omitted := make(map[int]bool)
+			for bit, position := range positions {
+				if mask&(1<<bit) != 0 {
+					omitted[position] = true
+				}
+			}
+
+			output := []string{}
+			kept := []int{}
+			for i, symbol := range rule.rule.Output {
+				if !omitted[i] {
+					output = append(output, symbol)
+					kept = append(kept, rule.kept[i])
+				}
+			}
+
+			if len(output) == 0 {
+				continue
+			}
+
+			c.addRule(&cnfRule{
+				rule:   ParsingRule{Input: rule.rule.Input, Output: output},
+				source: rule.source,
+				kept:   kept,
+				chain:  rule.chain,
+			})
+		}
+	}
+
+	if nullable[c.start] {
+		c.addRule(&cnfRule{rule: ParsingRule{Input: c.start, Output: []string{}}})
+	}
+}
+
+// Name: isUnit
+//
+// Parameters: *cnfRule
+//
+// Return: bool
+//
+// Determines if the rule derives a single variable
+func (c *cnfGrammar) isUnit(rule *cnfRule) bool {
+
+	return len(rule.rule.Output) == 1 && c.isVariable(rule.rule.Output[0])
+}
+
+// Name: removeUnitRules
+//
+// Parameters: none
+//
+// Return: none
+//
+// Replaces the unit rules of each variable by the other rules of the variables it derives through unit
+// rules, recording the unit rules that were applied
+func (c *cnfGrammar) removeUnitRules() {
+
+	rules := c.rules
+	c.rules = nil
+
+	variables := []string{}
+	for _, rule := range rules {
+		if !ContainsSymbol(variables, rule.rule.Input) {
+			variables = append(variables, rule.rule.Input)
+		}
+	}
+
+	for _, variable := range variables {
+		chains := map[string][]*cnfRule{variable: {}}
+		queue := []string{variable}
+
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+
+			for _, rule := range rules {
+				if rule.rule.Input != current {
+					continue
+				}
+
+				if c.isUnit(rule) {
+					target := rule.rule.Output[0]
+					if _, seen := chains[target]; !seen {
+						chains[target] = append(append([]*cnfRule{}, chains[current]...), rule)
+						queue = append(queue, target)
+					}
+					continue
+				}
+
+				c.addRule(&cnfRule{
+					rule:   ParsingRule{Input: variable, Output: rule.rule.Output},
+					source: rule.source,
+					kept:   rule.kept,
+					chain:  append(append([]*cnfRule{}, chains[current]...), rule.chain...),
+				})
+			}
+		}
+	}
+}
+
+// Name: removeUselessSymbols
+//
+// Parameters: none
+//
+// Return: none
+//
+// Removes the rules using variables that derive no sentence, then the rules of unreachable variables
+func (c *cnfGrammar) removeUselessSymbols() {
+
+	generating := make(map[string]bool)
+
+	for changed := true; changed; {
+		changed = false
+
+		for _, rule := range c.rules {
+			if generating[rule.rule.Input] {
+				continue
+			}
+
+			productive := true
+			for _, symbol := range rule.rule.Output {
+				if c.isVariable(symbol) && !generating[symbol] {
+					productive = false
+					break
+				}
+			}
+
+			if productive {
+				generating[rule.rule.Input] = true
+				changed = true
+			}
+		}
+	}
+
+	rules := []*cnfRule{}
+	for _, rule := range c.rules {
+		productive := generating[rule.rule.Input]
+		for _, symbol := range rule.rule.Output {
+			if c.isVariable(symbol) && !generating[symbol] {
+				productive = false
+			}
+		}
+		if productive {
+			rules = append(rules, rule)
+		}
+	}
+
+	reachable := map[string]bool{c.start: true}
+	queue := []string{c.start}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, rule := range rules {
+			if rule.rule.Input != current {
+				continue
+			}
+			for _, symbol := range rule.rule.Output {
+				if !reachable[symbol] {
+					reachable[symbol] = true
+					queue = append(queue, symbol)
+				}
+			}
+		}
+	}
+
+	c.rules = nil
+	for _, rule := range rules {
+		if reachable[rule.rule.Input] {
+			c.rules = append(c.rules, rule)
+		}
+	}
+}
+
+// Name: splitRules
+//
+// Parameters: none
+//
+// Return: none
+//
+// Replaces the terminals of rules with more than one symbol by helper variables and splits rules with
+// more than two symbols into a chain of rules with two variables
+func (c *cnfGrammar) splitRules() {
+
+	terminal_variables := make(map[string]string)
+	rules := c.rules
+	c.rules = nil
+
+	helpers := []*cnfRule{}
+
+	for _, rule := range rules {
+		output := append([]string{}, rule.rule.Output...)
+
+		if len(output) > 1 {
+			for i, symbol := range output {
+				if c.isVariable(symbol) {
+					continue
+				}
+
+				variable, exists := terminal_variables[symbol]
+				if !exists {
+					variable = c.newName("T_" + symbol)
+					c.helpers[variable] = true
+					terminal_variables[symbol] = variable
+					helpers = append(helpers, &cnfRule{rule: ParsingRule{Input: variable, Output: []string{symbol}}})
+				}
+				output[i] = variable
+			}
+		}
+
+		input := rule.rule.Input
+		current := &cnfRule{source: rule.source, kept: rule.kept, chain: rule.chain}
+
+		for len(output) > 2 {
+			helper := c.newName(rule.rule.Input + "_CNF")
+			c.helpers[helper] = true
+
+			current.rule = ParsingRule{Input: input, Output: []string{output[0], helper}}
+			c.rules = append(c.rules, current)
+
+			input = helper
+			output = output[1:]
+			current = &cnfRule{}
+		}
+
+		current.rule = ParsingRule{Input: input, Output: output}
+		c.rules = append(c.rules, current)
+	}
+
+	c.rules = append(c.rules, helpers...)
+}
+
+// Name: snapshot
+//
+// Parameters: none
+//
+// Return: Grammar
+//
+// Returns the grammar being converted in the form of a grammar
+func (c *cnfGrammar) snapshot() Grammar {
+
+	grammar := Grammar{
+		Variables: []string{c.start},
+		Terminals: c.original.Terminals,
+		Start:     c.start,
+		Rules:     []ParsingRule{},
+	}
+
+	for _, rule := range c.rules {
+		if !ContainsSymbol(grammar.Variables, rule.rule.Input) {
+			grammar.Variables = append(grammar.Variables, rule.rule.Input)
+		}
+
+		output := rule.rule.Output
+		if len(output) == 0 {
+			output = []string{"ε"}
+		}
+
+		grammar.Rules = append(grammar.Rules, ParsingRule{Input: rule.rule.Input, Output: append([]string{}, output...)})
+	}
+
+	return grammar
+}
+
+// Name: equalSymbols
+//
+// Parameters: []string, []string
+//
+// Return: bool
+//
+// Determines if the two sequences of symbols are the same
+func equalSymbols(first []string, second []string) bool {
+
+	if len(first) != len(second) {
+		return false
+	}
+
+	for i := range first {
+		if first[i] != second[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,226 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// struct to store the value of a constant expression.
+//
+// integral marks a whole number folded with integer semantics, and type is the type of the expression,
+// such as INTEGER for a literal or the result type of the type rule applied to an operation
+type ConstantValue struct {
+	Value    float64
+	Integral bool
+	Type     string
+}
+
+// Name: FoldConstant
+//
+// Parameters: []*TreeNode, *SymbolTable, GrammarRules, []TypeRule
+//
+// Return: *ConstantValue, error
+//
+// Computes the value of the expression made up by the nodes when it only holds literals. Names, calls and accesses
+// are not constant, and nil is returned for them. Operators are applied from left to right. An operation is folded
+// as an integer operation, so division truncates, when both operands are integers and the type rule for the
+// operation gives an integer operand type. Returns an error for an illegal constant operation, such as division by zero
+func FoldConstant(nodes []*TreeNode, symbol_table *SymbolTable, rules GrammarRules, type_rules []TypeRule) (*ConstantValue, error) {
+
+	operands := []*ConstantValue{}
+	operand_nodes := [][]*TreeNode{}
+	operators := []string{}
+
+	for _, node := range nodes {
+		if node.Symbol == rules.OperatorRule {
+			operators = append(operators, node.Value)
+			continue
+		}
+
+		if len(node.Children) == 0 && node.Symbol != rules.VariableRule {
+			// leaves outside a term, such as brackets, are not operands
+			continue
+		}
+
+		constant, err := FoldNode(node, symbol_table, rules, type_rules)
+		if err != nil || constant == nil {
+			return nil, err
+		}
+
+		operands = append(operands, constant)
+		operand_nodes = append(operand_nodes, []*TreeNode{node})
+	}
+
+	if len(operands) == 0 || len(operators) != len(operands)-1 {
+		return nil, nil
+	}
+
+	result := operands[0]
+	result_nodes := operand_nodes[0]
+
+	for i, operator := range operators {
+		folded, err := ApplyConstantOperator(operator, result, operands[i+1], type_rules, symbol_table.Promotions)
+		if err != nil {
+			return nil, fmt.Errorf("error: %v in %v %v %v", err.Error(), ExpressionText(result_nodes), operator, ExpressionText(operand_nodes[i+1]))
+		}
+		if folded == nil {
+			return nil, nil
+		}
+
+		result = folded
+		result_nodes = append(result_nodes, &TreeNode{Value: operator})
+		result_nodes = append(result_nodes, operand_nodes[i+1]...)
+	}
+
+	return result, nil
+}
+
+// Name: FoldNode
+//
+// Parameters: *TreeNode, *SymbolTable, GrammarRules, []TypeRule
+//
+// Return: *ConstantValue, error
+//
+// Computes the value of a single node of an expression. Terms holding a literal have the value of the literal,
+// and other nodes the value of the expression made up by their children
+func FoldNode(node *TreeNode, symbol_table *SymbolTable, rules GrammarRules, type_rules []TypeRule) (*ConstantValue, error) {
+
+	if (rules.CallRule != "" && node.Symbol == rules.CallRule) || IsAccessNode(node, rules) {
+		return nil, nil
+	}
+
+	if node.Symbol == rules.TermRule {
+		term_node := node
+		for term_node.Value == "" && len(term_node.Children) == 1 {
+			term_node = term_node.Children[0]
+		}
+
+		if len(term_node.Children) == 0 {
+			if term_node.Symbol == rules.VariableRule {
+				return nil, nil
+			}
+			return ParseConstant(term_node), nil
+		}
+		node = term_node
+	}
+
+	if len(node.Children) == 0 {
+		return nil, nil
+	}
+
+	return FoldConstant(node.Children, symbol_table, rules, type_rules)
+}
+
+// Name: ParseConstant
+//
+// Parameters: *TreeNode
+//
+// Return: *ConstantValue
+//
+// Reads the value of a literal leaf, which is an integer or a real number, or nil for any other literal
+func ParseConstant(leaf *TreeNode) *ConstantValue {
+
+	if value, err := strconv.ParseInt(leaf.Value, 10, 64); err == nil {
+		return &ConstantValue{Value: float64(value), Integral: true, Type: leaf.Symbol}
+	}
+
+	if value, err := strconv.ParseFloat(leaf.Value, 64); err == nil {
+		return &ConstantValue{Value: value, Type: leaf.Symbol}
+	}
+
+	return nil
+}
+
+// Name: ApplyConstantOperator
+//
+// Parameters: string, *ConstantValue, *ConstantValue, []TypeRule, []Promotion
+//
+// Return: *ConstantValue, error
+//
+// Applies an arithmetic operator to two constants. Returns nil for other operators and for operand types no
+// type rule accepts, which the type check reports, and an error for division or modulo by zero
+func ApplyConstantOperator(operator string, lhs *ConstantValue, rhs *ConstantValue, type_rules []TypeRule, promotions []Promotion) (*ConstantValue, error) {
+
+	result_types := OperationTypes(operator, []string{lhs.Type}, []string{rhs.Type}, type_rules, promotions)
+	if len(result_types) == 0 {
+		return nil, nil
+	}
+
+	result_type := result_types[0]
+	integral := lhs.Integral && rhs.Integral && (IsIntegralType(result_type, lhs.Type, type_rules) || IsIntegralType(result_type, rhs.Type, type_rules))
+
+	value := 0.0
+
+	switch operator {
+	case "+":
+		value = lhs.Value + rhs.Value
+	case "-":
+		value = lhs.Value - rhs.Value
+	case "*":
+		value = lhs.Value * rhs.Value
+	case "/":
+		if rhs.Value == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		value = lhs.Value / rhs.Value
+		if integral {
+			value = math.Trunc(value)
+		}
+	case "%":
+		if rhs.Value == 0 {
+			return nil, fmt.Errorf("modulo by zero")
+		}
+		value = math.Mod(lhs.Value, rhs.Value)
+	default:
+		return nil, nil
+	}
+
+	return &ConstantValue{Value: value, Integral: integral, Type: result_type}, nil
+}
+
+// Name: IsIntegralType
+//
+// Parameters: string, string, []TypeRule
+//
+// Return: bool
+//
+// Determines if a result type keeps the integer values of an integer operand type, which is when it is the
+// operand type or a type rule without an operator converts the operand type to it. Promotions, such as int
+// to float, do not keep integer values
+func IsIntegralType(result_type string, operand_type string, type_rules []TypeRule) bool {
+
+	if result_type == operand_type {
+		return true
+	}
+
+	for _, rule := range type_rules {
+		if len(rule.Operator) == 0 && rule.LHSData == operand_type && rule.ResultData == result_type {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Name: FormatConstant
+//
+// Parameters: *ConstantValue
+//
+// Return: string
+//
+// Writes out the value of a constant, as a whole number when it is integral
+func FormatConstant(constant *ConstantValue) string {
+
+	if constant.Integral {
+		return strconv.FormatInt(int64(constant.Value), 10)
+	}
+
+	value := strconv.FormatFloat(constant.Value, 'f', -1, 64)
+	if !strings.Contains(value, ".") {
+		value += ".0"
+	}
+
+	return value
+}
@@ -0,0 +1,292 @@
+package services
+
+// Struct for the result of the CYK parser
+//
+// table[length-1][start] holds the variables deriving the tokens from start with the given length
+type CYKResult struct {
+	Accepted bool         `json:"accepted"`
+	Table    [][][]string `json:"table"`
+	Grammar  Grammar      `json:"grammar"`
+	Tree     SyntaxTree   `json:"tree"`
+}
+
+// Struct for how a variable was derived in a cell of the CYK table
+type cykEntry struct {
+	rule  *cnfRule
+	split int
+}
+
+// Struct for a node of the tree over the grammar in Chomsky normal form
+type cykNode struct {
+	rule     *cnfRule
+	token    *TypeValue
+	children []*cykNode
+}
+
+// Name: CreateCYKSyntaxTree
+//
+// Parameters: []TypeValue, Grammar
+//
+// Return: CYKResult, error
+//
+// Parse the tokens with the CYK algorithm over the Chomsky normal form of the grammar. Returns the
+// filled table and, when the tokens are accepted, the syntax tree using the symbols of the original grammar
+func CreateCYKSyntaxTree(tokens []TypeValue, grammar Grammar) (CYKResult, error) {
+
+	err := ValidateTokens(tokens, grammar)
+	if err != nil {
+		return CYKResult{}, err
+	}
+
+	cnf, _, err := convertToCNF(grammar)
+	if err != nil {
+		return CYKResult{}, err
+	}
+
+	count := len(tokens)
+	table := make([][][]string, count)
+	entries := make([][]map[string]cykEntry, count)
+
+	for length := 1; length <= count; length++ {
+		table[length-1] = make([][]string, count-length+1)
+		entries[length-1] = make([]map[string]cykEntry, count-length+1)
+
+		for start := 0; start+length <= count; start++ {
+			cell := make(map[string]cykEntry)
+			variables := []string{}
+
+			for _, rule := range cnf.rules {
+				if _, exists := cell[rule.rule.Input]; exists {
+					continue
+				}
+
+				output := rule.rule.Output
+
+				if length == 1 && len(output) == 1 && MatchesTerminal(output[0], tokens[start]) {
+					cell[rule.rule.Input] = cykEntry{rule: rule}
+					variables = append(variables, rule.rule.Input)
+					continue
+				}
+
+				if length == 1 || len(output) != 2 {
+					continue
+				}
+
+				for split := 1; split < length; split++ {
+					_, left := entries[split-1][start][output[0]]
+					_, right := entries[length-split-1][start+split][output[1]]
+
+					if left && right {
+						cell[rule.rule.Input] = cykEntry{rule: rule, split: split}
+						variables = append(variables, rule.rule.Input)
+						break
+					}
+				}
+			}
+
+			table[length-1][start] = variables
+			entries[length-1][start] = cell
+		}
+	}
+
+	result := CYKResult{
+		Table:   table,
+		Grammar: cnf.snapshot(),
+	}
+
+	if _, accepted := entries[count-1][0][cnf.start]; !accepted {
+		return result, nil
+	}
+
+	root := BuildCYKNode(entries, tokens, cnf.start, count, 0)
+
+	result.Accepted = true
+	result.Tree = SyntaxTree{Root: cnf.rebuild(root)}
+
+	return result, nil
+}
+
+// Name: BuildCYKNode
+//
+// Parameters: [][]map[string]cykEntry, []TypeValue, string, int, int
+//
+// Return: *cykNode
+//
+// Recursively follows the entries of the CYK table to build the tree of the variable over the given span
+func BuildCYKNode(entries [][]map[string]cykEntry, tokens []TypeValue, variable string, length int, start int) *cykNode {
+
+	entry := entries[length-1][start][variable]
+	node := &cykNode{rule: entry.rule}
+
+	if entry.split == 0 {
+		node.children = []*cykNode{{token: &tokens[start]}}
+		return node
+	}
+
+	node.children = []*cykNode{
+		BuildCYKNode(entries, tokens, entry.rule.rule.Output[0], entry.split, start),
+		BuildCYKNode(entries, tokens, entry.rule.rule.Output[1], length-entry.split, start+entry.split),
+	}
+
+	return node
+}
+
+// Name: rebuild
+//
+// Parameters: *cykNode
+//
+// Return: *TreeNode
+//
+// Maps a node of the tree over the grammar in Chomsky normal form back onto the original grammar
+func (c *cnfGrammar) rebuild(node *cykNode) *TreeNode {
+
+	children := c.flatten(node.children)
+
+	if node.rule.source == nil {
+		return &TreeNode{Symbol: node.rule.rule.Input, Children: children}
+	}
+
+	return c.expand(node.rule.chain, node.rule, children)
+}
+
+// Name: flatten
+//
+// Parameters: []*cykNode
+//
+// Return: []*TreeNode
+//
+// Maps the children of a node back onto the original grammar, replacing helper variables by their children
+func (c *cnfGrammar) flatten(nodes []*cykNode) []*TreeNode {
+
+	children := []*TreeNode{}
+
+	for _, node := range nodes {
+		if node.token != nil {
+			children = append(children, &TreeNode{Symbol: node.token.Type, Value: node.token.Value, Children: nil})
+		} else if c.helpers[node.rule.rule.Input] {
+			children = append(children, c.flatten(node.children)...)
+		} else {
+			children = append(children, c.rebuild(node))
+		}
+	}
+
+	return children
+}
+
+// Name: expand
+//
+// Parameters: []*cnfRule, *cnfRule, []*TreeNode
+//
+// Return: *TreeNode
+//
+// Restores the removed unit rules in front of the rule, then the node of the original rule
+func (c *cnfGrammar) expand(chain []*cnfRule, rule *cnfRule, children []*TreeNode) *TreeNode {
+
+	if len(chain) == 0 {
+		return c.restore(rule, children)
+	}
+
+	if chain[0].source == nil {
+		return c.expand(chain[1:], rule, children)
+	}
+
+	return c.restore(chain[0], []*TreeNode{c.expand(chain[1:], rule, children)})
+}
+
+// Name: restore
+//
+// Parameters: *cnfRule, []*TreeNode
+//
+// Return: *TreeNode
+//
+// Builds the node of the original rule, placing the children at the kept positions and the empty
+// derivation of each removed nullable variable at the others
+func (c *cnfGrammar) restore(rule *cnfRule, children []*TreeNode) *TreeNode {
+
+	node := &TreeNode{Symbol: rule.source.Input, Children: make([]*TreeNode, 0)}
+	next := 0
+
+	for position, symbol := range rule.source.Output {
+		if symbol == "ε" {
+			continue
+		}
+
+		if next < len(rule.kept) && rule.kept[next] == position && next < len(children) {
+			node.Children = append(node.Children, children[next])
+			next++
+		} else {
+			node.Children = append(node.Children, c.emptyTree(symbol))
+		}
+	}
+
+	return node
+}
+
+// Name: emptyTree
+//
+// Parameters: string
+//
+// Return: *TreeNode
+//
+// Builds the shortest tree of the original grammar in which the variable derives the empty string
+func (c *cnfGrammar) emptyTree(variable string) *TreeNode {
+
+	heights := make(map[string]int)
+	chosen := make(map[string]ParsingRule)
+
+	for changed := true; changed; {
+		changed = false
+
+		for _, rule := range c.original.Rules {
+			height := 1
+			empty := true
+
+			for _, symbol := range rule.Output {
+				if symbol == "ε" {
+					continue
+				}
+				symbol_height, nullable := heights[symbol]
+				if !nullable {
+					empty = false
+					break
+				}
+				if symbol_height+1 > height {
+					height = symbol_height + 1
+				}
+			}
+
+			if current, exists := heights[rule.Input]; empty && (!exists || height < current) {
+				heights[rule.Input] = height
+				chosen[rule.Input] = rule
+				changed = true
+			}
+		}
+	}
+
+	return BuildEmptyNode(variable, chosen)
+}
+
+// Name: BuildEmptyNode
+//
+// Parameters: string, map[string]ParsingRule
+//
+// Return: *TreeNode
+//
+// Recursively builds the node of a variable using the chosen ε deriving rule of each variable
+func BuildEmptyNode(variable string, chosen map[string]ParsingRule) *TreeNode {
+
+	node := &TreeNode{Symbol: variable, Children: make([]*TreeNode, 0)}
+
+	rule, exists := chosen[variable]
+	if !exists {
+		return node
+	}
+
+	for _, symbol := range rule.Output {
+		if symbol != "ε" {
+			node.Children = append(node.Children, BuildEmptyNode(symbol, chosen))
+		}
+	}
+
+	return node
+}
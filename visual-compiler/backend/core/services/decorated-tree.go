@@ -0,0 +1,108 @@
+package services
+
+// struct to store a syntax tree decorated by the analyser
+type DecoratedTree struct {
+	Root *DecoratedNode `json:"root"`
+}
+
+// struct to store a node of the decorated tree.
+//
+// type is the computed type of the node, empty when it has none, scope id the scope of the symbol table artefact
+// the node is in and declaration the pre-order number of the node declaring the symbol the node refers to, or -1
+type DecoratedNode struct {
+	Symbol      string           `json:"symbol"`
+	Value       string           `json:"value"`
+	Type        string           `json:"type"`
+	ScopeID     int              `json:"scope_id"`
+	Declaration int              `json:"declaration"`
+	Children    []*DecoratedNode `json:"children"`
+}
+
+// Name: CreateDecoratedTree
+//
+// Parameters: *TreeNode
+//
+// Return: *DecoratedNode, map[*TreeNode]*DecoratedNode
+//
+// Copies the tree into undecorated nodes and returns the root with the decorated node of each tree node.
+// The scope and declaration of every node start as -1
+func CreateDecoratedTree(root *TreeNode) (*DecoratedNode, map[*TreeNode]*DecoratedNode) {
+
+	decorated := make(map[*TreeNode]*DecoratedNode)
+
+	if root == nil {
+		return nil, decorated
+	}
+
+	return CopyDecoratedNode(root, decorated), decorated
+}
+
+// Name: CopyDecoratedNode
+//
+// Parameters: *TreeNode, map[*TreeNode]*DecoratedNode
+//
+// Return: *DecoratedNode
+//
+// Recursively copies the node and its children into undecorated nodes, recording the copy of each node
+func CopyDecoratedNode(node *TreeNode, decorated map[*TreeNode]*DecoratedNode) *DecoratedNode {
+
+	decorated_node := &DecoratedNode{
+		Symbol:      node.Symbol,
+		Value:       node.Value,
+		ScopeID:     -1,
+		Declaration: -1,
+		Children:    []*DecoratedNode{},
+	}
+	decorated[node] = decorated_node
+
+	for _, child := range node.Children {
+		if child != nil {
+			decorated_node.Children = append(decorated_node.Children, CopyDecoratedNode(child, decorated))
+		}
+	}
+
+	return decorated_node
+}
+
+// Name: DecorateNode
+//
+// Parameters: *SymbolTable, *TreeNode, string, int
+//
+// Return: none
+//
+// Records the type of a node and the declaration of the symbol it refers to. A declaration of -1 leaves the
+// declaration of the node unchanged
+func DecorateNode(symbol_table *SymbolTable, node *TreeNode, node_type string, declaration int) {
+
+	decorated_node, found := symbol_table.Decorated[node]
+	if !found {
+		return
+	}
+
+	decorated_node.Type = node_type
+	if declaration >= 0 {
+		decorated_node.Declaration = declaration
+	}
+}
+
+// Name: FillDecoratedScopes
+//
+// Parameters: *DecoratedNode, int
+//
+// Return: none
+//
+// Gives every node the analyser did not visit, such as the nodes of a function declaration, the scope of its parent
+func FillDecoratedScopes(node *DecoratedNode, parent_scope int) {
+
+	if node == nil {
+		return
+	}
+
+	if node.ScopeID < 0 {
+		node.ScopeID = parent_scope
+	}
+
+	for _, child := range node.Children {
+		FillDecoratedScopes(child, node.ScopeID)
+	}
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Struct for one step of a derivation
+type DerivationStep struct {
+	Step int    `json:"step"`
+	Rule string `json:"rule"`
+	Form string `json:"form"`
+}
+
+// Name: CreateDerivation
+//
+// Parameters: SyntaxTree, Grammar
+//
+// Return: []DerivationStep, error
+//
+// Recreate the leftmost derivation of the syntax tree as the sequence of sentential forms,
+// starting at the start variable and ending at the sequence of terminals
+func CreateDerivation(tree SyntaxTree, grammar Grammar) ([]DerivationStep, error) {
+
+	if tree.Root == nil {
+		return nil, fmt.Errorf("syntax tree is empty")
+	}
+
+	variables := make(map[string]bool)
+	for _, variable := range grammar.Variables {
+		variables[variable] = true
+	}
+
+	form := []*TreeNode{tree.Root}
+
+	derivation := []DerivationStep{
+		{Step: 0, Rule: "", Form: FormatSententialForm(form)},
+	}
+
+	for {
+		leftmost := -1
+
+		for i, node := range form {
+			if variables[node.Symbol] || len(node.Children) > 0 {
+				leftmost = i
+				break
+			}
+		}
+
+		if leftmost < 0 {
+			break
+		}
+
+		expanded := form[leftmost]
+
+		rule := ParsingRule{Input: expanded.Symbol}
+		for _, child := range expanded.Children {
+			rule.Output = append(rule.Output, child.Symbol)
+		}
+
+		next_form := append([]*TreeNode{}, form[:leftmost]...)
+		next_form = append(next_form, expanded.Children...)
+		next_form = append(next_form, form[leftmost+1:]...)
+		form = next_form
+
+		derivation = append(derivation, DerivationStep{
+			Step: len(derivation),
+			Rule: FormatParsingRule(rule),
+			Form: FormatSententialForm(form),
+		})
+	}
+
+	return derivation, nil
+}
+
+// Name: FormatSententialForm
+//
+// Parameters: []*TreeNode
+//
+// Return: string
+//
+// Returns the symbols of a sentential form separated by spaces, or ε for the empty form
+func FormatSententialForm(form []*TreeNode) string {
+
+	if len(form) == 0 {
+		return "ε"
+	}
+
+	symbols := []string{}
+	for _, node := range form {
+		symbols = append(symbols, node.Symbol)
+	}
+
+	return strings.Join(symbols, " ")
+}
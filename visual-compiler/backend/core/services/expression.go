@@ -0,0 +1,300 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+)
+
+// struct to store an implicit conversion of a value from one type to another, such as int to float.
+//
+// promotions chain, so int to float and float to double also promote int to double
+type Promotion struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Name: IsPromotable
+//
+// Parameters: string, string, []Promotion
+//
+// Return: bool
+//
+// Determines if a value of the first type can be implicitly converted to the second type by one or more promotions
+func IsPromotable(from_type string, to_type string, promotions []Promotion) bool {
+
+	visited := map[string]bool{from_type: true}
+	queue := []string{from_type}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, promotion := range promotions {
+			if promotion.From != current || visited[promotion.To] {
+				continue
+			}
+			if promotion.To == to_type {
+				return true
+			}
+			visited[promotion.To] = true
+			queue = append(queue, promotion.To)
+		}
+	}
+
+	return false
+}
+
+// Name: OperationTypes
+//
+// Parameters: string, []string, []string, []TypeRule, []Promotion
+//
+// Return: []string
+//
+// Returns the possible result types of applying the operator to operands of the given types, using each
+// type rule with the operator as a signature. Rules matching the operand types exactly are preferred over
+// rules that need an operand to be converted. An unknown operand type, which is empty, gives an unknown result
+func OperationTypes(operator string, lhs_types []string, rhs_types []string, type_rules []TypeRule, promotions []Promotion) []string {
+
+	if ContainsSymbol(lhs_types, "") || ContainsSymbol(rhs_types, "") {
+		return []string{""}
+	}
+
+	exact := []string{}
+	converted := []string{}
+
+	for _, rule := range type_rules {
+		if !ContainsSymbol(rule.Operator, operator) {
+			continue
+		}
+
+		for _, lhs_type := range lhs_types {
+			for _, rhs_type := range rhs_types {
+				if lhs_type == rule.LHSData && rhs_type == rule.RHSData {
+					exact = appendType(exact, rule.ResultData)
+				} else if IsAssignable(rule.LHSData, lhs_type, type_rules, promotions) && IsAssignable(rule.RHSData, rhs_type, type_rules, promotions) {
+					converted = appendType(converted, rule.ResultData)
+				}
+			}
+		}
+	}
+
+	if len(exact) > 0 {
+		return exact
+	}
+
+	return converted
+}
+
+// Name: appendType
+//
+// Parameters: []string, string
+//
+// Return: []string
+//
+// Adds the type to the list when it is not in it yet
+func appendType(types []string, new_type string) []string {
+
+	if ContainsSymbol(types, new_type) {
+		return types
+	}
+
+	return append(types, new_type)
+}
+
+// Name: ExpressionTypes
+//
+// Parameters: []*TreeNode, *SymbolTable, GrammarRules, []TypeRule
+//
+// Return: []string, error
+//
+// Computes the possible types of the expression made up by the nodes, bottom-up. Terms take the type of their
+// declared name or of their token, calls the type of the called function and other nodes the type of the
+// expression made up by their children. Operators are applied from left to right. Returns no types when the
+// nodes hold no terms, an unknown type when the terms and operators do not alternate and an error naming the
+// sub-expression and its operand types when no type rule applies
+func ExpressionTypes(nodes []*TreeNode, symbol_table *SymbolTable, rules GrammarRules, type_rules []TypeRule) ([]string, error) {
+
+	operands := [][]string{}
+	operand_nodes := [][]*TreeNode{}
+	operators := []string{}
+
+	for _, node := range nodes {
+		if node.Symbol == rules.OperatorRule {
+			operators = append(operators, node.Value)
+			continue
+		}
+
+		types, err := NodeTypes(node, symbol_table, rules, type_rules)
+		if err != nil {
+			return nil, err
+		}
+		if types != nil {
+			operands = append(operands, types)
+			operand_nodes = append(operand_nodes, []*TreeNode{node})
+		}
+	}
+
+	if len(operands) == 0 {
+		return nil, nil
+	}
+	// the type rules only describe binary operators, so other forms such as calls without a call rule are not typed
+	if len(operators) != len(operands)-1 {
+		return []string{""}, nil
+	}
+
+	result := operands[0]
+	result_nodes := operand_nodes[0]
+
+	for i, operator := range operators {
+		operation := OperationTypes(operator, result, operands[i+1], type_rules, symbol_table.Promotions)
+
+		if len(operation) == 0 {
+			return nil, fmt.Errorf("error: invalid operand types in %v %v %v: %v %v %v", ExpressionText(result_nodes), operator, ExpressionText(operand_nodes[i+1]), strings.Join(result, "|"), operator, strings.Join(operands[i+1], "|"))
+		}
+
+		result = operation
+		result_nodes = append(result_nodes, &TreeNode{Value: operator})
+		result_nodes = append(result_nodes, operand_nodes[i+1]...)
+	}
+
+	return result, nil
+}
+
+// Name: NodeTypes
+//
+// Parameters: *TreeNode, *SymbolTable, GrammarRules, []TypeRule
+//
+// Return: []string, error
+//
+// Computes the possible types of a single node of an expression. Names that are not declared have an unknown type,
+// which is empty, and leaves that are not terms, such as brackets, have no type
+func NodeTypes(node *TreeNode, symbol_table *SymbolTable, rules GrammarRules, type_rules []TypeRule) ([]string, error) {
+
+	types, err := FindNodeTypes(node, symbol_table, rules, type_rules)
+
+	if len(types) > 0 {
+		DecorateNode(symbol_table, node, strings.Join(types, "|"), -1)
+	}
+
+	return types, err
+}
+
+// Name: FindNodeTypes
+//
+// Parameters: *TreeNode, *SymbolTable, GrammarRules, []TypeRule
+//
+// Return: []string, error
+//
+// Computes the possible types of a single node of an expression, without decorating it
+func FindNodeTypes(node *TreeNode, symbol_table *SymbolTable, rules GrammarRules, type_rules []TypeRule) ([]string, error) {
+
+	if rules.CallRule != "" && node.Symbol == rules.CallRule {
+		for _, child := range node.Children {
+			if child.Symbol == rules.VariableRule {
+				return []string{CallResultType(child, symbol_table, rules.VariableRule)}, nil
+			}
+		}
+		return []string{""}, nil
+	}
+
+	// invalid accesses are reported when the access node is analysed
+	if IsAccessNode(node, rules) {
+		access_type, err := AccessType(node, symbol_table, rules, type_rules)
+		if err != nil || access_type == nil {
+			return []string{""}, nil
+		}
+		return []string{TypeKey(access_type, symbol_table.StructuralTypes)}, nil
+	}
+
+	if node.Symbol == rules.TermRule {
+		term_node := node
+		for term_node.Value == "" && len(term_node.Children) == 1 {
+			term_node = term_node.Children[0]
+		}
+
+		if len(term_node.Children) == 0 && term_node.Symbol != rules.VariableRule {
+			if term_node != node {
+				DecorateNode(symbol_table, term_node, term_node.Symbol, -1)
+			}
+			return []string{term_node.Symbol}, nil
+		}
+		if term_node != node {
+			return NodeTypes(term_node, symbol_table, rules, type_rules)
+		}
+	}
+
+	if len(node.Children) == 0 {
+		if node.Symbol == rules.VariableRule {
+			return []string{NameType(node.Value, symbol_table)}, nil
+		}
+		return nil, nil
+	}
+
+	return ExpressionTypes(node.Children, symbol_table, rules, type_rules)
+}
+
+// Name: NameType
+//
+// Parameters: string, *SymbolTable
+//
+// Return: string
+//
+// Returns the type of a declared name, or an empty type when the name is not declared. A function used as a value
+// has its function type
+func NameType(name string, symbol_table *SymbolTable) string {
+
+	symbol, err := LookupName(symbol_table, name)
+	if err != nil {
+		return ""
+	}
+
+	if symbol.IsFunction && symbol.TypeInfo != nil {
+		return TypeKey(symbol.TypeInfo, symbol_table.StructuralTypes)
+	}
+
+	return symbol.Type
+}
+
+// Name: CallResultType
+//
+// Parameters: *TreeNode, *SymbolTable, string
+//
+// Return: string
+//
+// Returns the type of the value returned by calling the named function, or the value of a function type
+func CallResultType(name_node *TreeNode, symbol_table *SymbolTable, variable_rule string) string {
+
+	symbol, err := LookupName(symbol_table, LeafValue(name_node))
+	if err == nil && !symbol.IsFunction && symbol.TypeInfo != nil && symbol.TypeInfo.Kind == type_function {
+		return TypeKey(symbol.TypeInfo.Result, symbol_table.StructuralTypes)
+	}
+
+	return ArgumentType(name_node, symbol_table, variable_rule)
+}
+
+// Name: ExpressionText
+//
+// Parameters: []*TreeNode
+//
+// Return: string
+//
+// Returns the source text of the nodes, which is the values of their leaves separated by spaces
+func ExpressionText(nodes []*TreeNode) string {
+
+	values := []string{}
+
+	for _, node := range nodes {
+		if len(node.Children) == 0 {
+			if node.Value != "" {
+				values = append(values, node.Value)
+			}
+			continue
+		}
+
+		if text := ExpressionText(node.Children); text != "" {
+			values = append(values, text)
+		}
+	}
+
+	return strings.Join(values, " ")
+}
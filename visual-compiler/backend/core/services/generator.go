@@ -0,0 +1,512 @@
+package services
+
+import (
+	"fmt"
+	"math/rand"
+	"regexp/syntax"
+	"strings"
+	"time"
+)
+
+// Struct for the options of the sentence generator
+type GeneratorOptions struct {
+	Samples  int   `json:"samples"`
+	MaxDepth int   `json:"max_depth"`
+	Seed     int64 `json:"seed"`
+}
+
+// Struct for one generated sentence
+type GeneratedSample struct {
+	Tokens []TypeValue `json:"tokens"`
+	Tree   SyntaxTree  `json:"tree"`
+	Source string      `json:"source"`
+}
+
+// Struct for the generated sentences and the rules they did not use
+type GeneratorResult struct {
+	Samples   []GeneratedSample `json:"samples"`
+	Uncovered []string          `json:"uncovered"`
+}
+
+// Struct to track the sentence generator
+type Generator struct {
+	Grammar   Grammar
+	Rules     []TypeRegex
+	MaxDepth  int
+	Random    *rand.Rand
+	Heights   map[string]int
+	Used      map[int]bool
+	Variables map[string]bool
+}
+
+// Default options of the sentence generator
+const (
+	default_samples   = 5
+	default_gen_depth = 10
+	max_repeat        = 3
+	max_attempts      = 10
+	literal_type      = "LITERAL"
+)
+
+// Name: GenerateSentences
+//
+// Parameters: Grammar, GeneratorOptions, []TypeRegex
+//
+// Return: GeneratorResult, error
+//
+// Generate random sentences of the grammar with their syntax trees. Rules that have not been used yet
+// are preferred so every rule is covered, and past the maximum depth the shortest rules are chosen so
+// each derivation ends. When regex rules are given, each token receives a value matching its rule
+func GenerateSentences(grammar Grammar, options GeneratorOptions, rules []TypeRegex) (GeneratorResult, error) {
+
+	if grammar.Start == "" {
+		return GeneratorResult{}, fmt.Errorf("no start variable found")
+	}
+
+	if options.Samples <= 0 {
+		options.Samples = default_samples
+	}
+	if options.MaxDepth <= 0 {
+		options.MaxDepth = default_gen_depth
+	}
+	if options.Seed == 0 {
+		options.Seed = time.Now().UnixNano()
+	}
+
+	generator := &Generator{
+		Grammar:   grammar,
+		Rules:     rules,
+		MaxDepth:  options.MaxDepth,
+		Random:    rand.New(rand.NewSource(options.Seed)),
+		Heights:   MinimumHeight(grammar.Rules),
+		Used:      make(map[int]bool),
+		Variables: make(map[string]bool),
+	}
+
+	for _, rule := range grammar.Rules {
+		generator.Variables[rule.Input] = true
+	}
+
+	if _, productive := generator.Heights[grammar.Start]; !productive {
+		return GeneratorResult{}, fmt.Errorf("start variable does not derive any sentence")
+	}
+
+	result := GeneratorResult{Samples: []GeneratedSample{}, Uncovered: []string{}}
+
+	for i := 0; i < options.Samples; i++ {
+
+		sample := GeneratedSample{Tokens: []TypeValue{}}
+		sample.Tree = SyntaxTree{Root: generator.GenerateSymbol(grammar.Start, 1, &sample.Tokens)}
+
+		if len(rules) > 0 {
+			values := []string{}
+			for _, token := range sample.Tokens {
+				values = append(values, token.Value)
+			}
+			sample.Source = strings.Join(values, " ")
+		}
+
+		result.Samples = append(result.Samples, sample)
+	}
+
+	for index, rule := range grammar.Rules {
+		if !generator.Used[index] {
+			result.Uncovered = append(result.Uncovered, FormatParsingRule(rule))
+		}
+	}
+
+	return result, nil
+}
+
+// Name: MinimumHeight
+//
+// Parameters: []ParsingRule
+//
+// Return: map[string]int
+//
+// Returns the height of the shortest tree each productive symbol derives. Terminals have a height of zero
+// and unproductive variables are left out
+func MinimumHeight(rules []ParsingRule) map[string]int {
+
+	heights := make(map[string]int)
+	has_rules := make(map[string]bool)
+
+	for _, rule := range rules {
+		has_rules[rule.Input] = true
+	}
+
+	for _, rule := range rules {
+		for _, symbol := range rule.Output {
+			if !has_rules[symbol] {
+				heights[symbol] = 0
+			}
+		}
+	}
+
+	for changed := true; changed; {
+		changed = false
+
+		for _, rule := range rules {
+			height, productive := RuleHeight(rule, heights)
+
+			if !productive {
+				continue
+			}
+
+			if current, exists := heights[rule.Input]; !exists || height < current {
+				heights[rule.Input] = height
+				changed = true
+			}
+		}
+	}
+
+	return heights
+}
+
+// Name: RuleHeight
+//
+// Parameters: ParsingRule, map[string]int
+//
+// Return: int, bool
+//
+// Returns the height of the shortest tree built with the rule, and whether the rule derives a sentence
+func RuleHeight(rule ParsingRule, heights map[string]int) (int, bool) {
+
+	height := 1
+
+	for _, symbol := range rule.Output {
+		symbol_height, exists := heights[symbol]
+		if !exists {
+			return 0, false
+		}
+		if symbol_height+1 > height {
+			height = symbol_height + 1
+		}
+	}
+
+	return height, true
+}
+
+// Name: GenerateSymbol
+//
+// Parameters: string, int, *[]TypeValue
+//
+// Return: *TreeNode
+//
+// Recursively generates the subtree of a symbol at the given depth, adding its terminals to the tokens
+func (g *Generator) GenerateSymbol(symbol string, depth int, tokens *[]TypeValue) *TreeNode {
+
+	if !g.Variables[symbol] {
+		token := TypeValue{Type: symbol, Value: g.GenerateValue(symbol)}
+		if IsLiteral(symbol) {
+			token = g.GenerateLiteral(symbol)
+		}
+		*tokens = append(*tokens, token)
+
+		return &TreeNode{Symbol: token.Type, Value: token.Value, Children: nil}
+	}
+
+	if !ContainsSymbol(g.Grammar.Expressions, symbol) || len(g.Grammar.Operators) == 0 {
+		return g.GenerateRule(symbol, depth, tokens)
+	}
+
+	operands := []*TreeNode{g.GenerateRule(symbol, depth, tokens)}
+	operators := []TypeValue{}
+	var previous *Operator
+
+	for len(operands) < max_repeat && depth < g.MaxDepth && g.Random.Intn(2) == 0 {
+		operator := g.Grammar.Operators[g.Random.Intn(len(g.Grammar.Operators))]
+
+		// a non-associative operator cannot follow another operator of the same level
+		if previous != nil && previous.Precedence == operator.Precedence && (operator.Associativity == "nonassoc" || previous.Associativity == "nonassoc") {
+			break
+		}
+		previous = &operator
+
+		value := operator.Value
+		if value == "" {
+			value = g.GenerateValue(operator.Terminal)
+		}
+
+		token := TypeValue{Type: operator.Terminal, Value: value}
+		*tokens = append(*tokens, token)
+		operators = append(operators, token)
+
+		operands = append(operands, g.GenerateRule(symbol, depth+1, tokens))
+	}
+
+	next := 0
+
+	return FoldExpression(symbol, g.Grammar, operands, operators, &next, MinimumPrecedence(g.Grammar))
+}
+
+// Name: GenerateRule
+//
+// Parameters: string, int, *[]TypeValue
+//
+// Return: *TreeNode
+//
+// Chooses a rule for the variable and generates the subtree of each symbol in its output
+func (g *Generator) GenerateRule(variable string, depth int, tokens *[]TypeValue) *TreeNode {
+
+	index := g.ChooseRule(variable, depth)
+	g.Used[index] = true
+
+	rule := g.Grammar.Rules[index]
+	node := &TreeNode{Symbol: variable, Value: "", Children: make([]*TreeNode, 0)}
+
+	for _, symbol := range rule.Output {
+		if symbol == "ε" {
+			continue
+		}
+		node.Children = append(node.Children, g.GenerateSymbol(symbol, depth+1, tokens))
+	}
+
+	return node
+}
+
+// Name: ChooseRule
+//
+// Parameters: string, int
+//
+// Return: int
+//
+// Chooses the index of the next rule for the variable. Unused rules that fit within the maximum depth
+// are preferred, then any rule that fits, and otherwise one of the shortest rules
+func (g *Generator) ChooseRule(variable string, depth int) int {
+
+	unused := []int{}
+	fitting := []int{}
+	shortest := []int{}
+	shortest_height := -1
+
+	for index, rule := range g.Grammar.Rules {
+		if rule.Input != variable {
+			continue
+		}
+
+		height, productive := RuleHeight(rule, g.Heights)
+		if !productive {
+			continue
+		}
+
+		if depth+height-1 <= g.MaxDepth {
+			fitting = append(fitting, index)
+			if !g.Used[index] {
+				unused = append(unused, index)
+			}
+		}
+
+		if shortest_height < 0 || height < shortest_height {
+			shortest = []int{index}
+			shortest_height = height
+		} else if height == shortest_height {
+			shortest = append(shortest, index)
+		}
+	}
+
+	if len(unused) > 0 {
+		return unused[g.Random.Intn(len(unused))]
+	}
+	if len(fitting) > 0 {
+		return fitting[g.Random.Intn(len(fitting))]
+	}
+
+	return shortest[g.Random.Intn(len(shortest))]
+}
+
+// Name: FoldExpression
+//
+// Parameters: string, Grammar, []*TreeNode, []TypeValue, *int, int
+//
+// Return: *TreeNode
+//
+// Joins the operands and operators of a generated expression by precedence climbing, giving the same
+// tree the parser builds for the expression
+func FoldExpression(variable string, grammar Grammar, operands []*TreeNode, operators []TypeValue, next *int, minimum int) *TreeNode {
+
+	left := operands[*next]
+
+	for *next < len(operators) {
+		token := operators[*next]
+		operator, _ := MatchOperator(grammar, token)
+
+		if operator.Precedence < minimum {
+			break
+		}
+
+		next_minimum := operator.Precedence + 1
+		if operator.Associativity == "right" {
+			next_minimum = operator.Precedence
+		}
+
+		*next++
+		right := FoldExpression(variable, grammar, operands, operators, next, next_minimum)
+
+		left = &TreeNode{
+			Symbol: variable,
+			Value:  "",
+			Children: []*TreeNode{
+				left,
+				{Symbol: token.Type, Value: token.Value, Children: nil},
+				right,
+			},
+		}
+	}
+
+	return left
+}
+
+// Name: GenerateValue
+//
+// Parameters: string
+//
+// Return: string
+//
+// Generates a value for the terminal from the first regex rule of its type. The value is checked
+// against all the rules so it is read back as the same token. Returns an empty value without a rule
+func (g *Generator) GenerateValue(terminal string) string {
+
+	for _, rule := range g.Rules {
+		if rule.Type != terminal {
+			continue
+		}
+
+		value := ""
+		for attempt := 0; attempt < max_attempts; attempt++ {
+			generated, err := GenerateFromRegex(rule.Regex, g.Random)
+			if err != nil {
+				return ""
+			}
+			value = generated
+
+			tokens, leftovers, err := CreateTokens(value, g.Rules)
+			if err == nil && len(leftovers) == 0 && len(tokens) == 1 && tokens[0].Type == terminal {
+				break
+			}
+		}
+
+		return value
+	}
+
+	return ""
+}
+
+// Name: GenerateLiteral
+//
+// Parameters: string
+//
+// Return: TypeValue
+//
+// Generates the token of a literal terminal. Without a type constraint the token takes the type the
+// regex rules give its value, or LITERAL when no rule reads it as a single token
+func (g *Generator) GenerateLiteral(literal string) TypeValue {
+
+	token_type, value, _ := ReadLiteral(literal)
+
+	if token_type == "" {
+		token_type = literal_type
+
+		tokens, leftovers, err := CreateTokens(value, g.Rules)
+		if err == nil && len(leftovers) == 0 && len(tokens) == 1 {
+			token_type = tokens[0].Type
+		}
+	}
+
+	return TypeValue{Type: token_type, Value: value}
+}
+
+// Name: GenerateFromRegex
+//
+// Parameters: string, *rand.Rand
+//
+// Return: string, error
+//
+// Generates a random string matched by the regular expression
+func GenerateFromRegex(pattern string, random *rand.Rand) (string, error) {
+
+	regex, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", fmt.Errorf("invalid regex: %v", err)
+	}
+
+	var output strings.Builder
+	WriteRegexSample(regex.Simplify(), random, &output)
+
+	return output.String(), nil
+}
+
+// Name: WriteRegexSample
+//
+// Parameters: *syntax.Regexp, *rand.Rand, *strings.Builder
+//
+// Return: none
+//
+// Recursively writes a random match of the parsed regular expression. Repetitions are kept short
+// and character classes prefer printable ASCII characters
+func WriteRegexSample(regex *syntax.Regexp, random *rand.Rand, output *strings.Builder) {
+
+	switch regex.Op {
+	case syntax.OpLiteral:
+		output.WriteString(string(regex.Rune))
+	case syntax.OpCharClass:
+		output.WriteRune(ChooseClassRune(regex.Rune, random))
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		output.WriteRune(rune('a' + random.Intn(26)))
+	case syntax.OpCapture:
+		WriteRegexSample(regex.Sub[0], random, output)
+	case syntax.OpConcat:
+		for _, sub := range regex.Sub {
+			WriteRegexSample(sub, random, output)
+		}
+	case syntax.OpAlternate:
+		WriteRegexSample(regex.Sub[random.Intn(len(regex.Sub))], random, output)
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		minimum, maximum := 0, max_repeat
+		switch regex.Op {
+		case syntax.OpPlus:
+			minimum = 1
+		case syntax.OpQuest:
+			maximum = 1
+		case syntax.OpRepeat:
+			minimum, maximum = regex.Min, regex.Max
+			if maximum < 0 {
+				maximum = minimum + max_repeat
+			}
+		}
+		count := minimum + random.Intn(maximum-minimum+1)
+		for i := 0; i < count; i++ {
+			WriteRegexSample(regex.Sub[0], random, output)
+		}
+	}
+}
+
+// Name: ChooseClassRune
+//
+// Parameters: []rune, *rand.Rand
+//
+// Return: rune
+//
+// Chooses a random character from the ranges of a character class, preferring printable ASCII characters
+func ChooseClassRune(ranges []rune, random *rand.Rand) rune {
+
+	printable := []rune{}
+
+	for i := 0; i+1 < len(ranges); i += 2 {
+		for character := ranges[i]; character <= ranges[i+1] && character <= '~'; character++ {
+			if character >= '!' {
+				printable = append(printable, character)
+			}
+		}
+	}
+
+	if len(printable) > 0 {
+		return printable[random.Intn(len(printable))]
+	}
+
+	if len(ranges) >= 2 {
+		return ranges[0]
+	}
+
+	return 'a'
+}
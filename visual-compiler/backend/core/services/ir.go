@@ -0,0 +1,671 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+)
+
+// struct to store a three-address instruction as a quadruple.
+//
+// arithmetic and comparison instructions store result = arg1 operator arg2 and copies result = arg1.
+// Jumps store their target label in result, and a conditional jump the tested address in arg1
+type Quadruple struct {
+	Operator string `json:"operator"`
+	Arg1     string `json:"arg1,omitempty"`
+	Arg2     string `json:"arg2,omitempty"`
+	Result   string `json:"result,omitempty"`
+}
+
+// struct to store the intermediate representation of a program
+type IRProgram struct {
+	Instructions []Quadruple `json:"instructions"`
+	Temporaries  int         `json:"temporaries"`
+	Labels       int         `json:"labels"`
+}
+
+// struct to store the node kinds the intermediate representation is generated from.
+//
+// variable, assignment, operator and comparison rules are token types, and the other rules nonterminals.
+// A conditional or loop node holds its condition in a node of the condition rule, and the else rule is the
+// token separating the branches of a conditional. The body of a function is the node of the block rule held by
+// the function node, or the node after the function node when the function node only holds its declaration
+type IRRules struct {
+	VariableRule   string
+	AssignmentRule string
+	OperatorRule   string
+	ComparisonRule string
+	TermRule       string
+	ConditionRule  string
+	IfRule         string
+	ElseRule       string
+	WhileRule      string
+	FunctionRule   string
+	BlockRule      string
+	ParameterRule  string
+	CallRule       string
+	ArgumentRule   string
+	ReturnRule     string
+}
+
+// Operators of the instructions that are not arithmetic or comparison operators
+const (
+	ir_copy       = "="
+	ir_label      = "label"
+	ir_goto       = "goto"
+	ir_if_false   = "ifFalse"
+	ir_param      = "param"
+	ir_call       = "call"
+	ir_return     = "return"
+	ir_receive    = "receive"
+	ir_begin_func = "begin_func"
+	ir_end_func   = "end_func"
+)
+
+// Name: GenerateIR
+//
+// Parameters: SyntaxTree, IRRules
+//
+// Return: IRProgram, error
+//
+// Generates the three-address code of the syntax tree. Statements are generated in the order of the tree,
+// expressions are evaluated from left to right into temporaries and conditionals and loops are lowered to
+// labels and jumps
+func GenerateIR(tree SyntaxTree, rules IRRules) (IRProgram, error) {
+
+	program := IRProgram{Instructions: []Quadruple{}}
+
+	if tree.Root == nil {
+		return program, fmt.Errorf("empty syntax tree")
+	}
+
+	if rules.VariableRule == "" {
+		return program, fmt.Errorf("no variable rule entered")
+	}
+
+	if (rules.IfRule != "" || rules.WhileRule != "") && rules.ConditionRule == "" {
+		return program, fmt.Errorf("conditionals and loops need a condition rule")
+	}
+
+	err := GenerateIRStatements([]*TreeNode{tree.Root}, &program, rules)
+	if err != nil {
+		return IRProgram{Instructions: []Quadruple{}}, err
+	}
+
+	return program, nil
+}
+
+// Name: GenerateIRStatements
+//
+// Parameters: []*TreeNode, *IRProgram, IRRules
+//
+// Return: error
+//
+// Generates the instructions of a list of sibling nodes. A function is placed between its begin and end, and
+// each parameter of the function receives an argument, in the order of the parameters
+func GenerateIRStatements(nodes []*TreeNode, program *IRProgram, rules IRRules) error {
+
+	for i := 0; i < len(nodes); i++ {
+
+		node := nodes[i]
+
+		if rules.FunctionRule != "" && node.Symbol == rules.FunctionRule {
+			body := FindIRFunctionBody(node, rules)
+			if body == nil && i+1 < len(nodes) {
+				// the function node only holds the declaration, so its body is the next node
+				i++
+				body = nodes[i]
+			}
+
+			if err := GenerateIRFunction(node, body, program, rules); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := GenerateIRStatement(node, program, rules); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Name: GenerateIRFunction
+//
+// Parameters: *TreeNode, *TreeNode, *IRProgram, IRRules
+//
+// Return: error
+//
+// Generates a function from its function node and body. A function without a body has no instructions between
+// its begin and end
+func GenerateIRFunction(node *TreeNode, body *TreeNode, program *IRProgram, rules IRRules) error {
+
+	name := FindIRName(node, rules)
+	if name == "" {
+		return fmt.Errorf("function has no name")
+	}
+
+	EmitQuadruple(program, Quadruple{Operator: ir_begin_func, Arg1: name})
+	for _, parameter := range FindIRParameters(node, rules) {
+		EmitQuadruple(program, Quadruple{Operator: ir_receive, Arg1: parameter})
+	}
+
+	if body != nil {
+		if err := GenerateIRStatement(body, program, rules); err != nil {
+			return err
+		}
+	}
+
+	EmitQuadruple(program, Quadruple{Operator: ir_end_func, Arg1: name})
+
+	return nil
+}
+
+// Name: FindIRFunctionBody
+//
+// Parameters: *TreeNode, IRRules
+//
+// Return: *TreeNode
+//
+// Finds the child of the function node holding its body, or nil when the function node only holds its declaration
+func FindIRFunctionBody(node *TreeNode, rules IRRules) *TreeNode {
+
+	if rules.BlockRule == "" {
+		return nil
+	}
+
+	for _, child := range node.Children {
+		if child.Symbol == rules.BlockRule {
+			return child
+		}
+	}
+
+	return nil
+}
+
+// Name: GenerateIRStatement
+//
+// Parameters: *TreeNode, *IRProgram, IRRules
+//
+// Return: error
+//
+// Generates the instructions of a node. Conditionals, loops, returns, calls and assignments have their own
+// instructions, and any other node is made up of the instructions of its children
+func GenerateIRStatement(node *TreeNode, program *IRProgram, rules IRRules) error {
+
+	switch {
+	case rules.IfRule != "" && node.Symbol == rules.IfRule:
+		return GenerateIRIf(node, program, rules)
+	case rules.WhileRule != "" && node.Symbol == rules.WhileRule:
+		return GenerateIRWhile(node, program, rules)
+	case rules.ReturnRule != "" && node.Symbol == rules.ReturnRule:
+		value, err := GenerateIRExpression(node.Children, program, rules)
+		if err != nil {
+			return err
+		}
+		EmitQuadruple(program, Quadruple{Operator: ir_return, Arg1: value})
+		return nil
+	case rules.CallRule != "" && node.Symbol == rules.CallRule:
+		_, err := GenerateIRCall(node, program, rules, false)
+		return err
+	}
+
+	for i, child := range node.Children {
+		if child.Symbol == rules.AssignmentRule && len(child.Children) == 0 {
+			return GenerateIRAssignment(node, i, program, rules)
+		}
+	}
+
+	return GenerateIRStatements(node.Children, program, rules)
+}
+
+// Name: GenerateIRAssignment
+//
+// Parameters: *TreeNode, int, *IRProgram, IRRules
+//
+// Return: error
+//
+// Generates the instructions of an assignment, which copies the value of the expression after the assignment
+// token into the last name before it
+func GenerateIRAssignment(node *TreeNode, assignment int, program *IRProgram, rules IRRules) error {
+
+	target := ""
+	for _, child := range node.Children[:assignment] {
+		if child.Symbol == rules.VariableRule {
+			target = child.Value
+		}
+	}
+
+	if target == "" {
+		return fmt.Errorf("assignment has no target: %v", ExpressionText(node.Children))
+	}
+
+	value, err := GenerateIRExpression(node.Children[assignment+1:], program, rules)
+	if err != nil {
+		return err
+	}
+	if value == "" {
+		return fmt.Errorf("assignment to %v has no value", target)
+	}
+
+	EmitQuadruple(program, Quadruple{Operator: ir_copy, Arg1: value, Result: target})
+
+	return nil
+}
+
+// Name: GenerateIRIf
+//
+// Parameters: *TreeNode, *IRProgram, IRRules
+//
+// Return: error
+//
+// Generates a conditional. The condition jumps past the first branch when it is false, and the first branch
+// jumps past the second branch when there is one
+func GenerateIRIf(node *TreeNode, program *IRProgram, rules IRRules) error {
+
+	condition, then_branch, else_branch, err := SplitBranches(node, rules)
+	if err != nil {
+		return err
+	}
+
+	value, err := GenerateIRExpression(condition.Children, program, rules)
+	if err != nil {
+		return err
+	}
+
+	else_label := NewLabel(program)
+	EmitQuadruple(program, Quadruple{Operator: ir_if_false, Arg1: value, Result: else_label})
+
+	if err := GenerateIRStatements(then_branch, program, rules); err != nil {
+		return err
+	}
+
+	if len(else_branch) == 0 {
+		EmitQuadruple(program, Quadruple{Operator: ir_label, Result: else_label})
+		return nil
+	}
+
+	end_label := NewLabel(program)
+	EmitQuadruple(program, Quadruple{Operator: ir_goto, Result: end_label})
+	EmitQuadruple(program, Quadruple{Operator: ir_label, Result: else_label})
+
+	if err := GenerateIRStatements(else_branch, program, rules); err != nil {
+		return err
+	}
+
+	EmitQuadruple(program, Quadruple{Operator: ir_label, Result: end_label})
+
+	return nil
+}
+
+// Name: GenerateIRWhile
+//
+// Parameters: *TreeNode, *IRProgram, IRRules
+//
+// Return: error
+//
+// Generates a loop. The condition is tested at the start of every iteration and jumps past the body when it
+// is false, and the body jumps back to the condition
+func GenerateIRWhile(node *TreeNode, program *IRProgram, rules IRRules) error {
+
+	condition, body, _, err := SplitBranches(node, rules)
+	if err != nil {
+		return err
+	}
+
+	start_label := NewLabel(program)
+	end_label := NewLabel(program)
+
+	EmitQuadruple(program, Quadruple{Operator: ir_label, Result: start_label})
+
+	value, err := GenerateIRExpression(condition.Children, program, rules)
+	if err != nil {
+		return err
+	}
+	EmitQuadruple(program, Quadruple{Operator: ir_if_false, Arg1: value, Result: end_label})
+
+	if err := GenerateIRStatements(body, program, rules); err != nil {
+		return err
+	}
+
+	EmitQuadruple(program, Quadruple{Operator: ir_goto, Result: start_label})
+	EmitQuadruple(program, Quadruple{Operator: ir_label, Result: end_label})
+
+	return nil
+}
+
+// Name: SplitBranches
+//
+// Parameters: *TreeNode, IRRules
+//
+// Return: *TreeNode, []*TreeNode, []*TreeNode, error
+//
+// Finds the condition of a conditional or loop node and the statements after it, split at the else token.
+// Tokens, such as keywords and brackets, are not statements
+func SplitBranches(node *TreeNode, rules IRRules) (*TreeNode, []*TreeNode, []*TreeNode, error) {
+
+	var condition *TreeNode
+	first := []*TreeNode{}
+	second := []*TreeNode{}
+	in_second := false
+
+	for _, child := range node.Children {
+		switch {
+		case condition == nil && child.Symbol == rules.ConditionRule:
+			condition = child
+		case condition == nil:
+			continue
+		case rules.ElseRule != "" && child.Symbol == rules.ElseRule:
+			in_second = true
+		case len(child.Children) == 0:
+			continue
+		case in_second:
+			second = append(second, child)
+		default:
+			first = append(first, child)
+		}
+	}
+
+	if condition == nil {
+		return nil, nil, nil, fmt.Errorf("%v has no condition: %v", node.Symbol, ExpressionText(node.Children))
+	}
+
+	return condition, first, second, nil
+}
+
+// Name: GenerateIRExpression
+//
+// Parameters: []*TreeNode, *IRProgram, IRRules
+//
+// Return: string, error
+//
+// Generates the instructions of the expression made up by the nodes and returns the address holding its value,
+// which is a name, a literal or a temporary. Operators are applied from left to right. Tokens outside a term,
+// such as brackets and keywords, are not operands. Returns an empty address when the nodes hold no operand
+func GenerateIRExpression(nodes []*TreeNode, program *IRProgram, rules IRRules) (string, error) {
+
+	operands := []string{}
+	operators := []string{}
+
+	for _, node := range nodes {
+		if IsIROperator(node, rules) {
+			operators = append(operators, node.Value)
+			continue
+		}
+
+		if len(node.Children) == 0 && node.Symbol != rules.VariableRule {
+			continue
+		}
+
+		operand, err := GenerateIROperand(node, program, rules)
+		if err != nil {
+			return "", err
+		}
+		if operand != "" {
+			operands = append(operands, operand)
+		}
+	}
+
+	if len(operands) == 0 {
+		if len(operators) > 0 {
+			return "", fmt.Errorf("operator %v has no operands", operators[0])
+		}
+		return "", nil
+	}
+
+	if len(operators) != len(operands)-1 {
+		return "", fmt.Errorf("expression does not combine its operands: %v", ExpressionText(nodes))
+	}
+
+	result := operands[0]
+
+	for i, operator := range operators {
+		temporary := NewTemporary(program)
+		EmitQuadruple(program, Quadruple{Operator: operator, Arg1: result, Arg2: operands[i+1], Result: temporary})
+		result = temporary
+	}
+
+	return result, nil
+}
+
+// Name: GenerateIROperand
+//
+// Parameters: *TreeNode, *IRProgram, IRRules
+//
+// Return: string, error
+//
+// Generates the instructions of an operand of an expression. A term holding a single token is the value of
+// the token, a call the temporary holding its result and any other node the value of its children
+func GenerateIROperand(node *TreeNode, program *IRProgram, rules IRRules) (string, error) {
+
+	if rules.CallRule != "" && node.Symbol == rules.CallRule {
+		return GenerateIRCall(node, program, rules, true)
+	}
+
+	if len(node.Children) == 0 {
+		return node.Value, nil
+	}
+
+	if node.Symbol == rules.TermRule {
+		term_node := node
+		for term_node.Value == "" && len(term_node.Children) == 1 && (rules.CallRule == "" || term_node.Symbol != rules.CallRule) {
+			term_node = term_node.Children[0]
+		}
+
+		if rules.CallRule != "" && term_node.Symbol == rules.CallRule {
+			return GenerateIRCall(term_node, program, rules, true)
+		}
+		if len(term_node.Children) == 0 {
+			return term_node.Value, nil
+		}
+		node = term_node
+	}
+
+	return GenerateIRExpression(node.Children, program, rules)
+}
+
+// Name: GenerateIRCall
+//
+// Parameters: *TreeNode, *IRProgram, IRRules, bool
+//
+// Return: string, error
+//
+// Generates a call, which passes each argument with a param instruction before the call. When the value of the
+// call is used, the result is stored in a temporary and returned
+func GenerateIRCall(node *TreeNode, program *IRProgram, rules IRRules, has_value bool) (string, error) {
+
+	name := FindIRName(node, rules)
+	if name == "" {
+		return "", fmt.Errorf("function call has no name")
+	}
+
+	arguments := FindCallArguments(node, GrammarRules{ArgumentRule: rules.ArgumentRule, TermRule: rules.TermRule, CallRule: rules.CallRule})
+
+	values := []string{}
+	for _, argument := range arguments {
+		value, err := GenerateIROperand(argument, program, rules)
+		if err != nil {
+			return "", err
+		}
+		values = append(values, value)
+	}
+
+	for _, value := range values {
+		EmitQuadruple(program, Quadruple{Operator: ir_param, Arg1: value})
+	}
+
+	call := Quadruple{Operator: ir_call, Arg1: name, Arg2: fmt.Sprintf("%v", len(values))}
+	if has_value {
+		call.Result = NewTemporary(program)
+	}
+	EmitQuadruple(program, call)
+
+	return call.Result, nil
+}
+
+// Name: FindIRName
+//
+// Parameters: *TreeNode, IRRules
+//
+// Return: string
+//
+// Finds the first name among the children of a function or call node
+func FindIRName(node *TreeNode, rules IRRules) string {
+
+	for _, child := range node.Children {
+		if child.Symbol == rules.VariableRule {
+			name_node := child
+			for name_node.Value == "" && len(name_node.Children) > 0 {
+				name_node = name_node.Children[0]
+			}
+			return name_node.Value
+		}
+	}
+
+	return ""
+}
+
+// Name: FindIRParameters
+//
+// Parameters: *TreeNode, IRRules
+//
+// Return: []string
+//
+// Finds the names of the parameters of a function node in left-to-right order, outside the body of the function
+func FindIRParameters(node *TreeNode, rules IRRules) []string {
+
+	parameters := []string{}
+
+	if rules.ParameterRule == "" {
+		return parameters
+	}
+
+	for _, child := range node.Children {
+		if child.Symbol == rules.ParameterRule {
+			if name := FindIRName(child, rules); name != "" {
+				parameters = append(parameters, name)
+			}
+		} else if rules.BlockRule == "" || child.Symbol != rules.BlockRule {
+			parameters = append(parameters, FindIRParameters(child, rules)...)
+		}
+	}
+
+	return parameters
+}
+
+// Name: IsIROperator
+//
+// Parameters: *TreeNode, IRRules
+//
+// Return: bool
+//
+// Determines if a node is an arithmetic or comparison operator token
+func IsIROperator(node *TreeNode, rules IRRules) bool {
+
+	if len(node.Children) > 0 {
+		return false
+	}
+
+	return (rules.OperatorRule != "" && node.Symbol == rules.OperatorRule) || (rules.ComparisonRule != "" && node.Symbol == rules.ComparisonRule)
+}
+
+// Name: NewTemporary
+//
+// Parameters: *IRProgram
+//
+// Return: string
+//
+// Creates the name of a new temporary: t1, t2, ...
+func NewTemporary(program *IRProgram) string {
+
+	program.Temporaries++
+
+	return fmt.Sprintf("t%v", program.Temporaries)
+}
+
+// Name: NewLabel
+//
+// Parameters: *IRProgram
+//
+// Return: string
+//
+// Creates the name of a new label: L1, L2, ...
+func NewLabel(program *IRProgram) string {
+
+	program.Labels++
+
+	return fmt.Sprintf("L%v", program.Labels)
+}
+
+// Name: EmitQuadruple
+//
+// Parameters: *IRProgram, Quadruple
+//
+// Return: none
+//
+// Appends an instruction to the program
+func EmitQuadruple(program *IRProgram, instruction Quadruple) {
+
+	program.Instructions = append(program.Instructions, instruction)
+}
+
+// Name: FormatQuadruple
+//
+// Parameters: Quadruple
+//
+// Return: string
+//
+// Writes out an instruction in three-address form, such as t1 = a + b or ifFalse t1 goto L2
+func FormatQuadruple(instruction Quadruple) string {
+
+	switch instruction.Operator {
+	case ir_copy:
+		return fmt.Sprintf("%v = %v", instruction.Result, instruction.Arg1)
+	case ir_label:
+		return instruction.Result + ":"
+	case ir_goto:
+		return "goto " + instruction.Result
+	case ir_if_false:
+		return fmt.Sprintf("ifFalse %v goto %v", instruction.Arg1, instruction.Result)
+	case ir_param:
+		return "param " + instruction.Arg1
+	case ir_receive:
+		return "receive " + instruction.Arg1
+	case ir_call:
+		if instruction.Result == "" {
+			return fmt.Sprintf("call %v, %v", instruction.Arg1, instruction.Arg2)
+		}
+		return fmt.Sprintf("%v = call %v, %v", instruction.Result, instruction.Arg1, instruction.Arg2)
+	case ir_return:
+		return strings.TrimSpace("return " + instruction.Arg1)
+	case ir_begin_func:
+		return fmt.Sprintf("begin_func %v", instruction.Arg1)
+	case ir_end_func:
+		return fmt.Sprintf("end_func %v", instruction.Arg1)
+	default:
+		return fmt.Sprintf("%v = %v %v %v", instruction.Result, instruction.Arg1, instruction.Operator, instruction.Arg2)
+	}
+}
+
+// Name: ConvertIRToString
+//
+// Parameters: IRProgram
+//
+// Return: string
+//
+// Writes out the listing of the program, one instruction per line. Labels start at the beginning of the line
+// and other instructions are indented
+func ConvertIRToString(program IRProgram) string {
+
+	var output strings.Builder
+
+	for _, instruction := range program.Instructions {
+		if instruction.Operator != ir_label && instruction.Operator != ir_begin_func && instruction.Operator != ir_end_func {
+			output.WriteString("    ")
+		}
+		output.WriteString(FormatQuadruple(instruction))
+		output.WriteString("\n")
+	}
+
+	return output.String()
+}
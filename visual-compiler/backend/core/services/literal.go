@@ -0,0 +1,108 @@
+package services
+
+import (
+	"strings"
+)
+
+// Name: ReadLiteral
+//
+// Parameters: string
+//
+// Return: string, string, bool
+//
+// Splits a literal terminal such as 'if' or KEYWORD:'if' into the token type it is constrained to
+// and the token value it matches. Returns false when the symbol is not a literal
+func ReadLiteral(symbol string) (string, string, bool) {
+
+	quote := strings.IndexAny(symbol, "'\"")
+	if quote < 0 || len(symbol)-quote < 3 || symbol[len(symbol)-1] != symbol[quote] {
+		return "", "", false
+	}
+
+	token_type := ""
+	if quote > 0 {
+		if symbol[quote-1] != ':' || !notation_identifier.MatchString(symbol[:quote-1]) {
+			return "", "", false
+		}
+		token_type = symbol[:quote-1]
+	}
+
+	value := symbol[quote+1 : len(symbol)-1]
+	if strings.ContainsRune(value, rune(symbol[quote])) {
+		return "", "", false
+	}
+
+	return token_type, value, true
+}
+
+// Name: IsLiteral
+//
+// Parameters: string
+//
+// Return: bool
+//
+// Determines if the symbol is a quoted literal terminal
+func IsLiteral(symbol string) bool {
+
+	_, _, literal := ReadLiteral(symbol)
+
+	return literal
+}
+
+// Name: FormatLiteral
+//
+// Parameters: string, string
+//
+// Return: string
+//
+// Returns the literal terminal matching the value, constrained to the token type when one is given.
+// Values containing a single quote are written in double quotes
+func FormatLiteral(token_type string, value string) string {
+
+	quote := "'"
+	if strings.Contains(value, "'") {
+		quote = "\""
+	}
+
+	literal := quote + value + quote
+	if token_type != "" {
+		literal = token_type + ":" + literal
+	}
+
+	return literal
+}
+
+// Name: MatchesTerminal
+//
+// Parameters: string, TypeValue
+//
+// Return: bool
+//
+// Determines if the token matches the terminal, either by its type or, for a literal, by its value
+// and the constrained type
+func MatchesTerminal(terminal string, token TypeValue) bool {
+
+	token_type, value, literal := ReadLiteral(terminal)
+	if !literal {
+		return terminal == token.Type
+	}
+
+	return value == token.Value && (token_type == "" || token_type == token.Type)
+}
+
+// Name: NormaliseLiteral
+//
+// Parameters: string
+//
+// Return: string
+//
+// Returns the literal with its type constraint in upper case, or the symbol unchanged when it is not a literal
+func NormaliseLiteral(symbol string) string {
+
+	token_type, value, literal := ReadLiteral(symbol)
+	if !literal {
+		return symbol
+	}
+
+	return FormatLiteral(strings.ToUpper(token_type), value)
+}
@@ -8,10 +8,24 @@ import (
 
 // Struct for the grammar
 type Grammar struct {
-	Variables []string      `json:"variables"`
-	Terminals []string      `json:"terminals"`
-	Start     string        `json:"start"`
-	Rules     []ParsingRule `json:"rules"`
+	Variables     []string      `json:"variables"`
+	Terminals     []string      `json:"terminals"`
+	Start         string        `json:"start"`
+	Rules         []ParsingRule `json:"rules"`
+	SyncTerminals []string      `json:"sync_terminals,omitempty"`
+	Operators     []Operator    `json:"operators,omitempty"`
+	Expressions   []string      `json:"expressions,omitempty"`
+}
+
+// Struct for an operator declaration used to parse the expression variables
+//
+// value restricts the operator to tokens with that value, a higher precedence binds tighter and
+// associativity is left, right or nonassoc
+type Operator struct {
+	Terminal      string `json:"terminal"`
+	Value         string `json:"value,omitempty"`
+	Precedence    int    `json:"precedence"`
+	Associativity string `json:"associativity"`
 }
 
 type ParsingRule struct {
@@ -35,6 +49,23 @@ type ParseState struct {
 	Position int
 	Tokens   []TypeValue
 	Grammar  Grammar
+	Visiting map[string]bool
+	Tracing  bool
+	Depth    int
+	Trace    []ParseStep
+	Furthest int
+	Expected []string
+}
+
+// Struct for one step of the parse trace
+type ParseStep struct {
+	Step     int    `json:"step"`
+	Depth    int    `json:"depth"`
+	Action   string `json:"action"`
+	Symbol   string `json:"symbol"`
+	Rule     string `json:"rule"`
+	Position int    `json:"position"`
+	End      int    `json:"end"`
 }
 
 // Name: ReadGrammar
@@ -58,6 +89,18 @@ func ReadGrammar(input []byte) (Grammar, error) {
 		return Grammar{}, fmt.Errorf("invalid JSON for grammar: %v", err)
 	}
 
+	return ValidateGrammar(grammar)
+}
+
+// Name: ValidateGrammar
+//
+// Parameters: Grammar
+//
+// Return: Grammar, error
+//
+// Ensure the structure of the grammar is correct and normalise the terminals
+func ValidateGrammar(grammar Grammar) (Grammar, error) {
+
 	valid := false
 
 	for _, vars := range grammar.Variables {
@@ -72,7 +115,17 @@ func ReadGrammar(input []byte) (Grammar, error) {
 	}
 
 	for i, term := range grammar.Terminals {
-		grammar.Terminals[i] = strings.ToUpper(term)
+		if IsLiteral(term) {
+			grammar.Terminals[i] = NormaliseLiteral(term)
+		} else {
+			grammar.Terminals[i] = strings.ToUpper(term)
+		}
+	}
+
+	for _, rule := range grammar.Rules {
+		for i, symbol := range rule.Output {
+			rule.Output[i] = NormaliseLiteral(symbol)
+		}
 	}
 
 	for _, rule := range grammar.Rules {
@@ -81,6 +134,54 @@ func ReadGrammar(input []byte) (Grammar, error) {
 		}
 	}
 
+	for i, sync := range grammar.SyncTerminals {
+		if IsLiteral(sync) {
+			grammar.SyncTerminals[i] = NormaliseLiteral(sync)
+			continue
+		}
+		grammar.SyncTerminals[i] = strings.ToUpper(sync)
+
+		if !ContainsSymbol(grammar.Terminals, grammar.SyncTerminals[i]) {
+			return Grammar{}, fmt.Errorf("synchronising terminal %v is not in the list of terminals", sync)
+		}
+	}
+
+	for _, expression := range grammar.Expressions {
+		if !ContainsSymbol(grammar.Variables, expression) {
+			return Grammar{}, fmt.Errorf("expression variable %v is not in the list of variables", expression)
+		}
+	}
+
+	if len(grammar.Expressions) > 0 && len(grammar.Operators) == 0 {
+		return Grammar{}, fmt.Errorf("expression variables require at least one operator")
+	}
+
+	declared := make(map[string]bool)
+
+	for i, operator := range grammar.Operators {
+		operator.Terminal = strings.ToUpper(operator.Terminal)
+
+		if !ContainsSymbol(grammar.Terminals, operator.Terminal) {
+			return Grammar{}, fmt.Errorf("operator %v is not in the list of terminals", operator.Terminal)
+		}
+
+		switch operator.Associativity {
+		case "":
+			operator.Associativity = "left"
+		case "left", "right", "nonassoc":
+		default:
+			return Grammar{}, fmt.Errorf("invalid associativity for operator %v: %v", operator.Terminal, operator.Associativity)
+		}
+
+		key := operator.Terminal + " " + operator.Value
+		if declared[key] {
+			return Grammar{}, fmt.Errorf("operator declared more than once: %v", strings.TrimSpace(key))
+		}
+		declared[key] = true
+
+		grammar.Operators[i] = operator
+	}
+
 	return grammar, nil
 }
 
@@ -93,38 +194,169 @@ func ReadGrammar(input []byte) (Grammar, error) {
 // Recursively build the syntax tree from the tokens and the grammar
 func CreateSyntaxTree(tokens []TypeValue, grammar Grammar) (SyntaxTree, error) {
 
+	tree, _, err := ParseTokens(tokens, grammar, false)
+
+	return tree, err
+}
+
+// Name: CreateTracedSyntaxTree
+//
+// Parameters: []TypeValue, Grammar
+//
+// Return: SyntaxTree, []ParseStep, error
+//
+// Build the syntax tree and record every rule attempt, match and backtrack made by the parser.
+// The trace is returned even when the parse fails
+func CreateTracedSyntaxTree(tokens []TypeValue, grammar Grammar) (SyntaxTree, []ParseStep, error) {
+
+	return ParseTokens(tokens, grammar, true)
+}
+
+// Name: ParseTokens
+//
+// Parameters: []TypeValue, Grammar, bool
+//
+// Return: SyntaxTree, []ParseStep, error
+//
+// Validate the tokens against the grammar and parse them from the start variable, optionally tracing the parser
+func ParseTokens(tokens []TypeValue, grammar Grammar, tracing bool) (SyntaxTree, []ParseStep, error) {
+
+	err := ValidateTokens(tokens, grammar)
+	if err != nil {
+		return SyntaxTree{}, nil, err
+	}
+
+	state := &ParseState{
+		Position: 0,
+		Tokens:   tokens,
+		Grammar:  grammar,
+		Tracing:  tracing,
+	}
+
+	root, new_position, success := ParseSymbol(state, grammar.Start, 0)
+
+	if !success || new_position != len(tokens) {
+		return SyntaxTree{}, state.Trace, fmt.Errorf("syntax error")
+	}
+
+	return SyntaxTree{Root: root}, state.Trace, nil
+}
+
+// Name: ValidateTokens
+//
+// Parameters: []TypeValue, Grammar
+//
+// Return: error
+//
+// Ensure there are tokens to parse and that every token is either of a terminal type of the grammar
+// or matched by one of its literal terminals
+func ValidateTokens(tokens []TypeValue, grammar Grammar) error {
+
 	if len(tokens) == 0 {
-		return SyntaxTree{}, fmt.Errorf("no tokens found")
+		return fmt.Errorf("no tokens found")
 	}
 
 	if grammar.Start == "" {
-		return SyntaxTree{}, fmt.Errorf("no start variable found")
+		return fmt.Errorf("no start variable found")
 	}
 
 	link := make(map[string]bool)
+	literals := []string{}
+
 	for _, term := range grammar.Terminals {
-		link[term] = true
+		if IsLiteral(term) {
+			literals = append(literals, term)
+		} else {
+			link[term] = true
+		}
+	}
+
+	for _, rule := range grammar.Rules {
+		for _, symbol := range rule.Output {
+			if IsLiteral(symbol) {
+				literals = append(literals, symbol)
+			}
+		}
 	}
 
 	for _, token := range tokens {
-		if !link[token.Type] {
-			return SyntaxTree{}, fmt.Errorf("token types do not correspond to grammar terminals")
+		if link[token.Type] {
+			continue
+		}
+
+		matched := false
+		for _, literal := range literals {
+			if MatchesTerminal(literal, token) {
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			return fmt.Errorf("token types do not correspond to grammar terminals")
 		}
 	}
 
-	state := &ParseState{
-		Position: 0,
-		Tokens:   tokens,
-		Grammar:  grammar,
+	return nil
+}
+
+// Name: RecordStep
+//
+// Parameters: *ParseState, string, string, string, int, int
+//
+// Return: none
+//
+// Adds a step to the parse trace when tracing is enabled
+func RecordStep(state *ParseState, action string, symbol string, rule string, position int, end int) {
+
+	if !state.Tracing {
+		return
 	}
 
-	root, new_position, success := ParseSymbol(state, grammar.Start, 0)
+	state.Trace = append(state.Trace, ParseStep{
+		Step:     len(state.Trace) + 1,
+		Depth:    state.Depth,
+		Action:   action,
+		Symbol:   symbol,
+		Rule:     rule,
+		Position: position,
+		End:      end,
+	})
+}
 
-	if !success || new_position != len(tokens) {
-		return SyntaxTree{}, fmt.Errorf("syntax error")
+// Name: RecordFailure
+//
+// Parameters: *ParseState, string, int
+//
+// Return: none
+//
+// Keeps the furthest position at which a symbol could not be matched and the symbols expected there
+func RecordFailure(state *ParseState, symbol string, position int) {
+
+	if position > state.Furthest {
+		state.Furthest = position
+		state.Expected = nil
+	}
+
+	if position == state.Furthest && !ContainsSymbol(state.Expected, symbol) {
+		state.Expected = append(state.Expected, symbol)
+	}
+}
+
+// Name: FormatParsingRule
+//
+// Parameters: ParsingRule
+//
+// Return: string
+//
+// Returns the rule as a string of the form INPUT -> OUTPUT
+func FormatParsingRule(rule ParsingRule) string {
+
+	if len(rule.Output) == 0 {
+		return rule.Input + " -> ε"
 	}
 
-	return SyntaxTree{Root: root}, nil
+	return rule.Input + " -> " + strings.Join(rule.Output, " ")
 }
 
 // Name: ParseSymbol
@@ -133,14 +365,10 @@ func CreateSyntaxTree(tokens []TypeValue, grammar Grammar) (SyntaxTree, error) {
 //
 // Return: *TreeNode, int, bool
 //
-// Attempts to parse a variable or a terminal starting at the given position
+// Attempts to parse a variable, a terminal or a literal terminal starting at the given position
 func ParseSymbol(state *ParseState, symbol string, position int) (*TreeNode, int, bool) {
 
-	if position >= len(state.Tokens) {
-		return nil, position, false
-	}
-
-	found := false
+	found := IsLiteral(symbol)
 
 	for _, terminal := range state.Grammar.Terminals {
 		if terminal == symbol {
@@ -150,9 +378,88 @@ func ParseSymbol(state *ParseState, symbol string, position int) (*TreeNode, int
 
 	if found {
 		return ParseTerminal(state, symbol, position)
+	}
+
+	if position >= len(state.Tokens) && !HasEmptyRule(state.Grammar, symbol) {
+		RecordFailure(state, symbol, position)
+		return nil, position, false
+	}
+
+	if state.Visiting == nil {
+		state.Visiting = make(map[string]bool)
+	}
+
+	// a variable that is re-entered at the same position can never consume more input
+	visit_key := fmt.Sprintf("%s@%d", symbol, position)
+	if state.Visiting[visit_key] {
+		return nil, position, false
+	}
+
+	state.Visiting[visit_key] = true
+
+	var node *TreeNode
+	var new_position int
+	var success bool
+
+	if ContainsSymbol(state.Grammar.Expressions, symbol) {
+		node, new_position, success = ParseExpression(state, symbol, position, MinimumPrecedence(state.Grammar))
 	} else {
-		return ParseVariable(state, symbol, position)
+		node, new_position, success = ParseVariable(state, symbol, position)
 	}
+
+	delete(state.Visiting, visit_key)
+
+	return node, new_position, success
+}
+
+// Name: HasEmptyRule
+//
+// Parameters: Grammar, string
+//
+// Return: bool
+//
+// Determines if the variable can derive the empty string through an ε rule
+func HasEmptyRule(grammar Grammar, variable string) bool {
+
+	for _, rule := range grammar.Rules {
+		if rule.Input != variable {
+			continue
+		}
+
+		empty := true
+		for _, symbol := range rule.Output {
+			if symbol != "ε" && !HasEmptyRule(Grammar{Rules: removeRulesFor(grammar.Rules, variable)}, symbol) {
+				empty = false
+				break
+			}
+		}
+
+		if empty {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Name: removeRulesFor
+//
+// Parameters: []ParsingRule, string
+//
+// Return: []ParsingRule
+//
+// Returns the rules without those of the given variable, used to stop recursion when searching for ε rules
+func removeRulesFor(rules []ParsingRule, variable string) []ParsingRule {
+
+	remaining := []ParsingRule{}
+
+	for _, rule := range rules {
+		if rule.Input != variable {
+			remaining = append(remaining, rule)
+		}
+	}
+
+	return remaining
 }
 
 // Name: ParseTerminal
@@ -161,26 +468,34 @@ func ParseSymbol(state *ParseState, symbol string, position int) (*TreeNode, int
 //
 // Return: *TreeNode, int, bool
 //
-// Attempts to match a terminal symbol with the current token
+// Attempts to match a terminal symbol with the current token. The node of a matched literal
+// terminal takes the type of the token
 func ParseTerminal(state *ParseState, terminal string, position int) (*TreeNode, int, bool) {
 
 	if position >= len(state.Tokens) {
+		RecordStep(state, "fail terminal", terminal, "", position, position)
+		RecordFailure(state, terminal, position)
 		return nil, position, false
 	}
 
 	token := state.Tokens[position]
 
-	if token.Type == terminal {
+	if MatchesTerminal(terminal, token) {
 
 		node := &TreeNode{
-			Symbol:   terminal,
+			Symbol:   token.Type,
 			Value:    token.Value,
 			Children: nil,
 		}
 
+		RecordStep(state, "match terminal", terminal, "", position, position+1)
+
 		return node, position + 1, true
 	}
 
+	RecordStep(state, "fail terminal", terminal, "", position, position)
+	RecordFailure(state, terminal, position)
+
 	return nil, position, false
 }
 
@@ -194,6 +509,7 @@ func ParseTerminal(state *ParseState, terminal string, position int) (*TreeNode,
 func ParseVariable(state *ParseState, variable string, position int) (*TreeNode, int, bool) {
 
 	var best_node *TreeNode
+	var best_rule ParsingRule
 	best_position := position
 	success := false
 
@@ -202,8 +518,9 @@ func ParseVariable(state *ParseState, variable string, position int) (*TreeNode,
 		if rule.Input == variable {
 			node, new_position, match := TryRule(state, rule, position)
 
-			if match && new_position > best_position {
+			if match && (!success || new_position > best_position) {
 				best_node = node
+				best_rule = rule
 				best_position = new_position
 				success = true
 			}
@@ -211,11 +528,14 @@ func ParseVariable(state *ParseState, variable string, position int) (*TreeNode,
 	}
 
 	if success {
+		RecordStep(state, "select", variable, FormatParsingRule(best_rule), position, best_position)
 		return best_node, best_position, true
 	} else {
+		RecordStep(state, "backtrack", variable, "", position, position)
 		return nil, position, false
 	}
 }
+
 // Name: tryRule
 //
 // Parameters: *ParseState, ParsingRule, int
@@ -233,6 +553,9 @@ func TryRule(state *ParseState, rule ParsingRule, position int) (*TreeNode, int,
 
 	current_position := position
 
+	RecordStep(state, "try", rule.Input, FormatParsingRule(rule), position, position)
+	state.Depth++
+
 	for _, symbol := range rule.Output {
 
 		if symbol == "ε" {
@@ -242,6 +565,8 @@ func TryRule(state *ParseState, rule ParsingRule, position int) (*TreeNode, int,
 		child, next_position, match := ParseSymbol(state, symbol, current_position)
 
 		if !match {
+			state.Depth--
+			RecordStep(state, "fail", rule.Input, FormatParsingRule(rule), position, current_position)
 			return nil, position, false
 		}
 
@@ -249,6 +574,9 @@ func TryRule(state *ParseState, rule ParsingRule, position int) (*TreeNode, int,
 		current_position = next_position
 	}
 
+	state.Depth--
+	RecordStep(state, "match", rule.Input, FormatParsingRule(rule), position, current_position)
+
 	return node, current_position, true
 }
 
@@ -0,0 +1,126 @@
+package services
+
+// Name: ParseExpression
+//
+// Parameters: *ParseState, string, int, int
+//
+// Return: *TreeNode, int, bool
+//
+// Parses an expression variable by precedence climbing. The operands are parsed with the rules of the
+// variable and are joined by the declared operators of at least the minimum precedence, giving a node
+// with the children left operand, operator and right operand
+func ParseExpression(state *ParseState, variable string, position int, minimum int) (*TreeNode, int, bool) {
+
+	left, current_position, success := ParseVariable(state, variable, position)
+	if !success {
+		return nil, position, false
+	}
+
+	nonassoc_level := 0
+	nonassoc_found := false
+
+	for current_position < len(state.Tokens) {
+
+		operator, found := MatchOperator(state.Grammar, state.Tokens[current_position])
+		if !found {
+			for _, declared := range state.Grammar.Operators {
+				RecordFailure(state, declared.Terminal, current_position)
+			}
+			break
+		}
+
+		if operator.Precedence < minimum {
+			break
+		}
+
+		// a non-associative operator cannot follow another operator of the same level
+		if nonassoc_found && operator.Precedence == nonassoc_level {
+			break
+		}
+
+		next_minimum := operator.Precedence + 1
+		if operator.Associativity == "right" {
+			next_minimum = operator.Precedence
+		}
+
+		token := state.Tokens[current_position]
+
+		state.Depth++
+		right, next_position, match := ParseExpression(state, variable, current_position+1, next_minimum)
+		state.Depth--
+
+		if !match {
+			break
+		}
+
+		left = &TreeNode{
+			Symbol: variable,
+			Value:  "",
+			Children: []*TreeNode{
+				left,
+				{Symbol: token.Type, Value: token.Value, Children: nil},
+				right,
+			},
+		}
+
+		RecordStep(state, "operator", variable, variable+" -> "+variable+" "+token.Value+" "+variable, position, next_position)
+
+		current_position = next_position
+
+		nonassoc_found = operator.Associativity == "nonassoc"
+		nonassoc_level = operator.Precedence
+	}
+
+	return left, current_position, true
+}
+
+// Name: MatchOperator
+//
+// Parameters: Grammar, TypeValue
+//
+// Return: Operator, bool
+//
+// Finds the operator declaration for the token. A declaration with a value takes priority over one
+// that only names the terminal
+func MatchOperator(grammar Grammar, token TypeValue) (Operator, bool) {
+
+	var match Operator
+	found := false
+
+	for _, operator := range grammar.Operators {
+		if operator.Terminal != token.Type {
+			continue
+		}
+
+		if operator.Value == token.Value {
+			return operator, true
+		}
+
+		if operator.Value == "" && !found {
+			match = operator
+			found = true
+		}
+	}
+
+	return match, found
+}
+
+// Name: MinimumPrecedence
+//
+// Parameters: Grammar
+//
+// Return: int
+//
+// Returns the lowest declared operator precedence
+func MinimumPrecedence(grammar Grammar) int {
+
+	minimum := 0
+
+	for i, operator := range grammar.Operators {
+		if i == 0 || operator.Precedence < minimum {
+			minimum = operator.Precedence
+		}
+	}
+
+	return minimum
+}
@@ -0,0 +1,299 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Struct for a syntax error found while recovering
+type SyntaxError struct {
+	Position int         `json:"position"`
+	Found    TypeValue   `json:"found"`
+	Expected []string    `json:"expected"`
+	Skipped  []TypeValue `json:"skipped"`
+	Message  string      `json:"message"`
+}
+
+// Terminal that replaces the tokens skipped by the parser
+const error_terminal = "ERROR"
+
+// Name: CreateRecoveredSyntaxTree
+//
+// Parameters: []TypeValue, Grammar
+//
+// Return: SyntaxTree, []SyntaxError, error
+//
+// Build the syntax tree using panic-mode error recovery. At each syntax error the tokens from the
+// start of the current construct up to and including the next synchronising terminal are skipped
+// and replaced by an ERROR node, then parsing continues. Returns the partial tree and all errors
+func CreateRecoveredSyntaxTree(tokens []TypeValue, grammar Grammar) (SyntaxTree, []SyntaxError, error) {
+
+	err := ValidateTokens(tokens, grammar)
+	if err != nil {
+		return SyntaxTree{}, nil, err
+	}
+
+	if len(grammar.SyncTerminals) == 0 {
+		return SyntaxTree{}, nil, fmt.Errorf("grammar has no synchronising terminals")
+	}
+
+	recovery_grammar, err := CreateRecoveryGrammar(grammar)
+	if err != nil {
+		return SyntaxTree{}, nil, err
+	}
+
+	working := append([]TypeValue{}, tokens...)
+	skipped := [][]TypeValue{}
+	syntax_errors := []SyntaxError{}
+
+	for attempt := 0; attempt <= len(tokens); attempt++ {
+
+		state := &ParseState{
+			Tokens:  working,
+			Grammar: recovery_grammar,
+		}
+
+		root, new_position, success := ParseSymbol(state, recovery_grammar.Start, 0)
+
+		if success && new_position == len(working) {
+			ExpandErrorNodes(root, skipped)
+			return SyntaxTree{Root: root}, syntax_errors, nil
+		}
+
+		failure := state.Furthest
+		expected := state.Expected
+		if success && new_position > failure {
+			failure = new_position
+			expected = nil
+		}
+		if failure > len(working) {
+			failure = len(working)
+		}
+
+		start, end := FindSkippedRange(working, grammar.SyncTerminals, failure)
+		if start > end || (start == end && working[start].Type == error_terminal) {
+			break
+		}
+
+		syntax_error := SyntaxError{
+			Position: OriginalPosition(working, skipped, failure),
+			Expected: []string{},
+		}
+
+		for _, symbol := range expected {
+			if symbol != error_terminal {
+				syntax_error.Expected = append(syntax_error.Expected, symbol)
+			}
+		}
+
+		if failure < len(working) {
+			syntax_error.Found = UnwrapErrorToken(working[failure], skipped)[0]
+		}
+		syntax_error.Message = FormatSyntaxError(syntax_error)
+
+		group := []TypeValue{}
+		for _, token := range working[start : end+1] {
+			group = append(group, UnwrapErrorToken(token, skipped)...)
+		}
+		syntax_error.Skipped = group
+		syntax_errors = append(syntax_errors, syntax_error)
+
+		replaced := append([]TypeValue{}, working[:start]...)
+		replaced = append(replaced, TypeValue{Type: error_terminal, Value: strconv.Itoa(len(skipped))})
+		replaced = append(replaced, working[end+1:]...)
+
+		skipped = append(skipped, group)
+		working = replaced
+	}
+
+	return SyntaxTree{}, syntax_errors, fmt.Errorf("syntax error could not be recovered")
+}
+
+// Name: CreateRecoveryGrammar
+//
+// Parameters: Grammar
+//
+// Return: Grammar, error
+//
+// Copy the grammar and allow every variable with a rule ending in a synchronising terminal to derive
+// a single ERROR terminal, so skipped constructs can take their place in the tree
+func CreateRecoveryGrammar(grammar Grammar) (Grammar, error) {
+
+	recovery_grammar := grammar
+	recovery_grammar.Terminals = append([]string{}, grammar.Terminals...)
+	recovery_grammar.Rules = append([]ParsingRule{}, grammar.Rules...)
+
+	if !ContainsSymbol(recovery_grammar.Terminals, error_terminal) {
+		recovery_grammar.Terminals = append(recovery_grammar.Terminals, error_terminal)
+	}
+
+	recovering := []string{}
+
+	for _, rule := range grammar.Rules {
+		if len(rule.Output) == 0 || ContainsSymbol(recovering, rule.Input) {
+			continue
+		}
+
+		if ContainsSymbol(grammar.SyncTerminals, rule.Output[len(rule.Output)-1]) {
+			recovering = append(recovering, rule.Input)
+			recovery_grammar.Rules = append(recovery_grammar.Rules, ParsingRule{
+				Input:  rule.Input,
+				Output: []string{error_terminal},
+			})
+		}
+	}
+
+	if len(recovering) == 0 {
+		return Grammar{}, fmt.Errorf("no grammar rule ends with a synchronising terminal")
+	}
+
+	return recovery_grammar, nil
+}
+
+// Name: FindSkippedRange
+//
+// Parameters: []TypeValue, []string, int
+//
+// Return: int, int
+//
+// Returns the first and last index of the tokens to skip for an error at the given position. The range
+// starts after the previous synchronising terminal or skipped range and ends at the next synchronising
+// terminal, or at the end of the input
+func FindSkippedRange(tokens []TypeValue, sync_terminals []string, position int) (int, int) {
+
+	boundary := func(index int) bool {
+		return tokens[index].Type == error_terminal || IsSyncToken(tokens[index], sync_terminals)
+	}
+
+	start := position
+	for start > 0 && !boundary(start-1) {
+		start--
+	}
+
+	// the input ended straight after a synchronising terminal, so the last construct is incomplete
+	if start >= len(tokens) && start > 0 {
+		start--
+		for start > 0 && !boundary(start-1) {
+			start--
+		}
+	}
+
+	end := position
+	for end < len(tokens)-1 && !IsSyncToken(tokens[end], sync_terminals) {
+		end++
+	}
+	if end >= len(tokens) {
+		end = len(tokens) - 1
+	}
+
+	return start, end
+}
+
+// Name: IsSyncToken
+//
+// Parameters: TypeValue, []string
+//
+// Return: bool
+//
+// Determines if the token matches one of the synchronising terminals, either by its type or, for a literal, by its value
+func IsSyncToken(token TypeValue, sync_terminals []string) bool {
+
+	for _, sync := range sync_terminals {
+		if MatchesTerminal(sync, token) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Name: OriginalPosition
+//
+// Parameters: []TypeValue, [][]TypeValue, int
+//
+// Return: int
+//
+// Converts a position in the recovered tokens back to the position in the original tokens
+func OriginalPosition(tokens []TypeValue, skipped [][]TypeValue, position int) int {
+
+	original := 0
+
+	for _, token := range tokens[:position] {
+		original += len(UnwrapErrorToken(token, skipped))
+	}
+
+	return original
+}
+
+// Name: UnwrapErrorToken
+//
+// Parameters: TypeValue, [][]TypeValue
+//
+// Return: []TypeValue
+//
+// Returns the original tokens replaced by an ERROR token, or the token itself
+func UnwrapErrorToken(token TypeValue, skipped [][]TypeValue) []TypeValue {
+
+	if token.Type == error_terminal {
+		index, err := strconv.Atoi(token.Value)
+		if err == nil && index >= 0 && index < len(skipped) {
+			return skipped[index]
+		}
+	}
+
+	return []TypeValue{token}
+}
+
+// Name: ExpandErrorNodes
+//
+// Parameters: *TreeNode, [][]TypeValue
+//
+// Return: none
+//
+// Recursively replaces each ERROR leaf with an ERROR node whose children are the skipped tokens
+func ExpandErrorNodes(node *TreeNode, skipped [][]TypeValue) {
+
+	if node == nil {
+		return
+	}
+
+	if node.Symbol == error_terminal && len(node.Children) == 0 {
+		tokens := UnwrapErrorToken(TypeValue{Type: node.Symbol, Value: node.Value}, skipped)
+
+		node.Value = ""
+		node.Children = []*TreeNode{}
+		for _, token := range tokens {
+			node.Children = append(node.Children, &TreeNode{Symbol: token.Type, Value: token.Value})
+		}
+		return
+	}
+
+	for _, child := range node.Children {
+		ExpandErrorNodes(child, skipped)
+	}
+}
+
+// Name: FormatSyntaxError
+//
+// Parameters: SyntaxError
+//
+// Return: string
+//
+// Returns a readable message for the syntax error
+func FormatSyntaxError(syntax_error SyntaxError) string {
+
+	message := "unexpected end of input"
+
+	if syntax_error.Found.Type != "" {
+		message = fmt.Sprintf("unexpected %v '%v'", syntax_error.Found.Type, syntax_error.Found.Value)
+	}
+
+	message += fmt.Sprintf(" at position %d", syntax_error.Position)
+
+	if len(syntax_error.Expected) > 0 {
+		message += ", expected " + strings.Join(syntax_error.Expected, " or ")
+	}
+
+	return message
+}
@@ -0,0 +1,365 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Name: ExportTree
+//
+// Parameters: SyntaxTree, string
+//
+// Return: string, error
+//
+// Converts the syntax tree to the requested format: text, dot, sexpr, forest or qtree
+func ExportTree(tree SyntaxTree, format string) (string, error) {
+
+	switch format {
+	case "", "text":
+		return ConvertTreeToString(tree.Root, "", true), nil
+	case "dot":
+		return ConvertTreeToDot(tree.Root), nil
+	case "sexpr":
+		return ConvertTreeToSExpression(tree.Root), nil
+	case "forest":
+		return ConvertTreeToForest(tree.Root), nil
+	case "qtree":
+		return ConvertTreeToQtree(tree.Root), nil
+	default:
+		return "", fmt.Errorf("unknown tree format: %v", format)
+	}
+}
+
+// Name: ConvertTreeToDot
+//
+// Parameters: *TreeNode
+//
+// Return: string
+//
+// Build a Graphviz DOT graph of the syntax tree. Nodes holding a token value are drawn as ellipses
+func ConvertTreeToDot(node *TreeNode) string {
+
+	var output strings.Builder
+
+	output.WriteString("digraph SyntaxTree {\n")
+	output.WriteString("    node [shape=box];\n")
+
+	if node != nil {
+		count := 0
+		WriteDotNode(&output, node, &count)
+	}
+
+	output.WriteString("}\n")
+
+	return output.String()
+}
+
+// Name: WriteDotNode
+//
+// Parameters: *strings.Builder, *TreeNode, *int
+//
+// Return: string
+//
+// Recursively writes the node and the edges to its children, returning the identifier of the node
+func WriteDotNode(output *strings.Builder, node *TreeNode, count *int) string {
+
+	id := fmt.Sprintf("n%d", *count)
+	*count++
+
+	if node.Value == "" {
+		output.WriteString(fmt.Sprintf("    %s [label=\"%s\"];\n", id, EscapeDot(node.Symbol)))
+	} else {
+		output.WriteString(fmt.Sprintf("    %s [label=\"%s\\n%s\", shape=ellipse];\n", id, EscapeDot(node.Symbol), EscapeDot(node.Value)))
+	}
+
+	for _, child := range node.Children {
+		if child == nil {
+			continue
+		}
+		child_id := WriteDotNode(output, child, count)
+		output.WriteString(fmt.Sprintf("    %s -> %s;\n", id, child_id))
+	}
+
+	return id
+}
+
+// Name: EscapeDot
+//
+// Parameters: string
+//
+// Return: string
+//
+// Escapes the characters that cannot appear in a quoted DOT label
+func EscapeDot(label string) string {
+
+	label = strings.ReplaceAll(label, "\\", "\\\\")
+	label = strings.ReplaceAll(label, "\"", "\\\"")
+	label = strings.ReplaceAll(label, "\n", "\\n")
+
+	return label
+}
+
+// Name: ConvertTreeToSExpression
+//
+// Parameters: *TreeNode
+//
+// Return: string
+//
+// Build the canonical S-expression of the syntax tree, for example (TERM (INTEGER "13"))
+func ConvertTreeToSExpression(node *TreeNode) string {
+
+	if node == nil {
+		return "()"
+	}
+
+	parts := []string{node.Symbol}
+
+	if node.Value != "" {
+		parts = append(parts, QuoteSExpression(node.Value))
+	}
+
+	for _, child := range node.Children {
+		if child != nil {
+			parts = append(parts, ConvertTreeToSExpression(child))
+		}
+	}
+
+	return "(" + strings.Join(parts, " ") + ")"
+}
+
+// Name: QuoteSExpression
+//
+// Parameters: string
+//
+// Return: string
+//
+// Quotes a token value for an S-expression, escaping quotes and backslashes
+func QuoteSExpression(value string) string {
+
+	value = strings.ReplaceAll(value, "\\", "\\\\")
+	value = strings.ReplaceAll(value, "\"", "\\\"")
+
+	return "\"" + value + "\""
+}
+
+// Name: ReadSExpression
+//
+// Parameters: string
+//
+// Return: SyntaxTree, error
+//
+// Read a syntax tree from its S-expression. Each node is (SYMBOL "value" children...) where the value is optional
+func ReadSExpression(input string) (SyntaxTree, error) {
+
+	characters := []rune(input)
+
+	position := SkipSpaces(characters, 0)
+	if position >= len(characters) {
+		return SyntaxTree{}, fmt.Errorf("no S-expression entered")
+	}
+
+	root, position, err := ReadSExpressionNode(characters, position)
+	if err != nil {
+		return SyntaxTree{}, err
+	}
+
+	position = SkipSpaces(characters, position)
+	if position < len(characters) {
+		return SyntaxTree{}, fmt.Errorf("unexpected input after S-expression at position %d", position)
+	}
+
+	return SyntaxTree{Root: root}, nil
+}
+
+// Name: ReadSExpressionNode
+//
+// Parameters: []rune, int
+//
+// Return: *TreeNode, int, error
+//
+// Reads one parenthesised node and its children starting at the given position
+func ReadSExpressionNode(characters []rune, position int) (*TreeNode, int, error) {
+
+	if position >= len(characters) || characters[position] != '(' {
+		return nil, position, fmt.Errorf("expected '(' at position %d", position)
+	}
+	position = SkipSpaces(characters, position+1)
+
+	start := position
+	for position < len(characters) && !unicode.IsSpace(characters[position]) && !strings.ContainsRune("()\"", characters[position]) {
+		position++
+	}
+
+	if start == position {
+		return nil, position, fmt.Errorf("expected a symbol at position %d", position)
+	}
+
+	node := &TreeNode{Symbol: string(characters[start:position])}
+	position = SkipSpaces(characters, position)
+
+	if position < len(characters) && characters[position] == '"' {
+		value, next_position, err := ReadSExpressionString(characters, position)
+		if err != nil {
+			return nil, next_position, err
+		}
+		node.Value = value
+		position = SkipSpaces(characters, next_position)
+	}
+
+	for position < len(characters) && characters[position] == '(' {
+		child, next_position, err := ReadSExpressionNode(characters, position)
+		if err != nil {
+			return nil, next_position, err
+		}
+		node.Children = append(node.Children, child)
+		position = SkipSpaces(characters, next_position)
+	}
+
+	if position >= len(characters) || characters[position] != ')' {
+		return nil, position, fmt.Errorf("expected ')' at position %d", position)
+	}
+
+	return node, position + 1, nil
+}
+
+// Name: ReadSExpressionString
+//
+// Parameters: []rune, int
+//
+// Return: string, int, error
+//
+// Reads a quoted token value, removing the escapes added by QuoteSExpression
+func ReadSExpressionString(characters []rune, position int) (string, int, error) {
+
+	var value strings.Builder
+	position++
+
+	for position < len(characters) {
+		switch characters[position] {
+		case '\\':
+			if position+1 >= len(characters) {
+				return "", position, fmt.Errorf("unterminated string at position %d", position)
+			}
+			value.WriteRune(characters[position+1])
+			position += 2
+		case '"':
+			return value.String(), position + 1, nil
+		default:
+			value.WriteRune(characters[position])
+			position++
+		}
+	}
+
+	return "", position, fmt.Errorf("unterminated string at position %d", position)
+}
+
+// Name: SkipSpaces
+//
+// Parameters: []rune, int
+//
+// Return: int
+//
+// Returns the position of the next character that is not whitespace
+func SkipSpaces(characters []rune, position int) int {
+
+	for position < len(characters) && unicode.IsSpace(characters[position]) {
+		position++
+	}
+
+	return position
+}
+
+// Name: ConvertTreeToForest
+//
+// Parameters: *TreeNode
+//
+// Return: string
+//
+// Build a LaTeX forest environment of the syntax tree. Token values are drawn as children of their terminal
+func ConvertTreeToForest(node *TreeNode) string {
+
+	return "\\begin{forest}\n" + WriteBracketNode(node, "[{%s}", "]", "") + "\n\\end{forest}\n"
+}
+
+// Name: ConvertTreeToQtree
+//
+// Parameters: *TreeNode
+//
+// Return: string
+//
+// Build a LaTeX qtree of the syntax tree. Token values are drawn as children of their terminal
+func ConvertTreeToQtree(node *TreeNode) string {
+
+	return "\\Tree " + WriteBracketNode(node, "[.{%s}", " ]", "{%s}") + "\n"
+}
+
+// Name: WriteBracketNode
+//
+// Parameters: *TreeNode, string, string, string
+//
+// Return: string
+//
+// Recursively writes the labelled bracket notation shared by forest and qtree.
+// An empty leaf format writes token values as bracketed nodes
+func WriteBracketNode(node *TreeNode, open_format string, close string, leaf_format string) string {
+
+	if node == nil {
+		return fmt.Sprintf(open_format, "") + close
+	}
+
+	output := fmt.Sprintf(open_format, EscapeLatex(node.Symbol))
+
+	if node.Value != "" {
+		if leaf_format == "" {
+			output += " " + fmt.Sprintf(open_format, EscapeLatex(node.Value)) + close
+		} else {
+			output += " " + fmt.Sprintf(leaf_format, EscapeLatex(node.Value))
+		}
+	}
+
+	for _, child := range node.Children {
+		if child != nil {
+			output += " " + WriteBracketNode(child, open_format, close, leaf_format)
+		}
+	}
+
+	return output + close
+}
+
+// Name: EscapeLatex
+//
+// Parameters: string
+//
+// Return: string
+//
+// Escapes the characters with a special meaning in LaTeX
+func EscapeLatex(text string) string {
+
+	replacements := map[rune]string{
+		'\\': "\\textbackslash{}",
+		'{':  "\\{",
+		'}':  "\\}",
+		'#':  "\\#",
+		'$':  "\\$",
+		'%':  "\\%",
+		'&':  "\\&",
+		'_':  "\\_",
+		'~':  "\\textasciitilde{}",
+		'^':  "\\textasciicircum{}",
+		'[':  "{[}",
+		']':  "{]}",
+	}
+
+	var output strings.Builder
+
+	for _, character := range text {
+		if replacement, special := replacements[character]; special {
+			output.WriteString(replacement)
+		} else {
+			output.WriteRune(character)
+		}
+	}
+
+	return output.String()
+}
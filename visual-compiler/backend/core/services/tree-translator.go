@@ -0,0 +1,319 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Struct for the tree translation rules
+//
+// node is the nonterminal the rule translates and production the symbols of its children. A rule without a
+// production translates every node of the nonterminal that a more specific rule does not
+type TreeTranslationRule struct {
+	Node        string   `json:"node"`
+	Production  []string `json:"production,omitempty"`
+	Translation []string `json:"translation"`
+}
+
+// matches {SYMBOL} and {SYMBOL#n} placeholders, where n counts the children with the symbol from 1, and the
+// escaped braces {{ and }}. A brace followed by a space, such as in { return 0; }, is not a placeholder
+var tree_placeholder = regexp.MustCompile(`\{\{|\}\}|\{([^{}#\s]+)(?:#([0-9]+))?\}`)
+
+// Name: ReadTreeTranslationRules
+//
+// Parameters: []byte
+//
+// Return: []TreeTranslationRule, error
+//
+// Receive an array of tree translation rules and return them in the struct. The placeholders of a rule with a
+// production must refer to a symbol of the production. Rules with a production are placed before the rules
+// without one so that they are tried first
+func ReadTreeTranslationRules(input []byte) ([]TreeTranslationRule, error) {
+
+	rules := []TreeTranslationRule{}
+
+	if len(input) == 0 {
+		return []TreeTranslationRule{}, fmt.Errorf("no tree translation rules entered")
+	}
+
+	err := json.Unmarshal(input, &rules)
+	if err != nil {
+		return []TreeTranslationRule{}, fmt.Errorf("invalid JSON for tree translation rules: %v", err)
+	}
+
+	delimiter := regexp.MustCompile(`[,\s]+`)
+
+	for i, rule := range rules {
+
+		rules[i].Node = strings.TrimSpace(rule.Node)
+		if rules[i].Node == "" {
+			return []TreeTranslationRule{}, fmt.Errorf("tree translation rule %d has no node", i+1)
+		}
+
+		production := []string{}
+		for _, entry := range rule.Production {
+			for _, symbol := range delimiter.Split(strings.TrimSpace(entry), -1) {
+				if symbol != "" {
+					production = append(production, symbol)
+				}
+			}
+		}
+		rules[i].Production = production
+
+		for _, entry := range rule.Translation {
+			for _, match := range tree_placeholder.FindAllStringSubmatch(entry, -1) {
+
+				if match[1] == "" {
+					continue
+				}
+
+				index, err := PlaceholderIndex(match[2])
+				if err != nil {
+					return []TreeTranslationRule{}, fmt.Errorf("token %s in tree translation rule for %s: %v", match[0], rules[i].Node, err)
+				}
+
+				if len(production) > 0 && CountSymbol(production, match[1]) < index {
+					return []TreeTranslationRule{}, fmt.Errorf("token %s in tree translation rule not found in production: %s -> %v", match[0], rules[i].Node, production)
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(rules, func(i, j int) bool {
+		return len(rules[i].Production) > 0 && len(rules[j].Production) == 0
+	})
+
+	return rules, nil
+}
+
+// Name: TranslateTree
+//
+// Parameters: SyntaxTree, []TreeTranslationRule
+//
+// Return: []string, error
+//
+// Converts the syntax tree to the target code by translating each node with the rule for its production.
+// The translation of a child is inserted where the template holds its placeholder, so nested nodes are
+// translated before the node holding them
+func TranslateTree(tree SyntaxTree, rules []TreeTranslationRule) ([]string, error) {
+
+	if tree.Root == nil {
+		return []string{}, fmt.Errorf("empty syntax tree")
+	}
+
+	return TranslateTreeNode(tree.Root, rules)
+}
+
+// Name: TranslateTreeNode
+//
+// Parameters: *TreeNode, []TreeTranslationRule
+//
+// Return: []string, error
+//
+// Translates a node of the syntax tree. A leaf translates to its value and a node without a rule and a single
+// child to the translation of its child. Any other node without a rule cannot be translated
+func TranslateTreeNode(node *TreeNode, rules []TreeTranslationRule) ([]string, error) {
+
+	rule := FindTreeTranslationRule(node, rules)
+
+	if rule == nil {
+		if len(node.Children) == 0 {
+			return []string{node.Value}, nil
+		}
+		if len(node.Children) == 1 {
+			return TranslateTreeNode(node.Children[0], rules)
+		}
+		return nil, fmt.Errorf("no tree translation rule for %s -> %v", node.Symbol, ChildSymbols(node))
+	}
+
+	translations := make(map[string][]string)
+	result := []string{}
+
+	for _, template := range rule.Translation {
+
+		var err error
+		inserted := []string{}
+
+		line := tree_placeholder.ReplaceAllStringFunc(template, func(placeholder string) string {
+
+			if err != nil {
+				return placeholder
+			}
+
+			match := tree_placeholder.FindStringSubmatch(placeholder)
+			if match[1] == "" {
+				// an escaped brace stands for a single brace
+				return placeholder[:1]
+			}
+
+			index, _ := PlaceholderIndex(match[2])
+			key := match[1] + "#" + strconv.Itoa(index)
+
+			translation, found := translations[key]
+			if !found {
+				child := FindChild(node, match[1], index)
+				if child == nil {
+					err = fmt.Errorf("token %s in tree translation rule not found in production: %s -> %v", placeholder, node.Symbol, ChildSymbols(node))
+					return placeholder
+				}
+
+				translation, err = TranslateTreeNode(child, rules)
+				if err != nil {
+					return placeholder
+				}
+				translations[key] = translation
+			}
+
+			if strings.TrimSpace(template) == placeholder {
+				inserted = translation
+			}
+
+			return strings.Join(translation, " ")
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		if len(inserted) > 1 {
+			// a placeholder on a line of its own keeps the lines of the child, indented like the placeholder
+			indent := template[:strings.Index(template, strings.TrimSpace(template))]
+			for _, child_line := range inserted {
+				result = append(result, indent+child_line)
+			}
+			continue
+		}
+
+		result = append(result, line)
+	}
+
+	return result, nil
+}
+
+// Name: FindTreeTranslationRule
+//
+// Parameters: *TreeNode, []TreeTranslationRule
+//
+// Return: *TreeTranslationRule
+//
+// Finds the first rule for the node whose production matches the symbols of its children
+func FindTreeTranslationRule(node *TreeNode, rules []TreeTranslationRule) *TreeTranslationRule {
+
+	children := ChildSymbols(node)
+
+	for i, rule := range rules {
+
+		if rule.Node != node.Symbol {
+			continue
+		}
+
+		if len(rule.Production) == 0 {
+			return &rules[i]
+		}
+
+		if len(rule.Production) != len(children) {
+			continue
+		}
+
+		matched := true
+		for j, symbol := range rule.Production {
+			if children[j] != symbol {
+				matched = false
+				break
+			}
+		}
+
+		if matched {
+			return &rules[i]
+		}
+	}
+
+	return nil
+}
+
+// Name: FindChild
+//
+// Parameters: *TreeNode, string, int
+//
+// Return: *TreeNode
+//
+// Finds the child with the symbol at the position among the children with that symbol, counted from 1
+func FindChild(node *TreeNode, symbol string, index int) *TreeNode {
+
+	count := 0
+
+	for _, child := range node.Children {
+		if child.Symbol == symbol {
+			count++
+			if count == index {
+				return child
+			}
+		}
+	}
+
+	return nil
+}
+
+// Name: ChildSymbols
+//
+// Parameters: *TreeNode
+//
+// Return: []string
+//
+// Lists the symbols of the children of the node
+func ChildSymbols(node *TreeNode) []string {
+
+	symbols := []string{}
+
+	for _, child := range node.Children {
+		symbols = append(symbols, child.Symbol)
+	}
+
+	return symbols
+}
+
+// Name: CountSymbol
+//
+// Parameters: []string, string
+//
+// Return: int
+//
+// Counts how often the symbol appears in the list of symbols
+func CountSymbol(symbols []string, symbol string) int {
+
+	count := 0
+
+	for _, entry := range symbols {
+		if entry == symbol {
+			count++
+		}
+	}
+
+	return count
+}
+
+// Name: PlaceholderIndex
+//
+// Parameters: string
+//
+// Return: int, error
+//
+// Reads the position written after the # of a placeholder. A placeholder without a position refers to the
+// first child with its symbol
+func PlaceholderIndex(text string) (int, error) {
+
+	if text == "" {
+		return 1, nil
+	}
+
+	index, err := strconv.Atoi(text)
+	if err != nil || index < 1 {
+		return 0, fmt.Errorf("position must be at least 1")
+	}
+
+	return index, nil
+}
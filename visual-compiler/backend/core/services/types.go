@@ -0,0 +1,579 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Kinds of the types of the type system
+const (
+	type_basic    = "basic"
+	type_array    = "array"
+	type_record   = "record"
+	type_function = "function"
+)
+
+// Type of the values that can be used to index an array
+const index_type = "int"
+
+// struct to store a type of the type system.
+//
+// kind is basic, array, record or function. Basic types and records have a name, arrays an element type and a
+// length, which is -1 when it is not given, records their fields and functions their parameter and result types
+type Type struct {
+	Kind       string  `json:"kind"`
+	Name       string  `json:"name,omitempty"`
+	Element    *Type   `json:"element,omitempty"`
+	Length     int     `json:"length,omitempty"`
+	Fields     []Field `json:"fields,omitempty"`
+	Parameters []*Type `json:"parameters,omitempty"`
+	Result     *Type   `json:"result,omitempty"`
+}
+
+// struct to store a named field of a record type
+type Field struct {
+	Name string `json:"name"`
+	Type *Type  `json:"type"`
+}
+
+// struct to store a rule building a composite type from a node of the syntax tree.
+//
+// kind is array, record or function. The children of an array node are its element type and an integer leaf with
+// its length, and the children of a function node its parameter types followed by its result type. A record node
+// declares a record type named by its name node, with a field for every node below it declaring a name with a type
+type TypeConstructor struct {
+	Node string `json:"node"`
+	Kind string `json:"kind"`
+}
+
+// Name: BasicType
+//
+// Parameters: string
+//
+// Return: *Type
+//
+// Creates a type without structure, such as int
+func BasicType(name string) *Type {
+
+	return &Type{Kind: type_basic, Name: name}
+}
+
+// Name: ArrayType
+//
+// Parameters: *Type, int
+//
+// Return: *Type
+//
+// Creates the type of an array of the element type. A length of -1 leaves the length open
+func ArrayType(element *Type, length int) *Type {
+
+	return &Type{Kind: type_array, Element: element, Length: length}
+}
+
+// Name: RecordType
+//
+// Parameters: string, []Field
+//
+// Return: *Type
+//
+// Creates the type of a record with the given fields. Anonymous records have an empty name
+func RecordType(name string, fields []Field) *Type {
+
+	return &Type{Kind: type_record, Name: name, Fields: fields}
+}
+
+// Name: FunctionType
+//
+// Parameters: []*Type, *Type
+//
+// Return: *Type
+//
+// Creates the type of a function taking the parameter types and returning the result type
+func FunctionType(parameters []*Type, result *Type) *Type {
+
+	return &Type{Kind: type_function, Parameters: parameters, Result: result}
+}
+
+// Name: TypeString
+//
+// Parameters: *Type
+//
+// Return: string
+//
+// Returns the full type, such as int[10], Point{x: int, y: int} or func(int, float) int
+func TypeString(full_type *Type) string {
+
+	return FormatType(full_type, true, false)
+}
+
+// Name: TypeKey
+//
+// Parameters: *Type, bool
+//
+// Return: string
+//
+// Returns the type as it is compared by the analyser. Records are compared by name, unless structural is set and
+// records with the same fields are the same type. Two types are equal when their keys are
+func TypeKey(full_type *Type, structural bool) string {
+
+	return FormatType(full_type, false, structural)
+}
+
+// Name: TypesEqual
+//
+// Parameters: *Type, *Type, bool
+//
+// Return: bool
+//
+// Determines if two types are the same, comparing records by name or, when structural is set, by their fields
+func TypesEqual(first *Type, second *Type, structural bool) bool {
+
+	return TypeKey(first, structural) == TypeKey(second, structural)
+}
+
+// Name: FormatType
+//
+// Parameters: *Type, bool, bool
+//
+// Return: string
+//
+// Writes out the type. Full writes the fields of named records, and structural leaves out the names of records
+func FormatType(full_type *Type, full bool, structural bool) string {
+
+	if full_type == nil {
+		return ""
+	}
+
+	switch full_type.Kind {
+
+	case type_array:
+		length := ""
+		if full_type.Length >= 0 {
+			length = strconv.Itoa(full_type.Length)
+		}
+		return fmt.Sprintf("%v[%v]", FormatType(full_type.Element, full, structural), length)
+
+	case type_record:
+		if !full && !structural && full_type.Name != "" {
+			return full_type.Name
+		}
+
+		fields := []string{}
+		for _, field := range full_type.Fields {
+			fields = append(fields, fmt.Sprintf("%v: %v", field.Name, FormatType(field.Type, full, structural)))
+		}
+
+		name := full_type.Name
+		if structural && !full {
+			name = ""
+		}
+		return fmt.Sprintf("%v{%v}", name, strings.Join(fields, ", "))
+
+	case type_function:
+		parameters := []string{}
+		for _, parameter := range full_type.Parameters {
+			parameters = append(parameters, FormatType(parameter, full, structural))
+		}
+
+		result := ""
+		if full_type.Result != nil {
+			result = " " + FormatType(full_type.Result, full, structural)
+		}
+		return fmt.Sprintf("func(%v)%v", strings.Join(parameters, ", "), result)
+	}
+
+	return full_type.Name
+}
+
+// Name: FindTypeConstructor
+//
+// Parameters: *TreeNode, GrammarRules
+//
+// Return: *TypeConstructor
+//
+// Returns the type constructor rule of the node, or nil when no rule builds a type from it
+func FindTypeConstructor(node *TreeNode, rules GrammarRules) *TypeConstructor {
+
+	for i, constructor := range rules.TypeConstructors {
+		if constructor.Node == node.Symbol {
+			return &rules.TypeConstructors[i]
+		}
+	}
+
+	return nil
+}
+
+// Name: IsTypeNode
+//
+// Parameters: *TreeNode, GrammarRules
+//
+// Return: bool
+//
+// Determines if the node names a type, either as a type node or as an array or function type constructor
+func IsTypeNode(node *TreeNode, rules GrammarRules) bool {
+
+	if node.Symbol == rules.TypeRule {
+		return true
+	}
+
+	constructor := FindTypeConstructor(node, rules)
+
+	return constructor != nil && constructor.Kind != type_record
+}
+
+// Name: LeafValue
+//
+// Parameters: *TreeNode
+//
+// Return: string
+//
+// Returns the value of the node, or of its first leaf when the node has no value
+func LeafValue(node *TreeNode) string {
+
+	for node.Value == "" && len(node.Children) > 0 {
+		node = node.Children[0]
+	}
+
+	return node.Value
+}
+
+// Name: ResolveType
+//
+// Parameters: *TreeNode, *SymbolTable, GrammarRules
+//
+// Return: *Type, error
+//
+// Builds the type named by a type node or a type constructor node. Type nodes name a declared record or a basic type
+func ResolveType(node *TreeNode, symbol_table *SymbolTable, rules GrammarRules) (*Type, error) {
+
+	if node.Symbol == rules.TypeRule {
+		name := LeafValue(node)
+		if record, found := symbol_table.Types[name]; found {
+			return record, nil
+		}
+		return BasicType(name), nil
+	}
+
+	constructor := FindTypeConstructor(node, rules)
+	if constructor == nil {
+		return nil, fmt.Errorf("error: %v does not name a type", node.Symbol)
+	}
+
+	switch constructor.Kind {
+
+	case type_array:
+		var element *Type
+		length := -1
+
+		for _, child := range node.Children {
+			if element == nil && IsTypeNode(child, rules) {
+				child_type, err := ResolveType(child, symbol_table, rules)
+				if err != nil {
+					return nil, err
+				}
+				element = child_type
+			} else if child.Symbol == rules.VariableRule || len(child.Children) > 0 {
+				// the length can be a constant expression, but not a name whose value can change
+				constant, err := FoldNode(child, symbol_table, rules, symbol_table.TypeRules)
+				if err != nil {
+					return nil, err
+				}
+				if constant == nil {
+					return nil, fmt.Errorf("error: array size is not constant: %v", ExpressionText([]*TreeNode{child}))
+				}
+				if !constant.Integral || constant.Value < 0 {
+					return nil, fmt.Errorf("error: array size must be a non-negative integer: %v", FormatConstant(constant))
+				}
+				length = int(constant.Value)
+			} else if value, err := strconv.Atoi(child.Value); err == nil {
+				length = value
+			}
+		}
+
+		if element == nil {
+			return nil, fmt.Errorf("error: array type has no element type: %v", ExpressionText([]*TreeNode{node}))
+		}
+		return ArrayType(element, length), nil
+
+	case type_function:
+		types := []*Type{}
+
+		for _, child := range node.Children {
+			if IsTypeNode(child, rules) {
+				child_type, err := ResolveType(child, symbol_table, rules)
+				if err != nil {
+					return nil, err
+				}
+				types = append(types, child_type)
+			}
+		}
+
+		if len(types) == 0 {
+			return nil, fmt.Errorf("error: function type has no result type: %v", ExpressionText([]*TreeNode{node}))
+		}
+		return FunctionType(types[:len(types)-1], types[len(types)-1]), nil
+
+	case type_record:
+		return CreateRecordType(node, symbol_table, rules)
+	}
+
+	return nil, fmt.Errorf("error: unknown type constructor kind: %v", constructor.Kind)
+}
+
+// Name: ApplyDeclaredType
+//
+// Parameters: *Symbol, *TreeNode, *SymbolTable, GrammarRules
+//
+// Return: error
+//
+// Gives the symbol the type named by the type node. The full type of arrays, records and functions is kept as the
+// type info of the symbol
+func ApplyDeclaredType(symbol *Symbol, type_node *TreeNode, symbol_table *SymbolTable, rules GrammarRules) error {
+
+	declared_type, err := ResolveType(type_node, symbol_table, rules)
+	if err != nil {
+		return err
+	}
+
+	symbol.Type = TypeKey(declared_type, symbol_table.StructuralTypes)
+	if declared_type.Kind != type_basic {
+		symbol.TypeInfo = declared_type
+	}
+
+	return nil
+}
+
+// Name: CreateRecordType
+//
+// Parameters: *TreeNode, *SymbolTable, GrammarRules
+//
+// Return: *Type, error
+//
+// Builds the record type declared by a record node, named by its name node, with the fields declared below it
+func CreateRecordType(record_node *TreeNode, symbol_table *SymbolTable, rules GrammarRules) (*Type, error) {
+
+	name := ""
+	fields := []Field{}
+
+	for _, child := range record_node.Children {
+		if child.Symbol == rules.VariableRule {
+			name = LeafValue(child)
+			continue
+		}
+
+		child_fields, err := FindRecordFields(child, symbol_table, rules)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, field := range child_fields {
+			for _, declared := range fields {
+				if declared.Name == field.Name {
+					return nil, fmt.Errorf("error: field %v declared more than once in record %v", field.Name, name)
+				}
+			}
+			fields = append(fields, field)
+		}
+	}
+
+	return RecordType(name, fields), nil
+}
+
+// Name: FindRecordFields
+//
+// Parameters: *TreeNode, *SymbolTable, GrammarRules
+//
+// Return: []Field, error
+//
+// Finds the fields declared by the node and its descendants. A node declares a field when it has both a type node
+// and a name node as children
+func FindRecordFields(node *TreeNode, symbol_table *SymbolTable, rules GrammarRules) ([]Field, error) {
+
+	var type_node, name_node *TreeNode
+
+	for _, child := range node.Children {
+		if type_node == nil && IsTypeNode(child, rules) {
+			type_node = child
+		} else if child.Symbol == rules.VariableRule {
+			name_node = child
+		}
+	}
+
+	if type_node != nil && name_node != nil {
+		field_type, err := ResolveType(type_node, symbol_table, rules)
+		if err != nil {
+			return nil, err
+		}
+		return []Field{{Name: LeafValue(name_node), Type: field_type}}, nil
+	}
+
+	fields := []Field{}
+	for _, child := range node.Children {
+		child_fields, err := FindRecordFields(child, symbol_table, rules)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, child_fields...)
+	}
+
+	return fields, nil
+}
+
+// Name: DeclareRecord
+//
+// Parameters: *TreeNode, *SymbolTable, *SymbolTableArtefact, GrammarRules
+//
+// Return: none
+//
+// Declares the record type of a record node, so that type nodes can name it, and adds it to the artefact.
+// Records a diagnostic when the record is invalid or its name is already a declared type
+func DeclareRecord(record_node *TreeNode, symbol_table *SymbolTable, symbol_table_artefact *SymbolTableArtefact, rules GrammarRules) {
+
+	record, err := CreateRecordType(record_node, symbol_table, rules)
+	if err != nil {
+		RecordDiagnostic(symbol_table, code_invalid_type, "", record_node, err.Error())
+		return
+	}
+
+	if record.Name == "" {
+		RecordDiagnostic(symbol_table, code_missing_name, "", record_node, "error: record has no name defined")
+		return
+	}
+
+	if _, found := symbol_table.Types[record.Name]; found {
+		RecordDiagnostic(symbol_table, code_redeclared_symbol, record.Name, record_node, fmt.Sprintf("error: type already declared: %v", record.Name))
+		return
+	}
+
+	symbol_table.Types[record.Name] = record
+	symbol_table_artefact.Types = append(symbol_table_artefact.Types, record)
+}
+
+// Name: SymbolType
+//
+// Parameters: Symbol
+//
+// Return: *Type
+//
+// Returns the full type of a symbol, which is a basic type when the symbol has no composite type
+func SymbolType(symbol Symbol) *Type {
+
+	if symbol.TypeInfo != nil {
+		return symbol.TypeInfo
+	}
+
+	return BasicType(symbol.Type)
+}
+
+// Name: IsAccessNode
+//
+// Parameters: *TreeNode, GrammarRules
+//
+// Return: bool
+//
+// Determines if the node indexes an array or accesses a field of a record
+func IsAccessNode(node *TreeNode, rules GrammarRules) bool {
+
+	return (rules.IndexRule != "" && node.Symbol == rules.IndexRule) || (rules.FieldRule != "" && node.Symbol == rules.FieldRule)
+}
+
+// Name: AccessType
+//
+// Parameters: *TreeNode, *SymbolTable, GrammarRules, []TypeRule
+//
+// Return: *Type, error
+//
+// Computes the type of a name, an indexed array element or a record field. The indexed value must be an array and
+// the index an int within the length of the array, and the accessed value must be a record with the field. Returns
+// nil without an error when the type is unknown, such as for an undeclared name or an access whose base is invalid
+func AccessType(node *TreeNode, symbol_table *SymbolTable, rules GrammarRules, type_rules []TypeRule) (*Type, error) {
+
+	if !IsAccessNode(node, rules) {
+		for node.Value == "" && len(node.Children) == 1 && !IsAccessNode(node, rules) {
+			node = node.Children[0]
+		}
+
+		if IsAccessNode(node, rules) {
+			return AccessType(node, symbol_table, rules, type_rules)
+		}
+		if node.Symbol != rules.VariableRule {
+			return nil, nil
+		}
+
+		symbol, err := LookupName(symbol_table, node.Value)
+		if err != nil {
+			return nil, nil
+		}
+		return SymbolType(symbol), nil
+	}
+
+	if len(node.Children) == 0 {
+		return nil, nil
+	}
+
+	base := node.Children[0]
+	base_type, err := AccessType(base, symbol_table, rules, type_rules)
+	if err != nil || base_type == nil {
+		// an invalid base is reported at the base itself
+		return nil, nil
+	}
+
+	if node.Symbol == rules.IndexRule {
+		if base_type.Kind != type_array {
+			return nil, fmt.Errorf("error: %v is not an array: %v", ExpressionText([]*TreeNode{base}), TypeString(base_type))
+		}
+
+		// the index is made up by the children with a type, leaving out brackets
+		index := []*TreeNode{}
+		for _, child := range node.Children[1:] {
+			if child_types, _ := FindNodeTypes(child, symbol_table, rules, type_rules); child_types != nil {
+				index = append(index, child)
+			}
+		}
+
+		index_types, err := ExpressionTypes(index, symbol_table, rules, type_rules)
+		if err != nil {
+			return nil, err
+		}
+
+		valid_index := len(index_types) == 0
+		for _, value_type := range index_types {
+			if value_type == "" || IsAssignable(index_type, value_type, type_rules, symbol_table.Promotions) {
+				valid_index = true
+			}
+		}
+		if !valid_index {
+			return nil, fmt.Errorf("error: index of %v must be %v but is %v", ExpressionText([]*TreeNode{base}), index_type, strings.Join(index_types, "|"))
+		}
+
+		constant, err := FoldConstant(index, symbol_table, rules, type_rules)
+		if err != nil {
+			return nil, err
+		}
+		if constant != nil && constant.Integral && base_type.Length >= 0 && (constant.Value < 0 || int(constant.Value) >= base_type.Length) {
+			return nil, fmt.Errorf("error: index %v out of range for %v: %v", FormatConstant(constant), ExpressionText([]*TreeNode{base}), TypeString(base_type))
+		}
+
+		return base_type.Element, nil
+	}
+
+	if base_type.Kind != type_record {
+		return nil, fmt.Errorf("error: %v is not a record: %v", ExpressionText([]*TreeNode{base}), TypeString(base_type))
+	}
+
+	field_name := ""
+	for _, child := range node.Children[1:] {
+		if child.Symbol == rules.VariableRule {
+			field_name = LeafValue(child)
+		}
+	}
+
+	for _, field := range base_type.Fields {
+		if field.Name == field_name {
+			return field.Type, nil
+		}
+	}
+
+	return nil, fmt.Errorf("error: %v has no field %v: %v", ExpressionText([]*TreeNode{base}), field_name, TypeString(base_type))
+}
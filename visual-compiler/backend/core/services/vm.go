@@ -0,0 +1,611 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// struct to store an instruction of the stack machine.
+//
+// line is the line of the assembly the instruction was read from
+type VMInstruction struct {
+	Opcode  string `json:"opcode"`
+	Operand string `json:"operand,omitempty"`
+	Line    int    `json:"line"`
+}
+
+// struct to store an assembled program of the stack machine with the position of each label
+type VMProgram struct {
+	Instructions []VMInstruction `json:"instructions"`
+	Labels       map[string]int  `json:"labels"`
+}
+
+// struct to store a value of the stack machine, which is a whole number when it is integral
+type VMValue struct {
+	Value    float64
+	Integral bool
+}
+
+// struct to store the state of the machine before an instruction is executed
+type VMTraceEntry struct {
+	Step        int               `json:"step"`
+	Counter     int               `json:"counter"`
+	Instruction string            `json:"instruction"`
+	Stack       []string          `json:"stack"`
+	Variables   map[string]string `json:"variables"`
+}
+
+// struct to store the outcome of running a program.
+//
+// variables are the global variables, and halted is false when the program stopped because of an error or
+// the instruction limit. trace truncated is set when the program ran more instructions than the trace holds
+type VMResult struct {
+	Stdout         string            `json:"stdout"`
+	Stack          []string          `json:"stack"`
+	Variables      map[string]string `json:"variables"`
+	Steps          int               `json:"steps"`
+	Halted         bool              `json:"halted"`
+	Trace          []VMTraceEntry    `json:"trace,omitempty"`
+	TraceTruncated bool              `json:"trace_truncated,omitempty"`
+}
+
+// struct to store the variables and return position of a function call
+type VMFrame struct {
+	ReturnCounter int
+	Variables     map[string]VMValue
+}
+
+// Default number of instructions a program may execute
+const VMInstructionLimit = 100000
+
+// Most instructions recorded in the trace of a program
+const VMTraceLimit = 1000
+
+// Operands each instruction of the stack machine takes: a number, a name, a label or none
+var vm_operands = map[string]string{
+	"push":  "number",
+	"load":  "name",
+	"store": "name",
+	"local": "name",
+	"pop":   "",
+	"dup":   "",
+	"add":   "",
+	"sub":   "",
+	"mul":   "",
+	"div":   "",
+	"mod":   "",
+	"neg":   "",
+	"eq":    "",
+	"ne":    "",
+	"lt":    "",
+	"le":    "",
+	"gt":    "",
+	"ge":    "",
+	"jmp":   "label",
+	"jz":    "label",
+	"jnz":   "label",
+	"call":  "label",
+	"ret":   "",
+	"read":  "",
+	"print": "",
+	"halt":  "",
+}
+
+// Instructions of the stack machine for the arithmetic and comparison operators of the intermediate representation
+var vm_operators = map[string]string{
+	"+":  "add",
+	"-":  "sub",
+	"*":  "mul",
+	"/":  "div",
+	"%":  "mod",
+	"==": "eq",
+	"!=": "ne",
+	"<":  "lt",
+	"<=": "le",
+	">":  "gt",
+	">=": "ge",
+}
+
+// Name: AssembleVM
+//
+// Parameters: []string
+//
+// Return: VMProgram, error
+//
+// Reads the assembly of a stack machine program. Each line holds an instruction and its operand, or a label
+// ending in a colon. Text after a # is a comment. Returns an error for unknown instructions, missing or
+// invalid operands, labels declared more than once and jumps to undeclared labels
+func AssembleVM(source []string) (VMProgram, error) {
+
+	program := VMProgram{Instructions: []VMInstruction{}, Labels: make(map[string]int)}
+
+	for i, text := range source {
+
+		line := i + 1
+
+		if comment := strings.Index(text, "#"); comment >= 0 {
+			text = text[:comment]
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if strings.HasSuffix(fields[0], ":") {
+			label := strings.TrimSuffix(fields[0], ":")
+			if label == "" {
+				return VMProgram{}, fmt.Errorf("line %v: label has no name", line)
+			}
+			if _, found := program.Labels[label]; found {
+				return VMProgram{}, fmt.Errorf("line %v: label declared more than once: %v", line, label)
+			}
+			program.Labels[label] = len(program.Instructions)
+
+			fields = fields[1:]
+			if len(fields) == 0 {
+				continue
+			}
+		}
+
+		opcode := strings.ToLower(fields[0])
+		operand_kind, found := vm_operands[opcode]
+		if !found {
+			return VMProgram{}, fmt.Errorf("line %v: unknown instruction: %v", line, fields[0])
+		}
+
+		instruction := VMInstruction{Opcode: opcode, Line: line}
+
+		switch {
+		case operand_kind == "" && len(fields) > 1:
+			return VMProgram{}, fmt.Errorf("line %v: %v takes no operand", line, opcode)
+		case operand_kind != "" && len(fields) != 2:
+			return VMProgram{}, fmt.Errorf("line %v: %v takes one operand", line, opcode)
+		case operand_kind == "number":
+			if _, err := ParseVMValue(fields[1]); err != nil {
+				return VMProgram{}, fmt.Errorf("line %v: %v", line, err)
+			}
+			instruction.Operand = fields[1]
+		case operand_kind != "":
+			instruction.Operand = fields[1]
+		}
+
+		program.Instructions = append(program.Instructions, instruction)
+	}
+
+	for _, instruction := range program.Instructions {
+		if vm_operands[instruction.Opcode] == "label" {
+			if _, found := program.Labels[instruction.Operand]; !found {
+				return VMProgram{}, fmt.Errorf("line %v: label not declared: %v", instruction.Line, instruction.Operand)
+			}
+		}
+	}
+
+	return program, nil
+}
+
+// Name: RunVM
+//
+// Parameters: VMProgram, string, int, bool
+//
+// Return: VMResult, error
+//
+// Executes the program until it halts, returns from the outermost code or runs past its last instruction.
+// Each call has its own variables: store assigns the global variable with the name when the call has none, and
+// local always assigns a variable of the call. read takes the next whitespace-separated number from stdin and
+// print writes the top of the stack as a line of stdout. The program stops with an error after the instruction
+// limit, or the default limit when it is not positive. The result holds the state of the machine when it
+// stopped, with the state before each of the first instructions, up to the trace limit, when trace is set
+func RunVM(program VMProgram, stdin string, limit int, trace bool) (VMResult, error) {
+
+	if limit <= 0 {
+		limit = VMInstructionLimit
+	}
+
+	globals := &VMFrame{ReturnCounter: -1, Variables: make(map[string]VMValue)}
+	frames := []*VMFrame{globals}
+	stack := []VMValue{}
+	input := strings.Fields(stdin)
+	var stdout strings.Builder
+
+	result := VMResult{}
+	counter := 0
+	var err error
+
+	for counter < len(program.Instructions) && err == nil {
+
+		if result.Steps >= limit {
+			err = fmt.Errorf("instruction limit of %v reached", limit)
+			break
+		}
+
+		instruction := program.Instructions[counter]
+		frame := frames[len(frames)-1]
+
+		if trace && len(result.Trace) >= VMTraceLimit {
+			result.TraceTruncated = true
+		} else if trace {
+			result.Trace = append(result.Trace, VMTraceEntry{
+				Step:        result.Steps + 1,
+				Counter:     counter,
+				Instruction: strings.TrimSpace(instruction.Opcode + " " + instruction.Operand),
+				Stack:       FormatVMStack(stack),
+				Variables:   FormatVMVariables(frame.Variables),
+			})
+		}
+
+		result.Steps++
+		counter++
+
+		switch instruction.Opcode {
+		case "push":
+			value, _ := ParseVMValue(instruction.Operand)
+			stack = append(stack, value)
+		case "load":
+			value, found := frame.Variables[instruction.Operand]
+			if !found {
+				value, found = globals.Variables[instruction.Operand]
+			}
+			if !found {
+				err = fmt.Errorf("variable not assigned: %v", instruction.Operand)
+				break
+			}
+			stack = append(stack, value)
+		case "store":
+			var value VMValue
+			if stack, value, err = PopVMValue(stack); err != nil {
+				break
+			}
+			target := frame
+			if _, found := frame.Variables[instruction.Operand]; !found {
+				if _, found := globals.Variables[instruction.Operand]; found {
+					target = globals
+				}
+			}
+			target.Variables[instruction.Operand] = value
+		case "local":
+			var value VMValue
+			if stack, value, err = PopVMValue(stack); err != nil {
+				break
+			}
+			frame.Variables[instruction.Operand] = value
+		case "pop":
+			stack, _, err = PopVMValue(stack)
+		case "dup":
+			if len(stack) == 0 {
+				err = fmt.Errorf("stack is empty")
+				break
+			}
+			stack = append(stack, stack[len(stack)-1])
+		case "neg":
+			var value VMValue
+			if stack, value, err = PopVMValue(stack); err != nil {
+				break
+			}
+			stack = append(stack, VMValue{Value: -value.Value, Integral: value.Integral})
+		case "add", "sub", "mul", "div", "mod", "eq", "ne", "lt", "le", "gt", "ge":
+			var lhs, rhs, value VMValue
+			if stack, rhs, err = PopVMValue(stack); err != nil {
+				break
+			}
+			if stack, lhs, err = PopVMValue(stack); err != nil {
+				break
+			}
+			if value, err = ApplyVMOperator(instruction.Opcode, lhs, rhs); err != nil {
+				break
+			}
+			stack = append(stack, value)
+		case "jmp":
+			counter = program.Labels[instruction.Operand]
+		case "jz", "jnz":
+			var value VMValue
+			if stack, value, err = PopVMValue(stack); err != nil {
+				break
+			}
+			if (value.Value == 0) == (instruction.Opcode == "jz") {
+				counter = program.Labels[instruction.Operand]
+			}
+		case "call":
+			frames = append(frames, &VMFrame{ReturnCounter: counter, Variables: make(map[string]VMValue)})
+			counter = program.Labels[instruction.Operand]
+		case "ret":
+			if len(frames) == 1 {
+				counter = len(program.Instructions)
+				result.Halted = true
+				break
+			}
+			counter = frame.ReturnCounter
+			frames = frames[:len(frames)-1]
+		case "read":
+			if len(input) == 0 {
+				err = fmt.Errorf("no input left to read")
+				break
+			}
+			var value VMValue
+			if value, err = ParseVMValue(input[0]); err != nil {
+				err = fmt.Errorf("invalid input: %v", input[0])
+				break
+			}
+			input = input[1:]
+			stack = append(stack, value)
+		case "print":
+			var value VMValue
+			if stack, value, err = PopVMValue(stack); err != nil {
+				break
+			}
+			stdout.WriteString(FormatVMValue(value))
+			stdout.WriteString("\n")
+		case "halt":
+			counter = len(program.Instructions)
+			result.Halted = true
+		}
+
+		if err != nil {
+			err = fmt.Errorf("line %v: %v: %v", instruction.Line, strings.TrimSpace(instruction.Opcode+" "+instruction.Operand), err)
+		}
+	}
+
+	if err == nil {
+		result.Halted = true
+	}
+
+	result.Stdout = stdout.String()
+	result.Stack = FormatVMStack(stack)
+	result.Variables = FormatVMVariables(globals.Variables)
+
+	return result, err
+}
+
+// Name: ApplyVMOperator
+//
+// Parameters: string, VMValue, VMValue
+//
+// Return: VMValue, error
+//
+// Applies an arithmetic or comparison instruction to two values. Arithmetic on two integral values is integer
+// arithmetic, so division truncates. Comparisons give 1 when they hold and 0 otherwise
+func ApplyVMOperator(opcode string, lhs VMValue, rhs VMValue) (VMValue, error) {
+
+	integral := lhs.Integral && rhs.Integral
+	value := 0.0
+
+	switch opcode {
+	case "add":
+		value = lhs.Value + rhs.Value
+	case "sub":
+		value = lhs.Value - rhs.Value
+	case "mul":
+		value = lhs.Value * rhs.Value
+	case "div":
+		if rhs.Value == 0 {
+			return VMValue{}, fmt.Errorf("division by zero")
+		}
+		value = lhs.Value / rhs.Value
+		if integral {
+			value = math.Trunc(value)
+		}
+	case "mod":
+		if rhs.Value == 0 {
+			return VMValue{}, fmt.Errorf("modulo by zero")
+		}
+		value = math.Mod(lhs.Value, rhs.Value)
+	default:
+		holds := false
+		switch opcode {
+		case "eq":
+			holds = lhs.Value == rhs.Value
+		case "ne":
+			holds = lhs.Value != rhs.Value
+		case "lt":
+			holds = lhs.Value < rhs.Value
+		case "le":
+			holds = lhs.Value <= rhs.Value
+		case "gt":
+			holds = lhs.Value > rhs.Value
+		case "ge":
+			holds = lhs.Value >= rhs.Value
+		}
+		if holds {
+			value = 1
+		}
+		integral = true
+	}
+
+	return VMValue{Value: value, Integral: integral}, nil
+}
+
+// Name: PopVMValue
+//
+// Parameters: []VMValue
+//
+// Return: []VMValue, VMValue, error
+//
+// Removes the top value of the stack. Returns an error when the stack is empty
+func PopVMValue(stack []VMValue) ([]VMValue, VMValue, error) {
+
+	if len(stack) == 0 {
+		return stack, VMValue{}, fmt.Errorf("stack is empty")
+	}
+
+	return stack[:len(stack)-1], stack[len(stack)-1], nil
+}
+
+// Name: ParseVMValue
+//
+// Parameters: string
+//
+// Return: VMValue, error
+//
+// Reads a number, which is integral when it is written without a decimal point
+func ParseVMValue(text string) (VMValue, error) {
+
+	if value, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return VMValue{Value: float64(value), Integral: true}, nil
+	}
+
+	if value, err := strconv.ParseFloat(text, 64); err == nil {
+		return VMValue{Value: value}, nil
+	}
+
+	return VMValue{}, fmt.Errorf("not a number: %v", text)
+}
+
+// Name: FormatVMValue
+//
+// Parameters: VMValue
+//
+// Return: string
+//
+// Writes out a value of the stack machine
+func FormatVMValue(value VMValue) string {
+
+	return FormatConstant(&ConstantValue{Value: value.Value, Integral: value.Integral})
+}
+
+// Name: FormatVMStack
+//
+// Parameters: []VMValue
+//
+// Return: []string
+//
+// Writes out the values of the stack from the bottom to the top
+func FormatVMStack(stack []VMValue) []string {
+
+	values := []string{}
+
+	for _, value := range stack {
+		values = append(values, FormatVMValue(value))
+	}
+
+	return values
+}
+
+// Name: FormatVMVariables
+//
+// Parameters: map[string]VMValue
+//
+// Return: map[string]string
+//
+// Writes out the values of the variables
+func FormatVMVariables(variables map[string]VMValue) map[string]string {
+
+	values := make(map[string]string)
+
+	for name, value := range variables {
+		values[name] = FormatVMValue(value)
+	}
+
+	return values
+}
+
+// Name: EmitVMCode
+//
+// Parameters: IRProgram
+//
+// Return: []string, error
+//
+// Translates three-address code to the assembly of the stack machine. The code outside functions comes first
+// and ends with halt, followed by the functions. Calls to print and read that are not declared functions use
+// the print and read instructions. Every call leaves a value on the stack, which is 0 when a function returns
+// without one. Returns an error when a function begins inside another function or its begin and end do not match
+func EmitVMCode(program IRProgram) ([]string, error) {
+
+	functions := make(map[string]bool)
+	for _, instruction := range program.Instructions {
+		if instruction.Operator == ir_begin_func {
+			functions[instruction.Arg1] = true
+		}
+	}
+
+	main := []string{}
+	bodies := []string{}
+	output := &main
+	receives := []string{}
+	function := ""
+
+	operand := func(address string) string {
+		if _, err := ParseVMValue(address); err == nil {
+			return "push " + address
+		}
+		return "load " + address
+	}
+
+	for _, instruction := range program.Instructions {
+
+		// arguments are pushed in order, so parameters are stored from the last one
+		if instruction.Operator != ir_receive && len(receives) > 0 {
+			for i := len(receives) - 1; i >= 0; i-- {
+				*output = append(*output, "    local "+receives[i])
+			}
+			receives = []string{}
+		}
+
+		switch instruction.Operator {
+		case ir_copy:
+			*output = append(*output, "    "+operand(instruction.Arg1), "    store "+instruction.Result)
+		case ir_label:
+			*output = append(*output, instruction.Result+":")
+		case ir_goto:
+			*output = append(*output, "    jmp "+instruction.Result)
+		case ir_if_false:
+			*output = append(*output, "    "+operand(instruction.Arg1), "    jz "+instruction.Result)
+		case ir_param:
+			*output = append(*output, "    "+operand(instruction.Arg1))
+		case ir_call:
+			switch {
+			case functions[instruction.Arg1]:
+				*output = append(*output, "    call "+instruction.Arg1)
+			case instruction.Arg1 == "print":
+				*output = append(*output, "    print", "    push 0")
+			case instruction.Arg1 == "read":
+				*output = append(*output, "    read")
+			default:
+				return nil, fmt.Errorf("function not declared: %v", instruction.Arg1)
+			}
+			if instruction.Result != "" {
+				*output = append(*output, "    store "+instruction.Result)
+			} else {
+				*output = append(*output, "    pop")
+			}
+		case ir_return:
+			if instruction.Arg1 == "" {
+				*output = append(*output, "    push 0")
+			} else {
+				*output = append(*output, "    "+operand(instruction.Arg1))
+			}
+			*output = append(*output, "    ret")
+		case ir_receive:
+			receives = append(receives, instruction.Arg1)
+		case ir_begin_func:
+			if function != "" {
+				return nil, fmt.Errorf("function %v begins inside function %v", instruction.Arg1, function)
+			}
+			function = instruction.Arg1
+			output = &bodies
+			*output = append(*output, instruction.Arg1+":")
+		case ir_end_func:
+			if function != instruction.Arg1 {
+				return nil, fmt.Errorf("function %v ends without beginning", instruction.Arg1)
+			}
+			function = ""
+			*output = append(*output, "    push 0", "    ret")
+			output = &main
+		default:
+			opcode, found := vm_operators[instruction.Operator]
+			if !found {
+				return nil, fmt.Errorf("operator has no instruction: %v", instruction.Operator)
+			}
+			*output = append(*output, "    "+operand(instruction.Arg1), "    "+operand(instruction.Arg2), "    "+opcode, "    store "+instruction.Result)
+		}
+	}
+
+	if function != "" {
+		return nil, fmt.Errorf("function %v does not end", function)
+	}
+
+	main = append(main, "    halt")
+
+	return append(main, bodies...), nil
+}
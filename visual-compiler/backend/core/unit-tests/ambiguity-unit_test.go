@@ -0,0 +1,117 @@
+package unit_tests
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/COS301-SE-2025/Visual-Compiler/backend/core/services"
+)
+
+func TestDetectAmbiguity_NoStart(t *testing.T) {
+	_, err := services.DetectAmbiguity(services.Grammar{}, services.AmbiguityOptions{})
+
+	if err == nil {
+		t.Errorf("Error expected for grammar without start variable")
+	} else {
+		if err.Error() != fmt.Errorf("no start variable found").Error() {
+			t.Errorf("Incorrect error received: %v", err)
+		}
+	}
+}
+
+func TestDetectAmbiguity_Unproductive(t *testing.T) {
+	grammar := services.Grammar{
+		Variables: []string{"S"},
+		Terminals: []string{"INTEGER"},
+		Start:     "S",
+		Rules: []services.ParsingRule{
+			{Input: "S", Output: []string{"INTEGER", "S"}},
+		},
+	}
+
+	_, err := services.DetectAmbiguity(grammar, services.AmbiguityOptions{})
+
+	if err == nil {
+		t.Errorf("Error expected for unproductive start variable")
+	} else {
+		if err.Error() != fmt.Errorf("start variable does not derive any sentence").Error() {
+			t.Errorf("Incorrect error received: %v", err)
+		}
+	}
+}
+
+func TestDetectAmbiguity_DanglingElse(t *testing.T) {
+	grammar := services.Grammar{
+		Variables: []string{"STATEMENT"},
+		Terminals: []string{"IF", "ELSE", "OTHER"},
+		Start:     "STATEMENT",
+		Rules: []services.ParsingRule{
+			{Input: "STATEMENT", Output: []string{"IF", "STATEMENT"}},
+			{Input: "STATEMENT", Output: []string{"IF", "STATEMENT", "ELSE", "STATEMENT"}},
+			{Input: "STATEMENT", Output: []string{"OTHER"}},
+		},
+	}
+
+	result, err := services.DetectAmbiguity(grammar, services.AmbiguityOptions{MaxLength: 6})
+
+	if err != nil {
+		t.Fatalf("Error not expected: %v", err)
+	}
+
+	if !result.Ambiguous || result.Witness != "IF IF OTHER ELSE OTHER" {
+		t.Fatalf("Ambiguity not found: %v", result)
+	}
+
+	first := services.ConvertTreeToSExpression(result.Trees[0].Root)
+	second := services.ConvertTreeToSExpression(result.Trees[1].Root)
+
+	expected := map[string]bool{
+		"(STATEMENT (IF) (STATEMENT (IF) (STATEMENT (OTHER)) (ELSE) (STATEMENT (OTHER))))": true,
+		"(STATEMENT (IF) (STATEMENT (IF) (STATEMENT (OTHER))) (ELSE) (STATEMENT (OTHER)))": true,
+	}
+
+	if first == second || !expected[first] || !expected[second] {
+		t.Errorf("Incorrect trees: \n%v\n%v", first, second)
+	}
+}
+
+func TestDetectAmbiguity_Unambiguous(t *testing.T) {
+	grammar := services.Grammar{
+		Variables: []string{"LIST"},
+		Terminals: []string{"INTEGER", "SEPARATOR"},
+		Start:     "LIST",
+		Rules: []services.ParsingRule{
+			{Input: "LIST", Output: []string{"INTEGER", "SEPARATOR", "LIST"}},
+			{Input: "LIST", Output: []string{"INTEGER"}},
+		},
+	}
+
+	result, err := services.DetectAmbiguity(grammar, services.AmbiguityOptions{MaxLength: 7})
+
+	if err != nil {
+		t.Errorf("Error not expected: %v", err)
+	} else if result.Ambiguous || !result.Complete || result.Sentences != 4 {
+		t.Errorf("Incorrect result: %v", result)
+	}
+}
+
+func TestDetectAmbiguity_TimeBudget(t *testing.T) {
+	grammar := services.Grammar{
+		Variables: []string{"S"},
+		Terminals: []string{"X", "Y"},
+		Start:     "S",
+		Rules: []services.ParsingRule{
+			{Input: "S", Output: []string{"X", "S"}},
+			{Input: "S", Output: []string{"Y", "S"}},
+			{Input: "S", Output: []string{"ε"}},
+		},
+	}
+
+	result, err := services.DetectAmbiguity(grammar, services.AmbiguityOptions{MaxLength: 40, MaxDepth: 50, TimeBudget: 1})
+
+	if err != nil {
+		t.Errorf("Error not expected: %v", err)
+	} else if result.Ambiguous || result.Complete {
+		t.Errorf("Search expected to stop at the time budget: %v", result)
+	}
+}
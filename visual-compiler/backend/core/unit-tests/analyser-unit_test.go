@@ -1,7 +1,10 @@
 package unit_tests
 
 import (
+	"encoding/json"
 	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/COS301-SE-2025/Visual-Compiler/backend/core/services"
@@ -1953,7 +1956,8 @@ func TestAnalyse_Invalid_TypeCheckAssignment_VariableValues(t *testing.T) {
 	if err == nil {
 		t.Errorf("Error expected")
 	} else {
-		if err.Error() != fmt.Errorf("error: invalid types assigned to: int blue").Error() {
+		// both declarations are invalid and the analyser reports every error
+		if err.Error() != "error: invalid types assigned to: int blue\nerror: invalid operand types in 13 + 89: INTEGER + INTEGER" {
 			t.Errorf("incorrect error: %v", err)
 		}
 	}
@@ -2822,7 +2826,13 @@ func TestTraverseSyntaxTree_BindSymbolError(t *testing.T) {
 
 func TestStringifySymbolTable(t *testing.T) {
 	expected_res := "-------------------------------------------------------------------------- \nSYMBOL TABLE\n"
-	expected_res += "  Name: blue  Type: int  Scope: 0\n"
+	expected_res += "  Name  Type  Scope  Node  Parameter  Inferred\n"
+	expected_res += "  blue  int   0      1     false      false\n"
+	expected_res += "SCOPES\n"
+	expected_res += "  ID  Parent  Kind    Node\n"
+	expected_res += "  0   -       global  0\n"
+	expected_res += "REFERENCES\n"
+	expected_res += "  Name  Node  Scope  Declaration\n"
 	expected_res += "--------------------------------------------------------------------------\n"
 
 	scope_rules := []*services.ScopeRule{
@@ -2912,7 +2922,7 @@ func TestStringifySymbolTable(t *testing.T) {
 
 	string_artefact := services.StringifySymbolTable(symbol_table_artefact)
 	if string_artefact != expected_res {
-		t.Errorf("Incorrect string generated")
+		t.Errorf("Incorrect string generated: \n%v", string_artefact)
 	}
 }
 
@@ -4067,9 +4077,48 @@ func TestHandleFunctionScope_ErrorParameter_MultipleChildren(t *testing.T) {
 		FunctionRule:  "FUNCTION",
 	}
 
+	// the parameter is bound in the scope of the function body, so it no longer clashes with the global
 	err := services.HandleFunctionScope(&services.Symbol{}, child, symbol_table, symbol_table_artefact, rules)
-	if err == nil {
-		t.Fatalf("Error expected")
+	if err != nil {
+		t.Fatalf("Error not expected: %v", err)
+	}
+	if symbol_table.SymbolScopes[0]["blue"].IsParam || len(symbol_table_artefact.SymbolScopes) != 1 {
+		t.Errorf("Parameter bound in the enclosing scope")
+	}
+}
+
+func TestHandleFunctionScope_ErrorParameter_Duplicate(t *testing.T) {
+
+	child := &services.TreeNode{
+		Symbol: "CODE",
+		Children: []*services.TreeNode{
+			{Symbol: "IDENTIFIER", Value: "f"},
+			{
+				Symbol: "PARAMETER",
+				Children: []*services.TreeNode{
+					{Symbol: "TYPE", Value: "int"},
+					{Symbol: "IDENTIFIER", Value: "p"},
+				},
+			},
+			{
+				Symbol: "PARAMETER",
+				Children: []*services.TreeNode{
+					{Symbol: "TYPE", Value: "float"},
+					{Symbol: "IDENTIFIER", Value: "p"},
+				},
+			},
+		},
+	}
+	rules := services.GrammarRules{
+		VariableRule:  "IDENTIFIER",
+		TypeRule:      "TYPE",
+		ParameterRule: "PARAMETER",
+		FunctionRule:  "FUNCTION",
+	}
+
+	err := services.HandleFunctionScope(&services.Symbol{}, child, services.CreateEmptySymbolTable(), &services.SymbolTableArtefact{}, rules)
+	if err == nil || err.Error() != "symbol already declared in scope: p" {
+		t.Errorf("Error expected for duplicate parameter but received %v", err)
 	}
 }
 
@@ -4188,64 +4237,7 @@ func TestHandleFunctionScope_ErrorParameter_MultipleChildren2(t *testing.T) {
 	}
 }
 
-func TestAnalyse_DefaultExample(t *testing.T) {
-	scope_rules := []*services.ScopeRule{
-		{Start: "{", End: "}"},
-	}
-
-	tokens := []services.TypeValue{
-		{Type: "KEYWORD", Value: "int"},
-		{Type: "IDENTIFIER", Value: "blue"},
-		{Type: "ASSIGNMENT", Value: "="},
-		{Type: "INTEGER", Value: "13"},
-		{Type: "DELIMITER", Value: ";"},
-
-		{Type: "KEYWORD", Value: "int"},
-		{Type: "IDENTIFIER", Value: "new"},
-		{Type: "OPEN_BRACKET", Value: "("},
-		{Type: "KEYWORD", Value: "int"},
-		{Type: "IDENTIFIER", Value: "red"},
-		{Type: "CLOSE_BRACKET", Value: ")"},
-
-		{Type: "OPEN_SCOPE", Value: "{"},
-		{Type: "IDENTIFIER", Value: "red"},
-		{Type: "ASSIGNMENT", Value: "="},
-		{Type: "IDENTIFIER", Value: "red"},
-		{Type: "OPERATOR", Value: "+"},
-		{Type: "INTEGER", Value: "1"},
-		{Type: "DELIMITER", Value: ";"},
-		{Type: "KEYWORD", Value: "return"},
-		{Type: "IDENTIFIER", Value: "red"},
-		{Type: "DELIMITER", Value: ";"},
-		{Type: "CLOSE_SCOPE", Value: "}"},
-
-		{Type: "KEYWORD", Value: "int"},
-		{Type: "IDENTIFIER", Value: "_i"},
-		{Type: "ASSIGNMENT", Value: "="},
-		{Type: "INTEGER", Value: "0"},
-		{Type: "DELIMITER", Value: ";"},
-
-		{Type: "CONTROL", Value: "for"},
-		{Type: "IDENTIFIER", Value: "_i"},
-		{Type: "CONTROL", Value: "range"},
-		{Type: "OPEN_BRACKET", Value: "("},
-		{Type: "INTEGER", Value: "12"},
-		{Type: "CLOSE_BRACKET", Value: ")"},
-		{Type: "OPEN_SCOPE", Value: "{"},
-		{Type: "IDENTIFIER", Value: "blue"},
-		{Type: "ASSIGNMENT", Value: "="},
-		{Type: "IDENTIFIER", Value: "new"},
-		{Type: "OPEN_BRACKET", Value: "("},
-		{Type: "IDENTIFIER", Value: "blue"},
-		{Type: "CLOSE_BRACKET", Value: ")"},
-		{Type: "DELIMITER", Value: ";"},
-		{Type: "KEYWORD", Value: "print"},
-		{Type: "OPEN_BRACKET", Value: "("},
-		{Type: "IDENTIFIER", Value: "blue"},
-		{Type: "CLOSE_BRACKET", Value: ")"},
-		{Type: "DELIMITER", Value: ";"},
-		{Type: "CLOSE_SCOPE", Value: "}"},
-	}
+func createDefaultAnalyserExample(t *testing.T) (services.SyntaxTree, services.GrammarRules, []services.TypeRule) {
 
 	grammar := services.Grammar{
 		Variables: []string{"PROGRAM", "STATEMENT", "FUNCTION", "ITERATION", "DECLARATION", "ELEMENT", "TYPE", "EXPRESSION", "FUNCTION_DEFINITION", "FUNCTION_BLOCK", "RETURN", "ITERATION_DEFINITION", "ITERATION_BLOCK", "PARAMETER", "PRINT"},
@@ -4274,11 +4266,18 @@ func TestAnalyse_DefaultExample(t *testing.T) {
 		},
 	}
 
-	syntax_tree, err := services.CreateSyntaxTree(tokens, grammar)
-	if err != nil {
-		t.Fatalf("parser failed")
+	token_types := map[string]string{
+		"int": "KEYWORD", "return": "KEYWORD", "print": "KEYWORD", "for": "CONTROL", "range": "CONTROL",
+		"=": "ASSIGNMENT", "+": "OPERATOR", ";": "DELIMITER", "(": "OPEN_BRACKET", ")": "CLOSE_BRACKET", "{": "OPEN_SCOPE", "}": "CLOSE_SCOPE",
 	}
 
+	syntax_tree := createTree(t, grammar, token_types,
+		"int blue = 13 ;",
+		"int new ( int red ) { red = red + 1 ; return red ; }",
+		"int _i = 0 ;",
+		"for _i range ( 12 ) { blue = new ( blue ) ; print ( blue ) ; }",
+	)
+
 	type_rules := []services.TypeRule{
 		{ResultData: "int", Assignment: "=", LHSData: "INTEGER", Operator: []string{}, RHSData: ""},
 		{ResultData: "int", Assignment: "=", LHSData: "int", Operator: []string{}, RHSData: ""},
@@ -4294,13 +4293,97 @@ func TestAnalyse_DefaultExample(t *testing.T) {
 		TermRule:       "ELEMENT",
 	}
 
-	_, _, err = services.Analyse(scope_rules, syntax_tree, rules, type_rules)
+	return syntax_tree, rules, type_rules
+}
+
+func TestAnalyse_DefaultExample(t *testing.T) {
+	scope_rules := []*services.ScopeRule{
+		{Start: "{", End: "}"},
+	}
+
+	syntax_tree, rules, type_rules := createDefaultAnalyserExample(t)
+
+	_, _, err := services.Analyse(scope_rules, syntax_tree, rules, type_rules)
 	if err != nil {
 		t.Errorf("%v", err)
 
 	}
 }
 
+func TestAnalyse_ScopeTree(t *testing.T) {
+	scope_rules := []*services.ScopeRule{
+		{Start: "{", End: "}"},
+	}
+
+	syntax_tree, rules, type_rules := createDefaultAnalyserExample(t)
+
+	symbol_table_artefact, _, err := services.Analyse(scope_rules, syntax_tree, rules, type_rules)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	expected_scopes := []services.Scope{
+		{ID: 0, Parent: -1, Kind: "global", Node: 0},
+		{ID: 1, Parent: 0, Kind: "function", Node: 22},
+		{ID: 2, Parent: 0, Kind: "block", Node: 60},
+	}
+
+	if !reflect.DeepEqual(symbol_table_artefact.Scopes, expected_scopes) {
+		t.Errorf("Incorrect scopes: %v", symbol_table_artefact.Scopes)
+	}
+
+	for _, symbol := range symbol_table_artefact.SymbolScopes {
+		if symbol.Name == "red" && (symbol.ScopeID != 1 || symbol.Node != 15 || !symbol.IsParam) {
+			t.Errorf("Incorrect parameter symbol: %v", symbol)
+		}
+		if symbol.Name == "blue" && (symbol.ScopeID != 0 || symbol.Node != 2) {
+			t.Errorf("Incorrect symbol: %v", symbol)
+		}
+	}
+}
+
+func TestAnalyse_References(t *testing.T) {
+	scope_rules := []*services.ScopeRule{
+		{Start: "{", End: "}"},
+	}
+
+	syntax_tree, rules, type_rules := createDefaultAnalyserExample(t)
+
+	symbol_table_artefact, _, err := services.Analyse(scope_rules, syntax_tree, rules, type_rules)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	expected_res := []services.Reference{
+		{Name: "red", Node: 25, Scope: 1, Declaration: 15, Assign: true},
+		{Name: "red", Node: 29, Scope: 1, Declaration: 15},
+		{Name: "red", Node: 37, Scope: 1, Declaration: 15},
+		{Name: "_i", Node: 52, Scope: 0, Declaration: 41},
+		{Name: "new", Node: 65, Scope: 2, Declaration: 10},
+		{Name: "blue", Node: 69, Scope: 2, Declaration: 2},
+		{Name: "blue", Node: 76, Scope: 2, Declaration: 2},
+	}
+
+	if !reflect.DeepEqual(symbol_table_artefact.References, expected_res) {
+		t.Errorf("Incorrect references: %v", symbol_table_artefact.References)
+	}
+}
+
+func TestSymbolTableArtefact_JSON(t *testing.T) {
+	symbol_table_artefact := services.CreateEmptySymbolTableArtefact()
+	symbol_table_artefact.SymbolScopes = append(symbol_table_artefact.SymbolScopes, services.Symbol{Name: "blue", Type: "int"})
+
+	output, err := json.Marshal(symbol_table_artefact)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	expected_res := `{"symbol_scopes":[{"name":"blue","type":"int","scope":0,"scope_id":0,"node":0,"assign":false,"is_param":false,"is_function":false,"inferred":false}],"scopes":[{"id":0,"parent":-1,"kind":"global","node":0}],"references":[]}`
+	if string(output) != expected_res {
+		t.Errorf("Incorrect JSON: %v", string(output))
+	}
+}
+
 func TestAnalyser(t *testing.T) {
 	scope_rules := []*services.ScopeRule{
 		{Start: "{", End: "}"},
@@ -4702,3 +4785,1437 @@ func TestAnalyser(t *testing.T) {
 		t.Errorf("%v", err)
 	}
 }
+
+func createInferenceExample(t *testing.T, source_lines ...string) (services.SyntaxTree, services.GrammarRules, []services.TypeRule) {
+	grammar := services.Grammar{
+		Variables: []string{"PROGRAM", "STATEMENT", "DECLARATION", "EXPRESSION", "ELEMENT", "TYPE"},
+		Terminals: []string{"KEYWORD", "IDENTIFIER", "ASSIGNMENT", "INTEGER", "FLOAT", "OPERATOR", "DELIMITER"},
+		Start:     "PROGRAM",
+		Rules: []services.ParsingRule{
+			{Input: "PROGRAM", Output: []string{"STATEMENT", "PROGRAM"}},
+			{Input: "PROGRAM", Output: []string{"STATEMENT"}},
+			{Input: "STATEMENT", Output: []string{"DECLARATION", "DELIMITER"}},
+			{Input: "DECLARATION", Output: []string{"TYPE", "IDENTIFIER", "ASSIGNMENT", "ELEMENT"}},
+			{Input: "DECLARATION", Output: []string{"IDENTIFIER", "ASSIGNMENT", "EXPRESSION"}},
+			{Input: "DECLARATION", Output: []string{"IDENTIFIER", "ASSIGNMENT", "ELEMENT"}},
+			{Input: "EXPRESSION", Output: []string{"ELEMENT", "OPERATOR", "ELEMENT"}},
+			{Input: "ELEMENT", Output: []string{"INTEGER"}},
+			{Input: "ELEMENT", Output: []string{"FLOAT"}},
+			{Input: "ELEMENT", Output: []string{"IDENTIFIER"}},
+			{Input: "TYPE", Output: []string{"KEYWORD"}},
+		},
+	}
+
+	token_types := map[string]string{"int": "KEYWORD", "float": "KEYWORD", "=": "ASSIGNMENT", "+": "OPERATOR", ";": "DELIMITER"}
+	syntax_tree := createTree(t, grammar, token_types, source_lines...)
+
+	type_rules := []services.TypeRule{
+		{ResultData: "int", Assignment: "=", LHSData: "INTEGER", Operator: []string{}, RHSData: ""},
+		{ResultData: "int", Assignment: "=", LHSData: "int", Operator: []string{}, RHSData: ""},
+		{ResultData: "int", Assignment: "=", LHSData: "int", Operator: []string{"+"}, RHSData: "INTEGER"},
+		{ResultData: "float", Assignment: "=", LHSData: "FLOAT", Operator: []string{}, RHSData: ""},
+	}
+	rules := services.GrammarRules{
+		VariableRule:   "IDENTIFIER",
+		TypeRule:       "TYPE",
+		AssignmentRule: "ASSIGNMENT",
+		OperatorRule:   "OPERATOR",
+		TermRule:       "ELEMENT",
+	}
+
+	return syntax_tree, rules, type_rules
+}
+
+func TestAnalyse_InferredTypes(t *testing.T) {
+	expected_res := []services.Symbol{
+		{Name: "a", Type: "int", Inferred: false},
+		{Name: "b", Type: "int", Inferred: true},
+		{Name: "c", Type: "int", Inferred: true},
+		{Name: "d", Type: "float", Inferred: true},
+	}
+
+	syntax_tree, rules, type_rules := createInferenceExample(t,
+		"int a = 5 ;",
+		"b = a + 1 ;",
+		"c = b ;",
+		"d = 2.5 ;",
+	)
+
+	symbol_table_artefact, _, err := services.Analyse([]*services.ScopeRule{}, syntax_tree, rules, type_rules)
+
+	if err != nil {
+		t.Fatalf("Error not expected: %v", err)
+	}
+
+	if len(symbol_table_artefact.SymbolScopes) != len(expected_res) {
+		t.Fatalf("Incorrect symbols: %v", symbol_table_artefact.SymbolScopes)
+	}
+
+	for i, symbol := range symbol_table_artefact.SymbolScopes {
+		if symbol.Name != expected_res[i].Name || symbol.Type != expected_res[i].Type || symbol.Inferred != expected_res[i].Inferred {
+			t.Errorf("Symbol is incorrect: %v %v %v", symbol.Name, symbol.Type, symbol.Inferred)
+		}
+	}
+}
+
+func TestAnalyse_InferredTypes_NoRule(t *testing.T) {
+	syntax_tree, rules, type_rules := createInferenceExample(t, "e = 2.5 + 1 ;")
+
+	_, _, err := services.Analyse([]*services.ScopeRule{}, syntax_tree, rules, type_rules)
+
+	if err == nil {
+		t.Errorf("Error expected for type that cannot be inferred")
+	} else {
+		// no type rule adds a float to an integer, so the sub-expression is reported
+		if err.Error() != fmt.Errorf("error: invalid operand types in 2.5 + 1: FLOAT + INTEGER").Error() {
+			t.Errorf("Incorrect error: %v", err)
+		}
+	}
+}
+
+func TestInferType_Chained(t *testing.T) {
+	symbol_table := services.CreateEmptySymbolTable()
+	services.BindSymbol(symbol_table, services.Symbol{Name: "b", Type: "int", Inferred: true})
+
+	assignment_data := services.AssignmentData{
+		ResultData: services.Symbol{Name: "c"},
+		Terms:      []services.Symbol{{Name: "TERM", Type: "b"}},
+	}
+
+	type_rules := []services.TypeRule{
+		{ResultData: "int", Assignment: "=", LHSData: "int", Operator: []string{}, RHSData: ""},
+	}
+
+	inferred_type, err := services.InferType(assignment_data, symbol_table, type_rules)
+
+	if err != nil {
+		t.Errorf("Error not expected: %v", err)
+	} else if inferred_type != "int" {
+		t.Errorf("Incorrect type inferred: %v", inferred_type)
+	}
+}
+
+func createCallExample(t *testing.T, call string) (services.SyntaxTree, services.GrammarRules, []services.TypeRule) {
+	grammar := services.Grammar{
+		Variables: []string{"PROGRAM", "STATEMENTS", "STATEMENT", "FUNCTION", "FUNCTION_DEFINITION", "FUNCTION_BLOCK", "PARAMETER", "DECLARATION", "CALL", "ARGUMENTS", "ARGUMENT", "ELEMENT", "TYPE"},
+		Terminals: []string{"KEYWORD", "IDENTIFIER", "ASSIGNMENT", "INTEGER", "FLOAT", "SEPARATOR", "DELIMITER", "OPEN_BRACKET", "CLOSE_BRACKET", "OPEN_SCOPE", "CLOSE_SCOPE"},
+		Start:     "PROGRAM",
+		Rules: []services.ParsingRule{
+			{Input: "PROGRAM", Output: []string{"FUNCTION", "STATEMENTS"}},
+			{Input: "STATEMENTS", Output: []string{"STATEMENT", "STATEMENTS"}},
+			{Input: "STATEMENTS", Output: []string{"STATEMENT"}},
+			{Input: "FUNCTION", Output: []string{"FUNCTION_DEFINITION", "FUNCTION_BLOCK"}},
+			{Input: "FUNCTION_DEFINITION", Output: []string{"TYPE", "IDENTIFIER", "OPEN_BRACKET", "PARAMETER", "SEPARATOR", "PARAMETER", "CLOSE_BRACKET"}},
+			{Input: "PARAMETER", Output: []string{"TYPE", "IDENTIFIER"}},
+			{Input: "FUNCTION_BLOCK", Output: []string{"OPEN_SCOPE", "CLOSE_SCOPE"}},
+			{Input: "STATEMENT", Output: []string{"DECLARATION", "DELIMITER"}},
+			{Input: "DECLARATION", Output: []string{"TYPE", "IDENTIFIER", "ASSIGNMENT", "CALL"}},
+			{Input: "DECLARATION", Output: []string{"TYPE", "IDENTIFIER", "ASSIGNMENT", "ELEMENT"}},
+			{Input: "CALL", Output: []string{"IDENTIFIER", "OPEN_BRACKET", "ARGUMENTS", "CLOSE_BRACKET"}},
+			{Input: "ARGUMENTS", Output: []string{"ARGUMENT", "SEPARATOR", "ARGUMENTS"}},
+			{Input: "ARGUMENTS", Output: []string{"ARGUMENT"}},
+			{Input: "ARGUMENT", Output: []string{"ELEMENT"}},
+			{Input: "ELEMENT", Output: []string{"INTEGER"}},
+			{Input: "ELEMENT", Output: []string{"FLOAT"}},
+			{Input: "ELEMENT", Output: []string{"IDENTIFIER"}},
+			{Input: "TYPE", Output: []string{"KEYWORD"}},
+		},
+	}
+
+	token_types := map[string]string{
+		"int": "KEYWORD", "float": "KEYWORD", "=": "ASSIGNMENT", ",": "SEPARATOR", ";": "DELIMITER",
+		"(": "OPEN_BRACKET", ")": "CLOSE_BRACKET", "{": "OPEN_SCOPE", "}": "CLOSE_SCOPE",
+	}
+
+	syntax_tree := createTree(t, grammar, token_types,
+		"int add ( int x , float y ) { }",
+		"int a = 1 ;",
+		"int b = "+call+" ;",
+	)
+
+	type_rules := []services.TypeRule{
+		{ResultData: "int", Assignment: "=", LHSData: "INTEGER", Operator: []string{}, RHSData: ""},
+		{ResultData: "float", Assignment: "=", LHSData: "FLOAT", Operator: []string{}, RHSData: ""},
+	}
+	rules := services.GrammarRules{
+		VariableRule:  "IDENTIFIER",
+		TypeRule:      "TYPE",
+		FunctionRule:  "FUNCTION_DEFINITION",
+		ParameterRule: "PARAMETER",
+		CallRule:      "CALL",
+		ArgumentRule:  "ARGUMENT",
+	}
+
+	return syntax_tree, rules, type_rules
+}
+
+func TestAnalyse_Call_Valid(t *testing.T) {
+	syntax_tree, rules, type_rules := createCallExample(t, "add ( a , 2.5 )")
+
+	scope_rules := []*services.ScopeRule{
+		{Start: "{", End: "}"},
+	}
+
+	_, _, err := services.Analyse(scope_rules, syntax_tree, rules, type_rules)
+
+	if err != nil {
+		t.Errorf("Error not expected: %v", err)
+	}
+}
+
+func TestAnalyse_Call_Errors(t *testing.T) {
+	tests := []struct {
+		call     string
+		expected string
+	}{
+		{
+			"missing ( 1 )",
+			"error: function not declared: missing",
+		},
+		{
+			"add ( 1 )",
+			"error: function add expects 2 arguments but 1 were given",
+		},
+		{
+			"add ( 2.5 , a )",
+			"error: argument 1 of add has type FLOAT but parameter x expects int\nerror: argument 2 of add has type int but parameter y expects float",
+		},
+	}
+
+	for _, test := range tests {
+		syntax_tree, rules, type_rules := createCallExample(t, test.call)
+
+		scope_rules := []*services.ScopeRule{
+			{Start: "{", End: "}"},
+		}
+
+		_, _, err := services.Analyse(scope_rules, syntax_tree, rules, type_rules)
+
+		if err == nil {
+			t.Errorf("Error expected for call: %v", test.call)
+		} else if err.Error() != test.expected {
+			t.Errorf("Incorrect error: %v", err)
+		}
+	}
+}
+
+func TestAnalyseWithDiagnostics_MultipleErrors(t *testing.T) {
+	syntax_tree, rules, type_rules := createInferenceExample(t,
+		"int a = 5 ;",
+		"int a = 1 ;",
+		"b = c ;",
+		"d = 2.5 + 1 ;",
+	)
+
+	symbol_table_artefact, verified_tree, diagnostics, err := services.AnalyseWithDiagnostics([]*services.ScopeRule{}, syntax_tree, rules, type_rules, nil)
+
+	if err != nil {
+		t.Fatalf("Error not expected: %v", err)
+	}
+
+	expected_res := []services.Diagnostic{
+		{Code: "redeclared_symbol", Message: "symbol already declared in scope: a", Symbol: "a", Path: []int{1, 0, 0}},
+		{Code: "uninferable_type", Message: "error: could not infer type for: b", Symbol: "b", Path: []int{1, 1, 0, 0}},
+		{Code: "undeclared_symbol", Message: "variable not declared within it's scope: c", Symbol: "c", Path: []int{1, 1, 0, 0, 2, 0}},
+		{Code: "invalid_expression", Message: "error: invalid operand types in 2.5 + 1: FLOAT + INTEGER", Symbol: "d", Path: []int{1, 1, 1, 0, 0}},
+	}
+
+	if !reflect.DeepEqual(diagnostics, expected_res) {
+		t.Errorf("Incorrect diagnostics: %v", diagnostics)
+	}
+
+	if len(symbol_table_artefact.SymbolScopes) != 1 || symbol_table_artefact.SymbolScopes[0].Name != "a" {
+		t.Errorf("Partial symbol table expected: %v", symbol_table_artefact.SymbolScopes)
+	}
+
+	if verified_tree.Root != nil {
+		t.Errorf("Tree not expected with diagnostics")
+	}
+}
+
+func TestAnalyseWithDiagnostics_NoErrors(t *testing.T) {
+	syntax_tree, rules, type_rules := createInferenceExample(t, "int a = 5 ;")
+
+	_, verified_tree, diagnostics, err := services.AnalyseWithDiagnostics([]*services.ScopeRule{}, syntax_tree, rules, type_rules, nil)
+
+	if err != nil || len(diagnostics) != 0 || verified_tree.Root == nil {
+		t.Errorf("No diagnostics expected: %v %v", err, diagnostics)
+	}
+}
+
+func TestFindTreePaths(t *testing.T) {
+	syntax_tree := createDeclarationTree(t)
+
+	paths := services.FindTreePaths(syntax_tree.Root)
+
+	for node, path := range paths {
+		current := syntax_tree.Root
+		for _, index := range path {
+			current = current.Children[index]
+		}
+		if current != node {
+			t.Errorf("Incorrect path for %v: %v", node.Symbol, path)
+		}
+	}
+
+	if len(paths) != len(services.NumberTreeNodes(syntax_tree.Root)) {
+		t.Errorf("Path expected for every node")
+	}
+}
+
+func createWarningExample(t *testing.T) (services.SyntaxTree, services.GrammarRules, []services.TypeRule) {
+	grammar := services.Grammar{
+		Variables: []string{"PROGRAM", "STATEMENT", "BLOCK", "FUNCTION", "FUNCTION_DEFINITION", "PARAMETER", "DECLARATION", "ELEMENT", "TYPE"},
+		Terminals: []string{"KEYWORD", "IDENTIFIER", "ASSIGNMENT", "INTEGER", "DELIMITER", "OPEN_BRACKET", "CLOSE_BRACKET", "OPEN_SCOPE", "CLOSE_SCOPE"},
+		Start:     "PROGRAM",
+		Rules: []services.ParsingRule{
+			{Input: "PROGRAM", Output: []string{"STATEMENT", "PROGRAM"}},
+			{Input: "PROGRAM", Output: []string{"STATEMENT"}},
+			{Input: "STATEMENT", Output: []string{"DECLARATION", "DELIMITER"}},
+			{Input: "STATEMENT", Output: []string{"BLOCK"}},
+			{Input: "STATEMENT", Output: []string{"FUNCTION"}},
+			{Input: "BLOCK", Output: []string{"OPEN_SCOPE", "PROGRAM", "CLOSE_SCOPE"}},
+			{Input: "FUNCTION", Output: []string{"FUNCTION_DEFINITION", "BLOCK"}},
+			{Input: "FUNCTION_DEFINITION", Output: []string{"TYPE", "IDENTIFIER", "OPEN_BRACKET", "PARAMETER", "CLOSE_BRACKET"}},
+			{Input: "PARAMETER", Output: []string{"TYPE", "IDENTIFIER"}},
+			{Input: "DECLARATION", Output: []string{"TYPE", "IDENTIFIER", "ASSIGNMENT", "ELEMENT"}},
+			{Input: "DECLARATION", Output: []string{"TYPE", "IDENTIFIER"}},
+			{Input: "DECLARATION", Output: []string{"IDENTIFIER", "ASSIGNMENT", "ELEMENT"}},
+			{Input: "ELEMENT", Output: []string{"INTEGER"}},
+			{Input: "ELEMENT", Output: []string{"IDENTIFIER"}},
+			{Input: "TYPE", Output: []string{"KEYWORD"}},
+		},
+	}
+
+	token_types := map[string]string{
+		"int": "KEYWORD", "=": "ASSIGNMENT", ";": "DELIMITER",
+		"(": "OPEN_BRACKET", ")": "CLOSE_BRACKET", "{": "OPEN_SCOPE", "}": "CLOSE_SCOPE",
+	}
+
+	syntax_tree := createTree(t, grammar, token_types,
+		"int g = 1 ;",
+		"int f ( int p ) {",
+		"int g = 2 ;",
+		"int u ;",
+		"int v = u ;",
+		"} {",
+		"int w ;",
+		"w = 3 ;",
+		"int k = w ;",
+		"}",
+		"int q = g ;",
+	)
+
+	type_rules := []services.TypeRule{
+		{ResultData: "int", Assignment: "=", LHSData: "INTEGER", Operator: []string{}, RHSData: ""},
+		{ResultData: "int", Assignment: "=", LHSData: "int", Operator: []string{}, RHSData: ""},
+	}
+	rules := services.GrammarRules{
+		VariableRule:   "IDENTIFIER",
+		TypeRule:       "TYPE",
+		FunctionRule:   "FUNCTION_DEFINITION",
+		ParameterRule:  "PARAMETER",
+		AssignmentRule: "ASSIGNMENT",
+		TermRule:       "ELEMENT",
+	}
+
+	return syntax_tree, rules, type_rules
+}
+
+func TestFindWarnings_AllEnabled(t *testing.T) {
+	syntax_tree, rules, type_rules := createWarningExample(t)
+
+	scope_rules := []*services.ScopeRule{
+		{Start: "{", End: "}"},
+	}
+
+	symbol_table_artefact, _, err := services.Analyse(scope_rules, syntax_tree, rules, type_rules)
+	if err != nil {
+		t.Fatalf("Error not expected: %v", err)
+	}
+
+	options := services.WarningOptions{Unused: true, Shadowed: true, Uninitialised: true, UnusedParameters: true}
+	warnings := services.FindWarnings(symbol_table_artefact, syntax_tree, options)
+
+	expected_res := []string{
+		"unused_parameter p",
+		"unused_symbol f",
+		"unused_symbol g",
+		"shadowed_symbol g",
+		"uninitialised_read u",
+		"unused_symbol v",
+		"unused_symbol k",
+		"unused_symbol q",
+	}
+
+	found := []string{}
+	for _, warning := range warnings {
+		found = append(found, warning.Code+" "+warning.Symbol)
+
+		current := syntax_tree.Root
+		for _, index := range warning.Path {
+			current = current.Children[index]
+		}
+		if warning.Code == "uninitialised_read" && (current.Symbol != "IDENTIFIER" || current.Value != "u") {
+			t.Errorf("Incorrect node for warning: %v", current.Symbol)
+		}
+	}
+
+	if !reflect.DeepEqual(found, expected_res) {
+		t.Errorf("Incorrect warnings: %v", found)
+	}
+}
+
+func TestFindWarnings_Disabled(t *testing.T) {
+	syntax_tree, rules, type_rules := createWarningExample(t)
+
+	scope_rules := []*services.ScopeRule{
+		{Start: "{", End: "}"},
+	}
+
+	symbol_table_artefact, _, _ := services.Analyse(scope_rules, syntax_tree, rules, type_rules)
+
+	warnings := services.FindWarnings(symbol_table_artefact, syntax_tree, services.WarningOptions{})
+	if len(warnings) != 0 {
+		t.Errorf("No warnings expected: %v", warnings)
+	}
+
+	warnings = services.FindWarnings(symbol_table_artefact, syntax_tree, services.WarningOptions{Shadowed: true})
+	if len(warnings) != 1 || warnings[0].Code != "shadowed_symbol" {
+		t.Errorf("Only the shadowing warning expected: %v", warnings)
+	}
+}
+
+func createExpressionExample(t *testing.T, declaration string) (services.SyntaxTree, services.GrammarRules, []services.TypeRule) {
+	grammar := services.Grammar{
+		Variables: []string{"PROGRAM", "STATEMENT", "DECLARATION", "EXPRESSION", "PRODUCT", "ELEMENT", "TYPE"},
+		Terminals: []string{"KEYWORD", "IDENTIFIER", "ASSIGNMENT", "INTEGER", "FLOAT", "DELIMITER"},
+		Start:     "PROGRAM",
+		Rules: []services.ParsingRule{
+			{Input: "PROGRAM", Output: []string{"STATEMENT", "PROGRAM"}},
+			{Input: "PROGRAM", Output: []string{"STATEMENT"}},
+			{Input: "STATEMENT", Output: []string{"DECLARATION", "DELIMITER"}},
+			{Input: "DECLARATION", Output: []string{"TYPE", "IDENTIFIER", "ASSIGNMENT", "EXPRESSION"}},
+			{Input: "EXPRESSION", Output: []string{"PRODUCT", "'+'", "EXPRESSION"}},
+			{Input: "EXPRESSION", Output: []string{"PRODUCT"}},
+			{Input: "PRODUCT", Output: []string{"ELEMENT", "'*'", "PRODUCT"}},
+			{Input: "PRODUCT", Output: []string{"ELEMENT"}},
+			{Input: "ELEMENT", Output: []string{"INTEGER"}},
+			{Input: "ELEMENT", Output: []string{"FLOAT"}},
+			{Input: "ELEMENT", Output: []string{"IDENTIFIER"}},
+			{Input: "TYPE", Output: []string{"KEYWORD"}},
+		},
+	}
+
+	token_types := map[string]string{"int": "KEYWORD", "float": "KEYWORD", "=": "ASSIGNMENT", "+": "OPERATOR", "*": "OPERATOR", ";": "DELIMITER"}
+
+	syntax_tree := createTree(t, grammar, token_types,
+		"int a = 1 ;",
+		"float b = 2.5 ;",
+		declaration+" ;",
+	)
+
+	type_rules := []services.TypeRule{
+		{ResultData: "int", Assignment: "=", LHSData: "INTEGER", Operator: []string{}, RHSData: ""},
+		{ResultData: "float", Assignment: "=", LHSData: "FLOAT", Operator: []string{}, RHSData: ""},
+		{ResultData: "int", Assignment: "=", LHSData: "int", Operator: []string{"+", "*"}, RHSData: "int"},
+		{ResultData: "float", Assignment: "=", LHSData: "float", Operator: []string{"+", "*"}, RHSData: "float"},
+	}
+	rules := services.GrammarRules{
+		VariableRule:   "IDENTIFIER",
+		TypeRule:       "TYPE",
+		AssignmentRule: "ASSIGNMENT",
+		OperatorRule:   "OPERATOR",
+		TermRule:       "ELEMENT",
+	}
+
+	return syntax_tree, rules, type_rules
+}
+
+func TestAnalyse_NestedExpression(t *testing.T) {
+	syntax_tree, rules, type_rules := createExpressionExample(t, "int r = a + a * a")
+
+	_, _, err := services.Analyse([]*services.ScopeRule{}, syntax_tree, rules, type_rules)
+
+	if err != nil {
+		t.Errorf("Error not expected: %v", err)
+	}
+}
+
+func TestAnalyse_NestedExpression_HiddenTerm(t *testing.T) {
+	// the first term has the type of the rule, which must not hide the float term
+	syntax_tree, rules, type_rules := createExpressionExample(t, "int r = a + b")
+
+	_, _, err := services.Analyse([]*services.ScopeRule{}, syntax_tree, rules, type_rules)
+
+	if err == nil {
+		t.Errorf("Error expected for wrongly typed term")
+	} else if err.Error() != "error: invalid operand types in a + b: int + float" {
+		t.Errorf("Incorrect error: %v", err)
+	}
+}
+
+func TestAnalyse_NestedExpression_SubExpression(t *testing.T) {
+	syntax_tree, rules, type_rules := createExpressionExample(t, "int r = a + b * a")
+
+	_, _, diagnostics, err := services.AnalyseWithDiagnostics([]*services.ScopeRule{}, syntax_tree, rules, type_rules, nil)
+
+	if err != nil {
+		t.Fatalf("Error not expected: %v", err)
+	}
+
+	expected_res := []services.Diagnostic{
+		{Code: "invalid_expression", Message: "error: invalid operand types in b * a: float * int", Symbol: "r", Path: []int{1, 1, 0, 0}},
+	}
+
+	if !reflect.DeepEqual(diagnostics, expected_res) {
+		t.Errorf("Incorrect diagnostics: %v", diagnostics)
+	}
+}
+
+func TestAnalyse_NestedExpression_TokenTerms(t *testing.T) {
+	// token terms are converted by the type rules without an operator before the operators are applied
+	syntax_tree, rules, type_rules := createExpressionExample(t, "int r = 1 + 2 * a")
+
+	_, _, diagnostics, err := services.AnalyseWithDiagnostics([]*services.ScopeRule{}, syntax_tree, rules, type_rules, nil)
+
+	if err != nil || len(diagnostics) != 0 {
+		t.Errorf("Expression of token terms expected to be valid: %v %v", err, diagnostics)
+	}
+
+	syntax_tree, rules, type_rules = createExpressionExample(t, "int r = 1 + 2.5 * 3")
+
+	_, _, diagnostics, err = services.AnalyseWithDiagnostics([]*services.ScopeRule{}, syntax_tree, rules, type_rules, nil)
+
+	if err != nil {
+		t.Fatalf("Error not expected: %v", err)
+	}
+
+	expected_res := []services.Diagnostic{
+		{Code: "invalid_expression", Message: "error: invalid operand types in 2.5 * 3: FLOAT * INTEGER", Symbol: "r", Path: []int{1, 1, 0, 0}},
+	}
+
+	if !reflect.DeepEqual(diagnostics, expected_res) {
+		t.Errorf("Incorrect diagnostics: %v", diagnostics)
+	}
+}
+
+func TestAnalyse_NestedExpression_Promotions(t *testing.T) {
+	promotions := []services.Promotion{{From: "int", To: "float"}}
+
+	syntax_tree, rules, type_rules := createExpressionExample(t, "float r = a + b * a")
+
+	_, _, diagnostics, err := services.AnalyseWithDiagnostics([]*services.ScopeRule{}, syntax_tree, rules, type_rules, promotions)
+
+	if err != nil || len(diagnostics) != 0 {
+		t.Errorf("Promoted expression expected to be valid: %v %v", err, diagnostics)
+	}
+
+	// the float result is not demoted to an int
+	syntax_tree, rules, type_rules = createExpressionExample(t, "int r = a + b")
+
+	_, _, diagnostics, _ = services.AnalyseWithDiagnostics([]*services.ScopeRule{}, syntax_tree, rules, type_rules, promotions)
+
+	if len(diagnostics) != 1 || diagnostics[0].Message != "error: invalid types assigned to: int r" {
+		t.Errorf("Incorrect diagnostics: %v", diagnostics)
+	}
+}
+
+func TestIsPromotable(t *testing.T) {
+	promotions := []services.Promotion{
+		{From: "int", To: "float"},
+		{From: "float", To: "double"},
+		{From: "double", To: "float"},
+	}
+
+	if !services.IsPromotable("int", "double", promotions) {
+		t.Errorf("Chained promotion expected")
+	}
+	if services.IsPromotable("double", "int", promotions) {
+		t.Errorf("Promotions expected to be one way")
+	}
+}
+
+func TestOperationTypes_PrefersExactRule(t *testing.T) {
+	type_rules := []services.TypeRule{
+		{ResultData: "float", Assignment: "=", LHSData: "float", Operator: []string{"+"}, RHSData: "float"},
+		{ResultData: "int", Assignment: "=", LHSData: "int", Operator: []string{"+"}, RHSData: "int"},
+	}
+	promotions := []services.Promotion{{From: "int", To: "float"}}
+
+	result := services.OperationTypes("+", []string{"int"}, []string{"int"}, type_rules, promotions)
+	if !reflect.DeepEqual(result, []string{"int"}) {
+		t.Errorf("Incorrect types: %v", result)
+	}
+
+	result = services.OperationTypes("+", []string{"int"}, []string{"float"}, type_rules, promotions)
+	if !reflect.DeepEqual(result, []string{"float"}) {
+		t.Errorf("Incorrect types: %v", result)
+	}
+
+	result = services.OperationTypes("-", []string{"int"}, []string{"int"}, type_rules, promotions)
+	if len(result) != 0 {
+		t.Errorf("No types expected for unknown operator: %v", result)
+	}
+}
+
+func findDecoratedNodes(node *services.DecoratedNode, symbol string, found []*services.DecoratedNode) []*services.DecoratedNode {
+	if node.Symbol == symbol {
+		found = append(found, node)
+	}
+	for _, child := range node.Children {
+		found = findDecoratedNodes(child, symbol, found)
+	}
+	return found
+}
+
+func TestAnalyse_DecoratedTree(t *testing.T) {
+	syntax_tree, rules, type_rules := createExpressionExample(t, "float r = a + b * a")
+
+	symbol_table_artefact, decorated_tree, _, err := services.AnalyseWithDiagnostics([]*services.ScopeRule{}, syntax_tree, rules, type_rules, []services.Promotion{{From: "int", To: "float"}})
+
+	if err != nil || decorated_tree.Root == nil {
+		t.Fatalf("Decorated tree expected: %v", err)
+	}
+
+	if services.ConvertTreeToSExpression(syntax_tree.Root) == "" || decorated_tree.Root.Symbol != syntax_tree.Root.Symbol {
+		t.Errorf("Decorated tree does not match the syntax tree")
+	}
+
+	declarations := findDecoratedNodes(decorated_tree.Root, "DECLARATION", nil)
+	expected_types := []string{"int", "float", "float"}
+
+	if len(declarations) != len(expected_types) {
+		t.Fatalf("Incorrect number of declarations: %v", len(declarations))
+	}
+	for i, declaration := range declarations {
+		if declaration.Type != expected_types[i] || declaration.Declaration != symbol_table_artefact.SymbolScopes[i].Node || declaration.ScopeID != 0 {
+			t.Errorf("Incorrect declaration: %v %v %v", declaration.Type, declaration.Declaration, declaration.ScopeID)
+		}
+	}
+
+	expected_res := []struct {
+		value       string
+		node_type   string
+		declaration int
+	}{
+		{"a", "int", symbol_table_artefact.SymbolScopes[0].Node},
+		{"b", "float", symbol_table_artefact.SymbolScopes[1].Node},
+		{"r", "float", symbol_table_artefact.SymbolScopes[2].Node},
+		{"a", "int", symbol_table_artefact.SymbolScopes[0].Node},
+		{"b", "float", symbol_table_artefact.SymbolScopes[1].Node},
+		{"a", "int", symbol_table_artefact.SymbolScopes[0].Node},
+	}
+
+	identifiers := findDecoratedNodes(decorated_tree.Root, "IDENTIFIER", nil)
+	if len(identifiers) != len(expected_res) {
+		t.Fatalf("Incorrect number of names: %v", len(identifiers))
+	}
+	for i, identifier := range identifiers {
+		if identifier.Value != expected_res[i].value || identifier.Type != expected_res[i].node_type || identifier.Declaration != expected_res[i].declaration {
+			t.Errorf("Incorrect name: %v %v %v", identifier.Value, identifier.Type, identifier.Declaration)
+		}
+	}
+
+	expressions := findDecoratedNodes(decorated_tree.Root, "EXPRESSION", nil)
+	products := findDecoratedNodes(decorated_tree.Root, "PRODUCT", nil)
+
+	if expressions[len(expressions)-2].Type != "float" || products[len(products)-2].Type != "float" || products[len(products)-1].Type != "int" {
+		t.Errorf("Incorrect expression types")
+	}
+
+	for _, integer := range findDecoratedNodes(decorated_tree.Root, "INTEGER", nil) {
+		if integer.Type != "INTEGER" || integer.Declaration != -1 {
+			t.Errorf("Incorrect literal: %v %v", integer.Type, integer.Declaration)
+		}
+	}
+}
+
+func TestAnalyse_DecoratedTree_Scopes(t *testing.T) {
+	syntax_tree, rules, type_rules := createDefaultAnalyserExample(t)
+
+	scope_rules := []*services.ScopeRule{
+		{Start: "{", End: "}"},
+	}
+
+	symbol_table_artefact, decorated_tree, err := services.Analyse(scope_rules, syntax_tree, rules, type_rules)
+
+	if err != nil {
+		t.Fatalf("Error not expected: %v", err)
+	}
+
+	for _, symbol := range symbol_table_artefact.SymbolScopes {
+		identifiers := findDecoratedNodes(decorated_tree.Root, "IDENTIFIER", nil)
+		for _, identifier := range identifiers {
+			if identifier.Value == symbol.Name && identifier.Declaration == symbol.Node && identifier.Type != symbol.Type {
+				t.Errorf("Incorrect type of %v: %v", identifier.Value, identifier.Type)
+			}
+		}
+	}
+
+	scopes := []int{}
+	for _, scope := range findDecoratedNodes(decorated_tree.Root, "OPEN_SCOPE", nil) {
+		scopes = append(scopes, scope.ScopeID)
+	}
+	for _, scope := range findDecoratedNodes(decorated_tree.Root, "CLOSE_SCOPE", nil) {
+		scopes = append(scopes, scope.ScopeID)
+	}
+
+	if !reflect.DeepEqual(scopes, []int{1, 2, 1, 2}) {
+		t.Errorf("Incorrect scopes: %v", scopes)
+	}
+
+	// the parameter of the function is declared in the scope of the function body
+	parameter := findDecoratedNodes(decorated_tree.Root, "PARAMETER", nil)[0]
+	if parameter.ScopeID != 1 || parameter.Type != "int" {
+		t.Errorf("Incorrect parameter: %v %v", parameter.ScopeID, parameter.Type)
+	}
+}
+
+func createReturnExample(t *testing.T, source_lines ...string) (services.SyntaxTree, services.GrammarRules, []services.TypeRule) {
+	grammar := services.Grammar{
+		Variables: []string{"PROGRAM", "STATEMENT", "BLOCK", "FUNCTION", "FUNCTION_DEFINITION", "PARAMETER", "DECLARATION", "RETURN", "CONDITIONAL", "ELEMENT", "TYPE"},
+		Terminals: []string{"KEYWORD", "CONTROL", "IF", "ELSE", "IDENTIFIER", "ASSIGNMENT", "INTEGER", "FLOAT", "DELIMITER", "OPEN_BRACKET", "CLOSE_BRACKET", "OPEN_SCOPE", "CLOSE_SCOPE"},
+		Start:     "PROGRAM",
+		Rules: []services.ParsingRule{
+			{Input: "PROGRAM", Output: []string{"STATEMENT", "PROGRAM"}},
+			{Input: "PROGRAM", Output: []string{"STATEMENT"}},
+			{Input: "STATEMENT", Output: []string{"DECLARATION", "DELIMITER"}},
+			{Input: "STATEMENT", Output: []string{"RETURN"}},
+			{Input: "STATEMENT", Output: []string{"BLOCK"}},
+			{Input: "STATEMENT", Output: []string{"FUNCTION"}},
+			{Input: "STATEMENT", Output: []string{"CONDITIONAL"}},
+			{Input: "CONDITIONAL", Output: []string{"IF", "OPEN_BRACKET", "ELEMENT", "CLOSE_BRACKET", "BLOCK", "ELSE", "BLOCK"}},
+			{Input: "CONDITIONAL", Output: []string{"IF", "OPEN_BRACKET", "ELEMENT", "CLOSE_BRACKET", "BLOCK"}},
+			{Input: "BLOCK", Output: []string{"OPEN_SCOPE", "PROGRAM", "CLOSE_SCOPE"}},
+			{Input: "FUNCTION", Output: []string{"FUNCTION_DEFINITION", "BLOCK"}},
+			{Input: "FUNCTION_DEFINITION", Output: []string{"TYPE", "IDENTIFIER", "OPEN_BRACKET", "PARAMETER", "CLOSE_BRACKET"}},
+			{Input: "PARAMETER", Output: []string{"TYPE", "IDENTIFIER"}},
+			{Input: "DECLARATION", Output: []string{"TYPE", "IDENTIFIER", "ASSIGNMENT", "ELEMENT"}},
+			{Input: "RETURN", Output: []string{"CONTROL", "ELEMENT", "DELIMITER"}},
+			{Input: "RETURN", Output: []string{"CONTROL", "DELIMITER"}},
+			{Input: "ELEMENT", Output: []string{"INTEGER"}},
+			{Input: "ELEMENT", Output: []string{"FLOAT"}},
+			{Input: "ELEMENT", Output: []string{"IDENTIFIER"}},
+			{Input: "TYPE", Output: []string{"KEYWORD"}},
+		},
+	}
+
+	token_types := map[string]string{
+		"int": "KEYWORD", "float": "KEYWORD", "void": "KEYWORD", "return": "CONTROL", "if": "IF", "else": "ELSE",
+		"=": "ASSIGNMENT", ";": "DELIMITER", "(": "OPEN_BRACKET", ")": "CLOSE_BRACKET",
+		"{": "OPEN_SCOPE", "begin": "OPEN_SCOPE", "}": "CLOSE_SCOPE", "end": "CLOSE_SCOPE",
+	}
+
+	syntax_tree := createTree(t, grammar, token_types, source_lines...)
+
+	type_rules := []services.TypeRule{
+		{ResultData: "int", Assignment: "=", LHSData: "INTEGER", Operator: []string{}, RHSData: ""},
+		{ResultData: "float", Assignment: "=", LHSData: "FLOAT", Operator: []string{}, RHSData: ""},
+	}
+	rules := services.GrammarRules{
+		VariableRule:   "IDENTIFIER",
+		TypeRule:       "TYPE",
+		FunctionRule:   "FUNCTION_DEFINITION",
+		ParameterRule:  "PARAMETER",
+		AssignmentRule: "ASSIGNMENT",
+		TermRule:       "ELEMENT",
+		ReturnRule:     "RETURN",
+		IfRule:         "CONDITIONAL",
+		ElseRule:       "ELSE",
+	}
+
+	return syntax_tree, rules, type_rules
+}
+
+func TestAnalyse_Return_Valid(t *testing.T) {
+	syntax_tree, rules, type_rules := createReturnExample(t,
+		"int f ( int p ) { begin return 1 ; end return p ; }",
+		"float g ( int p ) { return p ; }",
+		"void h ( int p ) { int a = p ; return ; }",
+		"void k ( int p ) { int b = p ; }",
+		"int m ( int p ) { if ( p ) { return 1 ; } else { return 2 ; } }",
+		"int n ( int p ) { if ( p ) { if ( p ) { return 1 ; } else { return 2 ; } } else { return 3 ; } }",
+	)
+
+	scope_rules := []*services.ScopeRule{
+		{Start: "{", End: "}"},
+		{Start: "begin", End: "end"},
+	}
+	promotions := []services.Promotion{{From: "int", To: "float"}}
+
+	_, _, diagnostics, err := services.AnalyseWithDiagnostics(scope_rules, syntax_tree, rules, type_rules, promotions)
+
+	if err != nil {
+		t.Fatalf("Error not expected: %v", err)
+	}
+	if len(diagnostics) != 0 {
+		t.Errorf("No diagnostics expected: %v", diagnostics)
+	}
+}
+
+func TestAnalyse_ParameterScope(t *testing.T) {
+	syntax_tree, rules, type_rules := createReturnExample(t,
+		"int f ( int p ) { return p ; }",
+		"int x = p ;",
+	)
+
+	scope_rules := []*services.ScopeRule{
+		{Start: "{", End: "}"},
+	}
+
+	symbol_table_artefact, _, diagnostics, err := services.AnalyseWithDiagnostics(scope_rules, syntax_tree, rules, type_rules, []services.Promotion{})
+
+	if err != nil {
+		t.Fatalf("Error not expected: %v", err)
+	}
+	if len(diagnostics) != 1 || diagnostics[0].Code != "undeclared_symbol" || diagnostics[0].Symbol != "p" {
+		t.Errorf("Incorrect diagnostics: %v", diagnostics)
+	}
+
+	for _, symbol := range symbol_table_artefact.SymbolScopes {
+		if symbol.Name != "p" {
+			continue
+		}
+		if symbol.ScopeID >= len(symbol_table_artefact.Scopes) || symbol_table_artefact.Scopes[symbol.ScopeID].Kind != "function" {
+			t.Errorf("Parameter not in the function scope: %v %v", symbol, symbol_table_artefact.Scopes)
+		}
+	}
+}
+
+func TestAnalyse_ParameterShadowsGlobal(t *testing.T) {
+	syntax_tree, rules, type_rules := createReturnExample(t,
+		"float p = 2.5 ;",
+		"int f ( int p ) { return p ; }",
+		"float x = p ;",
+	)
+
+	scope_rules := []*services.ScopeRule{
+		{Start: "{", End: "}"},
+	}
+
+	symbol_table_artefact, _, diagnostics, err := services.AnalyseWithDiagnostics(scope_rules, syntax_tree, rules, type_rules, []services.Promotion{})
+
+	// the int parameter is returned inside the function and the float global is assigned after it
+	if err != nil || len(diagnostics) != 0 {
+		t.Fatalf("No diagnostics expected: %v %v", err, diagnostics)
+	}
+
+	parameters := 0
+	for _, symbol := range symbol_table_artefact.SymbolScopes {
+		if symbol.Name == "p" && symbol.IsParam {
+			parameters++
+		}
+	}
+	if parameters != 1 {
+		t.Errorf("Parameter expected besides the global: %v", symbol_table_artefact.SymbolScopes)
+	}
+}
+
+func TestAnalyse_Return_DefaultExample(t *testing.T) {
+	syntax_tree, rules, type_rules := createDefaultAnalyserExample(t)
+	rules.ReturnRule = "RETURN"
+
+	scope_rules := []*services.ScopeRule{
+		{Start: "{", End: "}"},
+	}
+
+	_, _, err := services.Analyse(scope_rules, syntax_tree, rules, type_rules)
+
+	if err != nil {
+		t.Errorf("Error not expected: %v", err)
+	}
+}
+
+func TestAnalyse_Return_Errors(t *testing.T) {
+	tests := []struct {
+		source  string
+		code    string
+		symbol  string
+		message string
+		node    string
+	}{
+		{
+			source:  "int f ( int p ) { return 2.5 ; }",
+			code:    "return_type",
+			symbol:  "f",
+			message: "error: function f returns FLOAT but is declared as int",
+			node:    "RETURN",
+		},
+		{
+			source:  "int f ( int p ) { return ; }",
+			code:    "return_type",
+			symbol:  "f",
+			message: "error: function f must return a value of type int",
+			node:    "RETURN",
+		},
+		{
+			source:  "void f ( int p ) { return p ; }",
+			code:    "return_type",
+			symbol:  "f",
+			message: "error: function f of type void cannot return a value",
+			node:    "RETURN",
+		},
+		{
+			source:  "int f ( int p ) { begin return p ; end }",
+			code:    "missing_return",
+			symbol:  "f",
+			message: "error: function f does not return a value on every path",
+			node:    "FUNCTION",
+		},
+		{
+			source:  "int f ( int p ) { if ( p ) { return 1 ; } else { int a = p ; } }",
+			code:    "missing_return",
+			symbol:  "f",
+			message: "error: function f does not return a value on every path",
+			node:    "FUNCTION",
+		},
+		{
+			source:  "int f ( int p ) { if ( p ) { return 1 ; } }",
+			code:    "missing_return",
+			symbol:  "f",
+			message: "error: function f does not return a value on every path",
+			node:    "FUNCTION",
+		},
+		{
+			source:  "int f ( int p ) { int a = p ; }",
+			code:    "missing_return",
+			symbol:  "f",
+			message: "error: function f does not return a value on every path",
+			node:    "FUNCTION",
+		},
+		{
+			source:  "int a = 1 ; return a ;",
+			code:    "return_outside_function",
+			symbol:  "",
+			message: "error: return statement outside a function",
+			node:    "RETURN",
+		},
+	}
+
+	scope_rules := []*services.ScopeRule{
+		{Start: "{", End: "}"},
+		{Start: "begin", End: "end"},
+	}
+
+	for _, test := range tests {
+		syntax_tree, rules, type_rules := createReturnExample(t, test.source)
+
+		_, _, diagnostics, err := services.AnalyseWithDiagnostics(scope_rules, syntax_tree, rules, type_rules, nil)
+		if err != nil {
+			t.Fatalf("Error not expected: %v", err)
+		}
+
+		if len(diagnostics) != 1 {
+			t.Errorf("One diagnostic expected for %v: %v", test.source, diagnostics)
+			continue
+		}
+
+		diagnostic := diagnostics[0]
+		if diagnostic.Code != test.code || diagnostic.Symbol != test.symbol || diagnostic.Message != test.message {
+			t.Errorf("Incorrect diagnostic for %v: %v", test.source, diagnostic)
+		}
+
+		current := syntax_tree.Root
+		for _, index := range diagnostic.Path {
+			current = current.Children[index]
+		}
+		if current.Symbol != test.node {
+			t.Errorf("Incorrect node for %v: %v", test.source, current.Symbol)
+		}
+	}
+}
+
+func TestAnalyse_NodeScopeRules(t *testing.T) {
+	syntax_tree, rules, type_rules := createWarningExample(t)
+
+	token_rules := []*services.ScopeRule{
+		{Start: "{", End: "}"},
+	}
+	node_rules := []*services.ScopeRule{
+		{Node: "BLOCK"},
+	}
+	node_rules_copy := []services.ScopeRule{*node_rules[0]}
+
+	token_artefact, _, err := services.Analyse(token_rules, syntax_tree, rules, type_rules)
+	if err != nil {
+		t.Fatalf("Error not expected: %v", err)
+	}
+	node_artefact, _, err := services.Analyse(node_rules, syntax_tree, rules, type_rules)
+	if err != nil {
+		t.Fatalf("Error not expected: %v", err)
+	}
+
+	if *node_rules[0] != node_rules_copy[0] {
+		t.Errorf("Scope rule changed: %v", *node_rules[0])
+	}
+
+	kinds := []string{}
+	for _, scope := range node_artefact.Scopes {
+		kinds = append(kinds, fmt.Sprintf("%v %v", scope.Kind, scope.Parent))
+	}
+	if !reflect.DeepEqual(kinds, []string{"global -1", "function 0", "block 0"}) {
+		t.Errorf("Incorrect scopes: %v", kinds)
+	}
+
+	if len(node_artefact.SymbolScopes) != len(token_artefact.SymbolScopes) {
+		t.Fatalf("Incorrect number of symbols: %v", len(node_artefact.SymbolScopes))
+	}
+	for i, symbol := range node_artefact.SymbolScopes {
+		expected := token_artefact.SymbolScopes[i]
+		if symbol.Name != expected.Name || symbol.ScopeID != expected.ScopeID || symbol.Scope != expected.Scope {
+			t.Errorf("Incorrect symbol: %v", symbol)
+		}
+	}
+}
+
+func TestAnalyse_NodeScopeRules_Kind(t *testing.T) {
+	syntax_tree, rules, type_rules := createWarningExample(t)
+
+	scope_rules := []*services.ScopeRule{
+		{Node: "BLOCK", Kind: "compound"},
+	}
+
+	symbol_table_artefact, _, err := services.Analyse(scope_rules, syntax_tree, rules, type_rules)
+	if err != nil {
+		t.Fatalf("Error not expected: %v", err)
+	}
+
+	kinds := []string{}
+	for _, scope := range symbol_table_artefact.Scopes {
+		kinds = append(kinds, scope.Kind)
+	}
+	if !reflect.DeepEqual(kinds, []string{"global", "compound", "compound"}) {
+		t.Errorf("Incorrect scope kinds: %v", kinds)
+	}
+}
+
+func TestAnalyse_NestedScopes(t *testing.T) {
+	syntax_tree, rules, type_rules := createReturnExample(t, "int f ( int p ) { { int a = p ; { return a ; } } return p ; }")
+
+	scope_rules := []*services.ScopeRule{
+		{Start: "{", End: "}"},
+	}
+
+	symbol_table_artefact, _, err := services.Analyse(scope_rules, syntax_tree, rules, type_rules)
+	if err != nil {
+		t.Fatalf("Error not expected: %v", err)
+	}
+
+	parents := []int{}
+	for _, scope := range symbol_table_artefact.Scopes {
+		parents = append(parents, scope.Parent)
+	}
+	if !reflect.DeepEqual(parents, []int{-1, 0, 1, 2}) {
+		t.Errorf("Incorrect scope parents: %v", parents)
+	}
+
+	for _, reference := range symbol_table_artefact.References {
+		if reference.Name == "a" && reference.Scope != 3 {
+			t.Errorf("Incorrect scope of reference: %v", reference.Scope)
+		}
+	}
+}
+
+func TestAnalyse_InterleavedScopes(t *testing.T) {
+	syntax_tree, rules, type_rules := createReturnExample(t, "int a = 1 ; { begin int b = a ; } end")
+
+	scope_rules := []*services.ScopeRule{
+		{Start: "{", End: "}"},
+		{Start: "begin", End: "end"},
+	}
+
+	_, _, diagnostics, err := services.AnalyseWithDiagnostics(scope_rules, syntax_tree, rules, type_rules, nil)
+	if err != nil {
+		t.Fatalf("Error not expected: %v", err)
+	}
+
+	expected_res := []string{
+		"mismatched_scope: end scope symbol } does not close the innermost scope, opened by begin",
+		"unclosed_scope: end scope symbol not found for start scope, please recheck source code",
+	}
+
+	found := []string{}
+	for _, diagnostic := range diagnostics {
+		found = append(found, diagnostic.Code+": "+diagnostic.Message)
+	}
+	if !reflect.DeepEqual(found, expected_res) {
+		t.Errorf("Incorrect diagnostics: %v", found)
+	}
+}
+
+func createCompositeExample(t *testing.T, structural bool, source_lines ...string) (services.SyntaxTree, services.GrammarRules, []services.TypeRule) {
+	grammar := services.Grammar{
+		Variables: []string{"PROGRAM", "STATEMENT", "RECORD", "FIELDS", "FIELD", "FUNCTION", "FUNCTION_DEFINITION", "PARAMETER", "DECLARATION", "ARRAY_TYPE", "FUNCTION_TYPE", "INDEX", "ACCESS", "CALL", "ELEMENT", "TYPE"},
+		Terminals: []string{"KEYWORD", "CONTROL", "IDENTIFIER", "ASSIGNMENT", "INTEGER", "FLOAT", "DELIMITER", "DOT", "OPEN_BRACKET", "CLOSE_BRACKET", "OPEN_INDEX", "CLOSE_INDEX", "OPEN_SCOPE", "CLOSE_SCOPE"},
+		Start:     "PROGRAM",
+		Rules: []services.ParsingRule{
+			{Input: "PROGRAM", Output: []string{"STATEMENT", "PROGRAM"}},
+			{Input: "PROGRAM", Output: []string{"STATEMENT"}},
+			{Input: "STATEMENT", Output: []string{"RECORD"}},
+			{Input: "STATEMENT", Output: []string{"FUNCTION"}},
+			{Input: "STATEMENT", Output: []string{"DECLARATION", "DELIMITER"}},
+			{Input: "RECORD", Output: []string{"CONTROL", "IDENTIFIER", "OPEN_SCOPE", "FIELDS", "CLOSE_SCOPE"}},
+			{Input: "FIELDS", Output: []string{"FIELD", "FIELDS"}},
+			{Input: "FIELDS", Output: []string{"FIELD"}},
+			{Input: "FIELD", Output: []string{"TYPE", "IDENTIFIER", "DELIMITER"}},
+			{Input: "FUNCTION", Output: []string{"FUNCTION_DEFINITION", "OPEN_SCOPE", "CLOSE_SCOPE"}},
+			{Input: "FUNCTION_DEFINITION", Output: []string{"TYPE", "IDENTIFIER", "OPEN_BRACKET", "PARAMETER", "CLOSE_BRACKET"}},
+			{Input: "PARAMETER", Output: []string{"TYPE", "IDENTIFIER"}},
+			{Input: "DECLARATION", Output: []string{"INDEX", "ASSIGNMENT", "ELEMENT"}},
+			{Input: "DECLARATION", Output: []string{"ACCESS", "ASSIGNMENT", "ELEMENT"}},
+			{Input: "DECLARATION", Output: []string{"ARRAY_TYPE", "IDENTIFIER", "ASSIGNMENT", "ELEMENT"}},
+			{Input: "DECLARATION", Output: []string{"ARRAY_TYPE", "IDENTIFIER"}},
+			{Input: "DECLARATION", Output: []string{"FUNCTION_TYPE", "IDENTIFIER", "ASSIGNMENT", "ELEMENT"}},
+			{Input: "DECLARATION", Output: []string{"TYPE", "IDENTIFIER", "ASSIGNMENT", "ELEMENT"}},
+			{Input: "DECLARATION", Output: []string{"TYPE", "IDENTIFIER"}},
+			{Input: "ARRAY_TYPE", Output: []string{"TYPE", "OPEN_INDEX", "INTEGER", "CLOSE_INDEX"}},
+			{Input: "FUNCTION_TYPE", Output: []string{"CONTROL", "OPEN_BRACKET", "TYPE", "CLOSE_BRACKET", "TYPE"}},
+			{Input: "INDEX", Output: []string{"IDENTIFIER", "OPEN_INDEX", "ELEMENT", "CLOSE_INDEX"}},
+			{Input: "ACCESS", Output: []string{"IDENTIFIER", "DOT", "IDENTIFIER"}},
+			{Input: "CALL", Output: []string{"IDENTIFIER", "OPEN_BRACKET", "ELEMENT", "CLOSE_BRACKET"}},
+			{Input: "ELEMENT", Output: []string{"INDEX"}},
+			{Input: "ELEMENT", Output: []string{"ACCESS"}},
+			{Input: "ELEMENT", Output: []string{"CALL"}},
+			{Input: "ELEMENT", Output: []string{"INTEGER"}},
+			{Input: "ELEMENT", Output: []string{"FLOAT"}},
+			{Input: "ELEMENT", Output: []string{"IDENTIFIER"}},
+			{Input: "TYPE", Output: []string{"KEYWORD"}},
+		},
+	}
+
+	token_types := map[string]string{
+		"int": "KEYWORD", "float": "KEYWORD", "Point": "KEYWORD", "Vec": "KEYWORD", "struct": "CONTROL", "func": "CONTROL",
+		"=": "ASSIGNMENT", ";": "DELIMITER", ".": "DOT", "(": "OPEN_BRACKET", ")": "CLOSE_BRACKET",
+		"[": "OPEN_INDEX", "]": "CLOSE_INDEX", "{": "OPEN_SCOPE", "}": "CLOSE_SCOPE",
+	}
+
+	source := createTokens(token_types, source_lines...)
+	for i := 1; i < len(source); i++ {
+		// the name of a record is declared by the record itself
+		if source[i-1].Value == "struct" {
+			source[i].Type = "IDENTIFIER"
+		}
+	}
+
+	syntax_tree, err := services.CreateSyntaxTree(source, grammar)
+	if err != nil {
+		t.Fatalf("parser failed: %v", err)
+	}
+
+	type_rules := []services.TypeRule{
+		{ResultData: "int", Assignment: "=", LHSData: "INTEGER", Operator: []string{}, RHSData: ""},
+		{ResultData: "float", Assignment: "=", LHSData: "FLOAT", Operator: []string{}, RHSData: ""},
+	}
+	rules := services.GrammarRules{
+		VariableRule:   "IDENTIFIER",
+		TypeRule:       "TYPE",
+		FunctionRule:   "FUNCTION_DEFINITION",
+		ParameterRule:  "PARAMETER",
+		AssignmentRule: "ASSIGNMENT",
+		TermRule:       "ELEMENT",
+		CallRule:       "CALL",
+		IndexRule:      "INDEX",
+		FieldRule:      "ACCESS",
+		TypeConstructors: []services.TypeConstructor{
+			{Node: "ARRAY_TYPE", Kind: "array"},
+			{Node: "FUNCTION_TYPE", Kind: "function"},
+			{Node: "RECORD", Kind: "record"},
+		},
+		StructuralTypes: structural,
+	}
+
+	return syntax_tree, rules, type_rules
+}
+
+func TestAnalyse_CompositeTypes_Valid(t *testing.T) {
+	syntax_tree, rules, type_rules := createCompositeExample(t, false,
+		"struct Point { int x ; int y ; }",
+		"int [ 3 ] a ;",
+		"Point p ;",
+		"int v = a [ 1 ] ;",
+		"int w = p . x ;",
+		"a [ 0 ] = 2 ;",
+		"p . y = w ;",
+		"int twice ( int n ) { }",
+		"func ( int ) int f = twice ;",
+		"int r = f ( 2 ) ;",
+	)
+
+	scope_rules := []*services.ScopeRule{
+		{Start: "{", End: "}"},
+	}
+
+	symbol_table_artefact, _, err := services.Analyse(scope_rules, syntax_tree, rules, type_rules)
+	if err != nil {
+		t.Fatalf("Error not expected: %v", err)
+	}
+
+	expected_res := map[string]string{
+		"a":     "int[3]",
+		"p":     "Point{x: int, y: int}",
+		"v":     "int",
+		"twice": "func(int) int",
+		"f":     "func(int) int",
+		"r":     "int",
+	}
+
+	for _, symbol := range symbol_table_artefact.SymbolScopes {
+		expected, found := expected_res[symbol.Name]
+		if !found {
+			continue
+		}
+
+		full_type := symbol.Type
+		if symbol.TypeInfo != nil {
+			full_type = services.TypeString(symbol.TypeInfo)
+		}
+		if full_type != expected {
+			t.Errorf("Incorrect type of %v: %v", symbol.Name, full_type)
+		}
+	}
+
+	if len(symbol_table_artefact.Types) != 1 || symbol_table_artefact.Types[0].Name != "Point" {
+		t.Errorf("Incorrect declared types: %v", symbol_table_artefact.Types)
+	}
+
+	output := services.StringifySymbolTable(symbol_table_artefact)
+	if !strings.Contains(output, "TYPES") || !strings.Contains(output, "Point{x: int, y: int}") || !strings.Contains(output, "int[3]") {
+		t.Errorf("Full types not printed: %v", output)
+	}
+}
+
+func TestAnalyse_CompositeTypes_Errors(t *testing.T) {
+	declarations := "struct Point { int x ; int y ; } int [ 3 ] a ; Point p ; int i = 1 ;"
+
+	tests := []struct {
+		source  string
+		code    string
+		message string
+	}{
+		{"int v = a [ 3 ] ;", "invalid_access", "error: index 3 out of range for a: int[3]"},
+		{"int v = a [ 2.5 ] ;", "invalid_access", "error: index of a must be int but is FLOAT"},
+		{"int v = i [ 0 ] ;", "invalid_access", "error: i is not an array: int"},
+		{"int v = p . z ;", "invalid_access", "error: p has no field z: Point{x: int, y: int}"},
+		{"int v = i . x ;", "invalid_access", "error: i is not a record: int"},
+		{"a [ 0 ] = 2.5 ;", "invalid_assignment", "error: invalid types assigned to: int a [ 0 ]"},
+		{"int [ 3 ] b = p ;", "invalid_assignment", "error: invalid types assigned to: int[3] b"},
+		{"struct Point { int z ; }", "redeclared_symbol", "error: type already declared: Point"},
+		{"struct Pair { int x ; float x ; }", "invalid_type", "error: field x declared more than once in record Pair"},
+	}
+
+	scope_rules := []*services.ScopeRule{
+		{Start: "{", End: "}"},
+	}
+
+	for _, test := range tests {
+		syntax_tree, rules, type_rules := createCompositeExample(t, false, declarations, test.source)
+
+		_, _, diagnostics, err := services.AnalyseWithDiagnostics(scope_rules, syntax_tree, rules, type_rules, nil)
+		if err != nil {
+			t.Fatalf("Error not expected: %v", err)
+		}
+
+		if len(diagnostics) != 1 {
+			t.Errorf("One diagnostic expected for %v: %v", test.source, diagnostics)
+			continue
+		}
+		if diagnostics[0].Code != test.code || diagnostics[0].Message != test.message {
+			t.Errorf("Incorrect diagnostic for %v: %v", test.source, diagnostics[0])
+		}
+	}
+}
+
+func TestAnalyse_CompositeTypes_Equality(t *testing.T) {
+	source := []string{
+		"struct Point { int x ; int y ; }",
+		"struct Vec { int x ; int y ; }",
+		"Point p ;",
+		"Vec v = p ;",
+	}
+
+	scope_rules := []*services.ScopeRule{
+		{Start: "{", End: "}"},
+	}
+
+	syntax_tree, rules, type_rules := createCompositeExample(t, false, source...)
+	_, _, diagnostics, _ := services.AnalyseWithDiagnostics(scope_rules, syntax_tree, rules, type_rules, nil)
+
+	if len(diagnostics) != 1 || diagnostics[0].Message != "error: invalid types assigned to: Vec v" {
+		t.Errorf("Records with different names should differ: %v", diagnostics)
+	}
+
+	syntax_tree, rules, type_rules = createCompositeExample(t, true, source...)
+	_, _, diagnostics, _ = services.AnalyseWithDiagnostics(scope_rules, syntax_tree, rules, type_rules, nil)
+
+	if len(diagnostics) != 0 {
+		t.Errorf("Records with the same fields should be equal: %v", diagnostics)
+	}
+}
+
+func TestTypesEqual(t *testing.T) {
+	point := services.RecordType("Point", []services.Field{{Name: "x", Type: services.BasicType("int")}})
+	vec := services.RecordType("Vec", []services.Field{{Name: "x", Type: services.BasicType("int")}})
+
+	tests := []struct {
+		first      *services.Type
+		second     *services.Type
+		structural bool
+		expected   bool
+	}{
+		{services.ArrayType(services.BasicType("int"), 3), services.ArrayType(services.BasicType("int"), 3), false, true},
+		{services.ArrayType(services.BasicType("int"), 3), services.ArrayType(services.BasicType("int"), 4), false, false},
+		{point, vec, false, false},
+		{point, vec, true, true},
+		{services.ArrayType(point, -1), services.ArrayType(vec, -1), true, true},
+		{services.FunctionType([]*services.Type{point}, services.BasicType("int")), services.FunctionType([]*services.Type{vec}, services.BasicType("int")), false, false},
+		{services.FunctionType([]*services.Type{services.BasicType("int")}, services.BasicType("int")), services.FunctionType([]*services.Type{}, services.BasicType("int")), true, false},
+	}
+
+	for _, test := range tests {
+		if services.TypesEqual(test.first, test.second, test.structural) != test.expected {
+			t.Errorf("Incorrect equality of %v and %v", services.TypeString(test.first), services.TypeString(test.second))
+		}
+	}
+
+	if services.TypeString(services.FunctionType([]*services.Type{point, services.ArrayType(services.BasicType("float"), -1)}, services.BasicType("int"))) != "func(Point{x: int}, float[]) int" {
+		t.Errorf("Incorrect type string")
+	}
+}
+
+func createConstantExample(t *testing.T, source_lines ...string) (services.SyntaxTree, services.GrammarRules, []services.TypeRule) {
+	grammar := services.Grammar{
+		Variables: []string{"PROGRAM", "STATEMENT", "DECLARATION", "ARRAY_TYPE", "EXPRESSION", "ELEMENT", "TYPE"},
+		Terminals: []string{"KEYWORD", "IDENTIFIER", "ASSIGNMENT", "OPERATOR", "INTEGER", "FLOAT", "DELIMITER", "OPEN_BRACKET", "CLOSE_BRACKET", "OPEN_INDEX", "CLOSE_INDEX"},
+		Start:     "PROGRAM",
+		Rules: []services.ParsingRule{
+			{Input: "PROGRAM", Output: []string{"STATEMENT", "PROGRAM"}},
+			{Input: "PROGRAM", Output: []string{"STATEMENT"}},
+			{Input: "STATEMENT", Output: []string{"DECLARATION", "DELIMITER"}},
+			{Input: "DECLARATION", Output: []string{"TYPE", "IDENTIFIER", "ASSIGNMENT", "EXPRESSION"}},
+			{Input: "DECLARATION", Output: []string{"ARRAY_TYPE", "IDENTIFIER"}},
+			{Input: "ARRAY_TYPE", Output: []string{"TYPE", "OPEN_INDEX", "EXPRESSION", "CLOSE_INDEX"}},
+			{Input: "EXPRESSION", Output: []string{"ELEMENT", "OPERATOR", "EXPRESSION"}},
+			{Input: "EXPRESSION", Output: []string{"ELEMENT"}},
+			{Input: "ELEMENT", Output: []string{"OPEN_BRACKET", "EXPRESSION", "CLOSE_BRACKET"}},
+			{Input: "ELEMENT", Output: []string{"INTEGER"}},
+			{Input: "ELEMENT", Output: []string{"FLOAT"}},
+			{Input: "ELEMENT", Output: []string{"IDENTIFIER"}},
+			{Input: "TYPE", Output: []string{"KEYWORD"}},
+		},
+	}
+
+	token_types := map[string]string{
+		"int": "KEYWORD", "float": "KEYWORD", "=": "ASSIGNMENT", ";": "DELIMITER",
+		"+": "OPERATOR", "-": "OPERATOR", "*": "OPERATOR", "/": "OPERATOR", "%": "OPERATOR",
+		"(": "OPEN_BRACKET", ")": "CLOSE_BRACKET", "[": "OPEN_INDEX", "]": "CLOSE_INDEX",
+	}
+
+	syntax_tree := createTree(t, grammar, token_types, source_lines...)
+
+	type_rules := []services.TypeRule{
+		{ResultData: "int", Assignment: "=", LHSData: "INTEGER", Operator: []string{}, RHSData: ""},
+		{ResultData: "float", Assignment: "=", LHSData: "FLOAT", Operator: []string{}, RHSData: ""},
+		{ResultData: "int", Assignment: "=", LHSData: "int", Operator: []string{"+", "-", "*", "/", "%"}, RHSData: "int"},
+		{ResultData: "float", Assignment: "=", LHSData: "float", Operator: []string{"+", "-", "*", "/"}, RHSData: "float"},
+	}
+	rules := services.GrammarRules{
+		VariableRule:   "IDENTIFIER",
+		TypeRule:       "TYPE",
+		AssignmentRule: "ASSIGNMENT",
+		OperatorRule:   "OPERATOR",
+		TermRule:       "ELEMENT",
+		TypeConstructors: []services.TypeConstructor{
+			{Node: "ARRAY_TYPE", Kind: "array"},
+		},
+	}
+
+	return syntax_tree, rules, type_rules
+}
+
+func TestAnalyse_ConstantValues(t *testing.T) {
+	syntax_tree, rules, type_rules := createConstantExample(t,
+		"int a = 1 ;",
+		"int q = 7 / 2 ;",
+		"int s = ( 2 * 3 ) + a ;",
+		"int r = ( 2 * 3 ) + 1 ;",
+		"float h = 7.0 / 2 ;",
+		"int [ 2 * 3 ] c ;",
+	)
+	promotions := []services.Promotion{{From: "int", To: "float"}}
+
+	symbol_table_artefact, _, diagnostics, err := services.AnalyseWithDiagnostics(nil, syntax_tree, rules, type_rules, promotions)
+	if err != nil {
+		t.Fatalf("Error not expected: %v", err)
+	}
+	if len(diagnostics) != 0 {
+		t.Fatalf("No diagnostics expected: %v", diagnostics)
+	}
+
+	expected_res := map[string]string{
+		"a": "1",
+		"q": "3",
+		"s": "",
+		"r": "7",
+		"h": "3.5",
+	}
+
+	for _, symbol := range symbol_table_artefact.SymbolScopes {
+		if expected, found := expected_res[symbol.Name]; found && symbol.Constant != expected {
+			t.Errorf("Incorrect constant of %v: %v", symbol.Name, symbol.Constant)
+		}
+		if symbol.Name == "c" && (symbol.TypeInfo == nil || services.TypeString(symbol.TypeInfo) != "int[6]") {
+			t.Errorf("Incorrect array type: %v", symbol.Type)
+		}
+	}
+}
+
+func TestAnalyse_ConstantValues_TypeRuleSemantics(t *testing.T) {
+	syntax_tree, rules, _ := createConstantExample(t, "float q = 7 / 2 ;")
+
+	// dividing two ints gives a float, so the division is not an integer division
+	type_rules := []services.TypeRule{
+		{ResultData: "int", Assignment: "=", LHSData: "INTEGER", Operator: []string{}, RHSData: ""},
+		{ResultData: "float", Assignment: "=", LHSData: "int", Operator: []string{"/"}, RHSData: "int"},
+	}
+
+	symbol_table_artefact, _, err := services.Analyse(nil, syntax_tree, rules, type_rules)
+	if err != nil {
+		t.Fatalf("Error not expected: %v", err)
+	}
+
+	if symbol_table_artefact.SymbolScopes[0].Constant != "3.5" {
+		t.Errorf("Incorrect constant: %v", symbol_table_artefact.SymbolScopes[0].Constant)
+	}
+}
+
+func TestAnalyse_ConstantValues_Errors(t *testing.T) {
+	tests := []struct {
+		source  string
+		code    string
+		message string
+	}{
+		{"int r = 4 / ( 2 - 2 ) ;", "invalid_constant", "error: division by zero in 4 / ( 2 - 2 )"},
+		{"int r = 1 + 5 % 0 ;", "invalid_constant", "error: modulo by zero in 5 % 0"},
+		{"int [ a ] c ;", "invalid_type", "error: array size is not constant: a"},
+		{"int [ 2.5 ] c ;", "invalid_type", "error: array size must be a non-negative integer: 2.5"},
+		{"int [ 1 / 0 ] c ;", "invalid_type", "error: division by zero in 1 / 0"},
+	}
+
+	for _, test := range tests {
+		syntax_tree, rules, type_rules := createConstantExample(t, "int a = 1 ;", "int b = a / 0 ;", test.source)
+
+		_, _, diagnostics, err := services.AnalyseWithDiagnostics(nil, syntax_tree, rules, type_rules, nil)
+		if err != nil {
+			t.Fatalf("Error not expected: %v", err)
+		}
+
+		if len(diagnostics) != 1 {
+			t.Errorf("One diagnostic expected for %v: %v", test.source, diagnostics)
+			continue
+		}
+		if diagnostics[0].Code != test.code || diagnostics[0].Message != test.message {
+			t.Errorf("Incorrect diagnostic for %v: %v", test.source, diagnostics[0])
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package unit_tests
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/COS301-SE-2025/Visual-Compiler/backend/core/services"
+)
+
+func createDeclarationGrammar() services.Grammar {
+	return services.Grammar{
+		Variables: []string{"STATEMENT", "DECLARATION", "EXPRESSION", "TYPE", "TERM"},
+		Terminals: []string{"KEYWORD", "IDENTIFIER", "ASSIGNMENT", "INTEGER", "OPERATOR", "SEPARATOR"},
+		Start:     "STATEMENT",
+		Rules: []services.ParsingRule{
+			{Input: "STATEMENT", Output: []string{"DECLARATION", "SEPARATOR"}},
+			{Input: "DECLARATION", Output: []string{"TYPE", "IDENTIFIER", "ASSIGNMENT", "EXPRESSION"}},
+			{Input: "EXPRESSION", Output: []string{"TERM", "OPERATOR", "TERM"}},
+			{Input: "TERM", Output: []string{"INTEGER"}},
+			{Input: "TYPE", Output: []string{"KEYWORD"}},
+		},
+	}
+}
+
+func createDeclarationTokens() []services.TypeValue {
+	return createTokens(map[string]string{"int": "KEYWORD", "=": "ASSIGNMENT", "+": "OPERATOR", ";": "SEPARATOR"}, "int blue = 13 + 89 ;")
+}
+
+func createDeclarationTree(t *testing.T) services.SyntaxTree {
+	syntax_tree, err := services.CreateSyntaxTree(createDeclarationTokens(), createDeclarationGrammar())
+	if err != nil {
+		t.Fatalf("parser failed: %v", err)
+	}
+
+	return syntax_tree
+}
+
+func TestCreateAbstractSyntaxTree_EmptyTree(t *testing.T) {
+	_, err := services.CreateAbstractSyntaxTree(services.SyntaxTree{}, []services.ASTRule{})
+
+	if err == nil {
+		t.Errorf("Error expected for empty tree")
+	} else {
+		if err.Error() != fmt.Errorf("syntax tree is empty").Error() {
+			t.Errorf("Incorrect error received: %v", err)
+		}
+	}
+}
+
+func TestCreateAbstractSyntaxTree_DuplicateRule(t *testing.T) {
+	rules := []services.ASTRule{
+		{Symbol: "EXPRESSION", Promote: "OPERATOR"},
+		{Symbol: "EXPRESSION", Name: "EXPR"},
+	}
+
+	_, err := services.CreateAbstractSyntaxTree(createDeclarationTree(t), rules)
+
+	if err == nil {
+		t.Errorf("Error expected for duplicate rule")
+	} else {
+		if err.Error() != fmt.Errorf("more than one abstract syntax tree rule for symbol: EXPRESSION").Error() {
+			t.Errorf("Incorrect error received: %v", err)
+		}
+	}
+}
+
+func TestCreateAbstractSyntaxTree_PromoteNotKept(t *testing.T) {
+	rules := []services.ASTRule{
+		{Symbol: "EXPRESSION", Keep: []string{"TERM"}, Promote: "OPERATOR"},
+	}
+
+	_, err := services.CreateAbstractSyntaxTree(createDeclarationTree(t), rules)
+
+	if err == nil {
+		t.Errorf("Error expected for promoted symbol that is not kept")
+	} else {
+		if err.Error() != fmt.Errorf("promoted symbol OPERATOR is not kept for symbol: EXPRESSION").Error() {
+			t.Errorf("Incorrect error received: %v", err)
+		}
+	}
+}
+
+func TestCreateAbstractSyntaxTree_CollapseChains(t *testing.T) {
+
+	expected_res := `
+└──  STATEMENT
+    ├──  DECLARATION
+    │   ├──  KEYWORD: int
+    │   ├──  IDENTIFIER: blue
+    │   ├──  ASSIGNMENT: =
+    │   └──  EXPRESSION
+    │       ├──  INTEGER: 13
+    │       ├──  OPERATOR: +
+    │       └──  INTEGER: 89
+    └──  SEPARATOR: ;`
+
+	ast, err := services.CreateAbstractSyntaxTree(createDeclarationTree(t), []services.ASTRule{})
+
+	if err != nil {
+		t.Errorf("Error not expected: %v", err)
+	} else {
+		tree_string := services.ConvertTreeToString(ast.Root, "", true)
+		if strings.TrimSpace(tree_string) != strings.TrimSpace(expected_res) {
+			t.Errorf("Incorrect tree: \n%v", tree_string)
+		}
+	}
+}
+
+func TestCreateAbstractSyntaxTree_Annotated(t *testing.T) {
+
+	expected_res := `
+└──  DECLARE
+    ├──  TYPE
+    │   └──  KEYWORD: int
+    ├──  IDENTIFIER: blue
+    └──  OPERATOR: +
+        ├──  INTEGER: 13
+        └──  INTEGER: 89`
+
+	rules := []services.ASTRule{
+		{Symbol: "STATEMENT", Keep: []string{"DECLARATION"}, Promote: "DECLARATION"},
+		{Symbol: "DECLARATION", Keep: []string{"TYPE", "IDENTIFIER", "EXPRESSION"}, Name: "DECLARE"},
+		{Symbol: "EXPRESSION", Promote: "OPERATOR"},
+		{Symbol: "TYPE"},
+	}
+
+	syntax_tree := createDeclarationTree(t)
+	ast, err := services.CreateAbstractSyntaxTree(syntax_tree, rules)
+
+	if err != nil {
+		t.Errorf("Error not expected: %v", err)
+	} else {
+		tree_string := services.ConvertTreeToString(ast.Root, "", true)
+		if strings.TrimSpace(tree_string) != strings.TrimSpace(expected_res) {
+			t.Errorf("Incorrect tree: \n%v", tree_string)
+		}
+	}
+
+	if syntax_tree.Root.Symbol != "STATEMENT" || len(syntax_tree.Root.Children) != 2 {
+		t.Errorf("Parse tree was modified")
+	}
+}
+
+func TestCreateAbstractSyntaxTree_EmptyVariables(t *testing.T) {
+
+	syntax_tree := services.SyntaxTree{
+		Root: &services.TreeNode{
+			Symbol: "LIST",
+			Children: []*services.TreeNode{
+				{Symbol: "STRING", Value: "red"},
+				{Symbol: "LIST_REP1", Children: []*services.TreeNode{}},
+			},
+		},
+	}
+
+	ast, err := services.CreateAbstractSyntaxTree(syntax_tree, []services.ASTRule{})
+
+	if err != nil {
+		t.Errorf("Error not expected: %v", err)
+	} else if ast.Root.Symbol != "STRING" || ast.Root.Value != "red" {
+		t.Errorf("Incorrect tree: \n%v", services.ConvertTreeToString(ast.Root, "", true))
+	}
+}
@@ -0,0 +1,312 @@
+package unit_tests
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/COS301-SE-2025/Visual-Compiler/backend/core/services"
+)
+
+func createCalculatorTree(t *testing.T) services.SyntaxTree {
+	grammar := services.Grammar{
+		Variables: []string{"EXPRESSION", "TERM", "FACTOR"},
+		Terminals: []string{"INTEGER"},
+		Start:     "EXPRESSION",
+		Rules: []services.ParsingRule{
+			{Input: "EXPRESSION", Output: []string{"TERM", "'+'", "EXPRESSION"}},
+			{Input: "EXPRESSION", Output: []string{"TERM"}},
+			{Input: "TERM", Output: []string{"FACTOR", "'*'", "TERM"}},
+			{Input: "TERM", Output: []string{"FACTOR"}},
+			{Input: "FACTOR", Output: []string{"INTEGER"}},
+		},
+	}
+
+	return createTree(t, grammar, map[string]string{"+": "OPERATOR", "*": "OPERATOR"}, "2 + 3 * 4")
+}
+
+func createCalculatorAttributes() services.AttributeGrammar {
+	return services.AttributeGrammar{
+		Rules: []services.AttributeRule{
+			{Input: "EXPRESSION", Output: []string{"TERM", "'+'", "EXPRESSION"}, Equations: []services.AttributeEquation{
+				{Target: "$0.val", Expression: "$1.val + $3.val"},
+			}},
+			{Input: "EXPRESSION", Output: []string{"TERM"}, Equations: []services.AttributeEquation{
+				{Target: "$0.val", Expression: "$1.val"},
+			}},
+			{Input: "TERM", Output: []string{"FACTOR", "'*'", "TERM"}, Equations: []services.AttributeEquation{
+				{Target: "$0.val", Expression: "$1.val * $3.val"},
+			}},
+			{Input: "TERM", Output: []string{"FACTOR"}, Equations: []services.AttributeEquation{
+				{Target: "$0.val", Expression: "$1.val"},
+			}},
+			{Input: "FACTOR", Output: []string{"INTEGER"}, Equations: []services.AttributeEquation{
+				{Target: "$0.val", Expression: "num($1.lexeme)"},
+			}},
+		},
+	}
+}
+
+func createDeclarationListTree(t *testing.T) services.SyntaxTree {
+	grammar := services.Grammar{
+		Variables: []string{"DECLARATION", "TYPE", "LIST"},
+		Terminals: []string{"KEYWORD", "IDENTIFIER", "SEPARATOR"},
+		Start:     "DECLARATION",
+		Rules: []services.ParsingRule{
+			{Input: "DECLARATION", Output: []string{"TYPE", "LIST"}},
+			{Input: "TYPE", Output: []string{"KEYWORD"}},
+			{Input: "LIST", Output: []string{"IDENTIFIER", "SEPARATOR", "LIST"}},
+			{Input: "LIST", Output: []string{"IDENTIFIER"}},
+		},
+	}
+
+	return createTree(t, grammar, map[string]string{"int": "KEYWORD", ",": "SEPARATOR"}, "int a , b , c")
+}
+
+func TestEvaluateAttributes_Synthesised(t *testing.T) {
+	attributed_tree, err := services.EvaluateAttributes(createCalculatorTree(t), createCalculatorAttributes())
+
+	if err != nil {
+		t.Fatalf("Error not expected: %v", err)
+	}
+
+	if attributed_tree.Root.Attributes["val"] != float64(14) {
+		t.Errorf("Incorrect value: %v", attributed_tree.Root.Attributes["val"])
+	}
+
+	product := attributed_tree.Root.Children[2].Children[0]
+	if product.Symbol != "TERM" || product.Attributes["val"] != float64(12) {
+		t.Errorf("Incorrect value of product: %v %v", product.Symbol, product.Attributes["val"])
+	}
+
+	if len(attributed_tree.Order) != 8 {
+		t.Fatalf("Incorrect number of attributes evaluated: %v", len(attributed_tree.Order))
+	}
+
+	last := attributed_tree.Order[len(attributed_tree.Order)-1]
+	if last.Node != 0 || last.Symbol != "EXPRESSION" || last.Attribute != "val" {
+		t.Errorf("Root expected to be evaluated last: %v", last)
+	}
+}
+
+func TestEvaluateAttributes_Inherited(t *testing.T) {
+	attribute_grammar := services.AttributeGrammar{
+		Rules: []services.AttributeRule{
+			{Input: "DECLARATION", Output: []string{"TYPE", "LIST"}, Equations: []services.AttributeEquation{
+				{Target: "$0.names", Expression: "$2.names"},
+				{Target: "$2.type", Expression: "$1.name"},
+			}},
+			{Input: "TYPE", Output: []string{"KEYWORD"}, Equations: []services.AttributeEquation{
+				{Target: "$0.name", Expression: "$1.lexeme"},
+			}},
+			{Input: "LIST", Output: []string{"IDENTIFIER", "SEPARATOR", "LIST"}, Equations: []services.AttributeEquation{
+				{Target: "$3.type", Expression: "$0.type"},
+				{Target: "$0.names", Expression: "concat($1.lexeme, ':', $0.type, ' ', $3.names)"},
+			}},
+			{Input: "LIST", Output: []string{"IDENTIFIER"}, Equations: []services.AttributeEquation{
+				{Target: "$0.names", Expression: "concat($1.lexeme, ':', $0.type)"},
+			}},
+		},
+	}
+
+	attributed_tree, err := services.EvaluateAttributes(createDeclarationListTree(t), attribute_grammar)
+
+	if err != nil {
+		t.Fatalf("Error not expected: %v", err)
+	}
+
+	if attributed_tree.Root.Attributes["names"] != "a:int b:int c:int" {
+		t.Errorf("Incorrect names: %v", attributed_tree.Root.Attributes["names"])
+	}
+
+	expected_res := `└──  DECLARATION [names=a:int b:int c:int]
+    ├──  TYPE [name=int]
+    │   └──  KEYWORD: int
+    └──  LIST [names=a:int b:int c:int, type=int]
+        ├──  IDENTIFIER: a
+        ├──  SEPARATOR: ,
+        └──  LIST [names=b:int c:int, type=int]
+            ├──  IDENTIFIER: b
+            ├──  SEPARATOR: ,
+            └──  LIST [names=c:int, type=int]
+                └──  IDENTIFIER: c
+`
+
+	if services.ConvertAttributedTreeToString(attributed_tree.Root, "", true) != expected_res {
+		t.Errorf("Incorrect tree: \n%v", services.ConvertAttributedTreeToString(attributed_tree.Root, "", true))
+	}
+}
+
+func TestEvaluateAttributes_Circular(t *testing.T) {
+	grammar := services.Grammar{
+		Variables: []string{"S", "A"},
+		Terminals: []string{"X"},
+		Start:     "S",
+		Rules: []services.ParsingRule{
+			{Input: "S", Output: []string{"A"}},
+			{Input: "A", Output: []string{"X"}},
+		},
+	}
+
+	syntax_tree := createTree(t, grammar, map[string]string{"x": "X"}, "x")
+
+	attribute_grammar := services.AttributeGrammar{
+		Rules: []services.AttributeRule{
+			{Input: "S", Output: []string{"A"}, Equations: []services.AttributeEquation{
+				{Target: "$1.down", Expression: "$1.up"},
+			}},
+			{Input: "A", Output: []string{"X"}, Equations: []services.AttributeEquation{
+				{Target: "$0.up", Expression: "$0.down + 1"},
+			}},
+		},
+	}
+
+	_, err := services.EvaluateAttributes(syntax_tree, attribute_grammar)
+
+	if err == nil {
+		t.Errorf("Error expected for circular attributes")
+	} else if err.Error() != "circular attribute dependency: A.down (node 1) -> A.up (node 1) -> A.down (node 1)" {
+		t.Errorf("Incorrect error: %v", err)
+	}
+}
+
+func TestEvaluateAttributes_InvalidRules(t *testing.T) {
+	tests := []struct {
+		equations []services.AttributeEquation
+		err       string
+	}{
+		{
+			[]services.AttributeEquation{{Target: "$0.a", Expression: "$0.b"}, {Target: "$0.b", Expression: "$0.a + 1"}},
+			"circular attribute equations in FACTOR -> INTEGER: $0.a -> $0.b -> $0.a",
+		},
+		{
+			[]services.AttributeEquation{{Target: "$0.val", Expression: "1"}, {Target: "$0.val", Expression: "2"}},
+			"attribute $0.val defined more than once in FACTOR -> INTEGER",
+		},
+		{
+			[]services.AttributeEquation{{Target: "$2.val", Expression: "1"}},
+			"target $2.val refers to a symbol that FACTOR -> INTEGER does not have",
+		},
+		{
+			[]services.AttributeEquation{{Target: "$0.val", Expression: "$1.val +"}},
+			"invalid expression for $0.val in FACTOR -> INTEGER: unexpected end of the expression",
+		},
+		{
+			[]services.AttributeEquation{{Target: "$0.lexeme", Expression: "1"}},
+			"the lexeme attribute cannot be defined: FACTOR -> INTEGER",
+		},
+	}
+
+	for _, test := range tests {
+		attribute_grammar := createCalculatorAttributes()
+		attribute_grammar.Rules[4].Equations = test.equations
+
+		_, err := services.EvaluateAttributes(createCalculatorTree(t), attribute_grammar)
+
+		if err == nil {
+			t.Errorf("Error expected: %v", test.err)
+		} else if err.Error() != test.err {
+			t.Errorf("Incorrect error: %v", err)
+		}
+	}
+}
+
+func TestEvaluateAttributes_MissingEquation(t *testing.T) {
+	attribute_grammar := createCalculatorAttributes()
+	attribute_grammar.Rules = attribute_grammar.Rules[:4]
+
+	_, err := services.EvaluateAttributes(createCalculatorTree(t), attribute_grammar)
+
+	if err == nil {
+		t.Errorf("Error expected for attribute without an equation")
+	} else if err.Error() != "attribute val of FACTOR (node 2) has no equation" {
+		t.Errorf("Incorrect error: %v", err)
+	}
+}
+
+func TestEvaluateAttributeExpression(t *testing.T) {
+	tests := []struct {
+		expression string
+		value      interface{}
+	}{
+		{"1 + 2 * 3", float64(7)},
+		{"(1 + 2) * 3", float64(9)},
+		{"10 - 4 - 3", float64(3)},
+		{"7 % 4", float64(3)},
+		{"-2 * -3", float64(6)},
+		{"1 + 2 * 3 == 7 ? 'yes' : 'no'", "yes"},
+		{"1 < 2 && !(3 <= 2) || false", true},
+		{"'a' + \"b\"", "ab"},
+		{"len('four')", float64(4)},
+		{"max(2, min(8, 5))", float64(5)},
+		{"str(2.5) + concat(1, true)", "2.51true"},
+		{"num('12') / 4", float64(3)},
+	}
+
+	for _, test := range tests {
+		expression, err := services.ParseAttributeExpression(test.expression)
+		if err != nil {
+			t.Errorf("Error not expected for %v: %v", test.expression, err)
+			continue
+		}
+
+		value, err := services.EvaluateAttributeExpression(expression, []*services.TreeNode{}, nil)
+		if err != nil {
+			t.Errorf("Error not expected for %v: %v", test.expression, err)
+		} else if value != test.value {
+			t.Errorf("Incorrect value for %v: %v", test.expression, value)
+		}
+	}
+}
+
+func TestEvaluateAttributeExpression_Invalid(t *testing.T) {
+	tests := []struct {
+		expression string
+		err        string
+	}{
+		{"1 / 0", "division by zero"},
+		{"1 + 'a'", "operator + cannot be applied to 1 and a"},
+		{"1 ? 2 : 3", "condition is not a boolean: 1"},
+		{"num('one')", "num cannot convert one to a number"},
+	}
+
+	for _, test := range tests {
+		expression, err := services.ParseAttributeExpression(test.expression)
+		if err != nil {
+			t.Errorf("Error not expected for %v: %v", test.expression, err)
+			continue
+		}
+
+		_, err = services.EvaluateAttributeExpression(expression, []*services.TreeNode{}, nil)
+		if err == nil {
+			t.Errorf("Error expected for %v", test.expression)
+		} else if err.Error() != test.err {
+			t.Errorf("Incorrect error for %v: %v", test.expression, err)
+		}
+	}
+}
+
+func TestParseAttributeExpression_Invalid(t *testing.T) {
+	tests := []struct {
+		expression string
+		err        error
+	}{
+		{"", fmt.Errorf("empty attribute expression")},
+		{"1 +", fmt.Errorf("unexpected end of the expression")},
+		{"(1 + 2", fmt.Errorf("expected ')' at the end of the expression")},
+		{"$a.val", fmt.Errorf("invalid reference at position 0: references are written as $1.attribute")},
+		{"1 = 2", fmt.Errorf("unexpected character '=' at position 2")},
+		{"exec('rm')", fmt.Errorf("unknown function at position 0: exec")},
+		{"min(1)", fmt.Errorf("function min expects 2 arguments but 1 were given")},
+		{"1 2", fmt.Errorf("unexpected '2' at position 2")},
+	}
+
+	for _, test := range tests {
+		_, err := services.ParseAttributeExpression(test.expression)
+
+		if err == nil {
+			t.Errorf("Error expected for %v", test.expression)
+		} else if err.Error() != test.err.Error() {
+			t.Errorf("Incorrect error for %v: %v", test.expression, err)
+		}
+	}
+}
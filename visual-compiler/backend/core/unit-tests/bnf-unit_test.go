@@ -0,0 +1,236 @@
+package unit_tests
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/COS301-SE-2025/Visual-Compiler/backend/core/services"
+)
+
+func TestReadGrammarNotation_NoGrammar(t *testing.T) {
+	_, err := services.ReadGrammarNotation("   ")
+
+	if err == nil {
+		t.Errorf("Error expected for no grammar")
+	} else {
+		if err.Error() != fmt.Errorf("no grammar entered").Error() {
+			t.Errorf("Incorrect error received: %v", err)
+		}
+	}
+}
+
+func TestReadGrammarNotation_NoProductions(t *testing.T) {
+	_, err := services.ReadGrammarNotation(`INTEGER OPERATOR INTEGER`)
+
+	if err == nil {
+		t.Errorf("Error expected for no productions")
+	} else {
+		if err.Error() != fmt.Errorf("no productions found in grammar notation").Error() {
+			t.Errorf("Incorrect error received: %v", err)
+		}
+	}
+}
+
+func TestReadGrammarNotation_UnterminatedQuote(t *testing.T) {
+	_, err := services.ReadGrammarNotation(`EXPR ::= TERM "+ TERM`)
+
+	if err == nil {
+		t.Errorf("Error expected for unterminated quote")
+	} else {
+		if err.Error() != fmt.Errorf("unterminated quoted terminal on line 1").Error() {
+			t.Errorf("Incorrect error received: %v", err)
+		}
+	}
+}
+
+func TestReadGrammarNotation_MissingBracket(t *testing.T) {
+	_, err := services.ReadGrammarNotation(`EXPR ::= TERM { "+" TERM`)
+
+	if err == nil {
+		t.Errorf("Error expected for missing bracket")
+	} else {
+		if err.Error() != fmt.Errorf("missing closing '}' in production EXPR").Error() {
+			t.Errorf("Incorrect error received: %v", err)
+		}
+	}
+}
+
+func TestReadGrammarNotation_LeftRecursion(t *testing.T) {
+	_, err := services.ReadGrammarNotation(`EXPR ::= EXPR "+" TERM | TERM
+TERM ::= INTEGER`)
+
+	if err == nil {
+		t.Errorf("Error expected for left recursion")
+	} else {
+		if err.Error() != fmt.Errorf("grammar includes left recursion").Error() {
+			t.Errorf("Incorrect error received: %v", err)
+		}
+	}
+}
+
+func TestReadGrammarNotation_BNF(t *testing.T) {
+
+	expected_res := services.Grammar{
+		Variables: []string{"STATEMENT", "DECLARATION", "TYPE"},
+		Terminals: []string{"SEPARATOR", "IDENTIFIER", "ASSIGNMENT", "INTEGER", "KEYWORD"},
+		Start:     "STATEMENT",
+		Rules: []services.ParsingRule{
+			{Input: "STATEMENT", Output: []string{"DECLARATION", "SEPARATOR"}},
+			{Input: "DECLARATION", Output: []string{"TYPE", "IDENTIFIER", "ASSIGNMENT", "INTEGER"}},
+			{Input: "TYPE", Output: []string{"KEYWORD"}},
+		},
+	}
+
+	grammar, err := services.ReadGrammarNotation(`
+		# a single declaration
+		<STATEMENT> ::= <DECLARATION> SEPARATOR
+		DECLARATION ::= TYPE IDENTIFIER ASSIGNMENT INTEGER
+		TYPE -> keyword
+	`)
+
+	if err != nil {
+		t.Errorf("Error not expected: %v", err)
+	} else if !reflect.DeepEqual(grammar, expected_res) {
+		t.Errorf("Incorrect grammar: %v", grammar)
+	}
+}
+
+func TestReadGrammarNotation_EBNF(t *testing.T) {
+
+	expected_rules := []services.ParsingRule{
+		{Input: "EXPR", Output: []string{"TERM", "EXPR_REP2"}},
+		{Input: "EXPR_REP2", Output: []string{"EXPR_GRP1", "TERM", "EXPR_REP2"}},
+		{Input: "EXPR_REP2", Output: []string{"ε"}},
+		{Input: "EXPR_GRP1", Output: []string{"'+'"}},
+		{Input: "EXPR_GRP1", Output: []string{"'-'"}},
+		{Input: "TERM", Output: []string{"TERM_OPT1", "INTEGER"}},
+		{Input: "TERM_OPT1", Output: []string{"'-'"}},
+		{Input: "TERM_OPT1", Output: []string{"ε"}},
+		{Input: "TERM", Output: []string{"IDENTIFIER"}},
+	}
+
+	grammar, err := services.ReadGrammarNotation(`EXPR ::= TERM { ( "+" | "-" ) TERM }
+TERM ::= [ "-" ] INTEGER | IDENTIFIER`)
+
+	if err != nil {
+		t.Errorf("Error not expected: %v", err)
+		return
+	}
+
+	if grammar.Start != "EXPR" {
+		t.Errorf("Incorrect start: %v", grammar.Start)
+	}
+
+	if len(grammar.Rules) != len(expected_rules) {
+		t.Fatalf("Incorrect rules: %v", grammar.Rules)
+	}
+
+	for _, expected := range expected_rules {
+		found := false
+		for _, rule := range grammar.Rules {
+			if reflect.DeepEqual(rule, expected) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Rule not found: %v", expected)
+		}
+	}
+
+	if !reflect.DeepEqual(grammar.Terminals, []string{"INTEGER", "IDENTIFIER"}) {
+		t.Errorf("Incorrect terminals: %v", grammar.Terminals)
+	}
+}
+
+func TestReadGrammarNotation_ParsesTokens(t *testing.T) {
+
+	tokens := []services.TypeValue{
+		{Type: "INTEGER", Value: "1"},
+		{Type: "OPERATOR", Value: "+"},
+		{Type: "INTEGER", Value: "2"},
+		{Type: "OPERATOR", Value: "-"},
+		{Type: "OPERATOR", Value: "-"},
+		{Type: "INTEGER", Value: "3"},
+	}
+
+	grammar, err := services.ReadGrammarNotation(`EXPR ::= TERM { ( "+" | "-" ) TERM }
+TERM ::= [ "-" ] INTEGER`)
+
+	if err != nil {
+		t.Fatalf("Error not expected: %v", err)
+	}
+
+	syntax_tree, err := services.CreateSyntaxTree(tokens, grammar)
+	if err != nil {
+		t.Errorf("Error not expected: %v", err)
+	} else {
+		values := []string{}
+		for _, leaf := range services.LeafNodes(syntax_tree.Root) {
+			if leaf.Value != "" {
+				values = append(values, leaf.Value)
+			}
+		}
+		if strings.Join(values, " ") != "1 + 2 - - 3" {
+			t.Errorf("Incorrect syntax tree: \n%v", services.ConvertTreeToString(syntax_tree.Root, "", true))
+		}
+	}
+
+	_, err = services.CreateSyntaxTree(tokens[:1], grammar)
+	if err != nil {
+		t.Errorf("Error not expected for zero repetitions: %v", err)
+	}
+}
+
+func TestConvertGrammarToNotation_BNF(t *testing.T) {
+
+	expected_res := `STATEMENT ::= DECLARATION SEPARATOR
+DECLARATION ::= TYPE IDENTIFIER ASSIGNMENT EXPRESSION
+EXPRESSION ::= TERM "+" TERM | TERM
+TERM ::= INTEGER | ε
+`
+
+	grammar := services.Grammar{
+		Variables: []string{"STATEMENT", "DECLARATION", "EXPRESSION", "TERM"},
+		Terminals: []string{"IDENTIFIER", "ASSIGNMENT", "INTEGER", "+", "SEPARATOR"},
+		Start:     "STATEMENT",
+		Rules: []services.ParsingRule{
+			{Input: "STATEMENT", Output: []string{"DECLARATION", "SEPARATOR"}},
+			{Input: "DECLARATION", Output: []string{"TYPE", "IDENTIFIER", "ASSIGNMENT", "EXPRESSION"}},
+			{Input: "EXPRESSION", Output: []string{"TERM", "+", "TERM"}},
+			{Input: "EXPRESSION", Output: []string{"TERM"}},
+			{Input: "TERM", Output: []string{"INTEGER"}},
+			{Input: "TERM", Output: []string{"ε"}},
+		},
+	}
+
+	notation := services.ConvertGrammarToNotation(grammar)
+	if notation != expected_res {
+		t.Errorf("Incorrect notation: \n%v", notation)
+	}
+}
+
+func TestConvertGrammarToNotation_RoundTrip(t *testing.T) {
+
+	input := `EXPR ::= TERM { ( '+' | '-' ) TERM }
+TERM ::= [ '-' ] INTEGER | IDENTIFIER
+`
+
+	grammar, err := services.ReadGrammarNotation(input)
+	if err != nil {
+		t.Fatalf("Error not expected: %v", err)
+	}
+
+	notation := services.ConvertGrammarToNotation(grammar)
+	if strings.TrimSpace(notation) != strings.TrimSpace(input) {
+		t.Errorf("Incorrect notation: \n%v", notation)
+	}
+
+	reread, err := services.ReadGrammarNotation(notation)
+	if err != nil {
+		t.Errorf("Error not expected: %v", err)
+	} else if !reflect.DeepEqual(reread, grammar) {
+		t.Errorf("Grammar changed after round trip: %v", reread)
+	}
+}
@@ -0,0 +1,192 @@
+package unit_tests
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/COS301-SE-2025/Visual-Compiler/backend/core/services"
+)
+
+func createListGrammar() services.Grammar {
+	return services.Grammar{
+		Variables: []string{"LIST", "REST", "ITEM"},
+		Terminals: []string{"INTEGER", "IDENTIFIER", "SEPARATOR"},
+		Start:     "LIST",
+		Rules: []services.ParsingRule{
+			{Input: "LIST", Output: []string{"ITEM", "REST"}},
+			{Input: "REST", Output: []string{"SEPARATOR", "ITEM", "REST"}},
+			{Input: "REST", Output: []string{"ε"}},
+			{Input: "ITEM", Output: []string{"INTEGER"}},
+			{Input: "ITEM", Output: []string{"IDENTIFIER"}},
+		},
+	}
+}
+
+func isChomskyNormalForm(grammar services.Grammar) bool {
+	for _, rule := range grammar.Rules {
+		switch len(rule.Output) {
+		case 1:
+			if rule.Output[0] == "ε" && rule.Input != grammar.Start {
+				return false
+			}
+			if rule.Output[0] != "ε" && !services.ContainsSymbol(grammar.Terminals, rule.Output[0]) {
+				return false
+			}
+		case 2:
+			for _, symbol := range rule.Output {
+				if !services.ContainsSymbol(grammar.Variables, symbol) || symbol == grammar.Start {
+					return false
+				}
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func TestConvertToCNF_NoStart(t *testing.T) {
+	_, _, err := services.ConvertToCNF(services.Grammar{})
+
+	if err == nil {
+		t.Errorf("Error expected for grammar without start variable")
+	} else {
+		if err.Error() != fmt.Errorf("no start variable found").Error() {
+			t.Errorf("Incorrect error received: %v", err)
+		}
+	}
+}
+
+func TestConvertToCNF_Steps(t *testing.T) {
+	cnf, steps, err := services.ConvertToCNF(createListGrammar())
+
+	if err != nil {
+		t.Fatalf("Error not expected: %v", err)
+	}
+
+	names := []string{"start", "epsilon", "unit", "useless", "cnf"}
+	if len(steps) != len(names) {
+		t.Fatalf("Incorrect number of steps: %v", len(steps))
+	}
+	for i, name := range names {
+		if steps[i].Name != name {
+			t.Errorf("Incorrect step: %v", steps[i].Name)
+		}
+	}
+
+	for _, rule := range steps[1].Grammar.Rules {
+		if rule.Output[0] == "ε" {
+			t.Errorf("ε rule not removed: %v", services.FormatParsingRule(rule))
+		}
+	}
+
+	for _, rule := range steps[2].Grammar.Rules {
+		if len(rule.Output) == 1 && services.ContainsSymbol(steps[2].Grammar.Variables, rule.Output[0]) {
+			t.Errorf("Unit rule not removed: %v", services.FormatParsingRule(rule))
+		}
+	}
+
+	if !isChomskyNormalForm(cnf) {
+		t.Errorf("Grammar not in Chomsky normal form: %v", cnf.Rules)
+	}
+}
+
+func TestConvertToCNF_UselessSymbols(t *testing.T) {
+	grammar := services.Grammar{
+		Variables: []string{"S", "A", "B"},
+		Terminals: []string{"X", "Y"},
+		Start:     "S",
+		Rules: []services.ParsingRule{
+			{Input: "S", Output: []string{"X", "Y"}},
+			{Input: "S", Output: []string{"X", "A"}},
+			{Input: "A", Output: []string{"X", "A"}},
+			{Input: "B", Output: []string{"Y"}},
+		},
+	}
+
+	_, steps, err := services.ConvertToCNF(grammar)
+
+	if err != nil {
+		t.Fatalf("Error not expected: %v", err)
+	}
+
+	useless := steps[3].Grammar
+	if len(useless.Rules) != 1 || services.ContainsSymbol(useless.Variables, "A") || services.ContainsSymbol(useless.Variables, "B") {
+		t.Errorf("Useless symbols not removed: %v", useless.Rules)
+	}
+}
+
+func TestCreateCYKSyntaxTree_MatchesParser(t *testing.T) {
+	tokens := createDeclarationTokens()
+
+	result, err := services.CreateCYKSyntaxTree(tokens, createDeclarationGrammar())
+
+	if err != nil {
+		t.Fatalf("Error not expected: %v", err)
+	}
+
+	if !result.Accepted {
+		t.Fatalf("Tokens expected to be accepted")
+	}
+
+	if len(result.Table) != len(tokens) || len(result.Table[0]) != len(tokens) || len(result.Table[len(tokens)-1]) != 1 {
+		t.Errorf("Incorrect table shape")
+	}
+
+	expected := services.ConvertTreeToSExpression(createDeclarationTree(t).Root)
+	if services.ConvertTreeToSExpression(result.Tree.Root) != expected {
+		t.Errorf("Incorrect tree: %v", services.ConvertTreeToSExpression(result.Tree.Root))
+	}
+}
+
+func TestCreateCYKSyntaxTree_EmptyAndUnitRules(t *testing.T) {
+	grammar := createListGrammar()
+
+	inputs := []string{"1", "1 , a", "a , 2 , 3"}
+
+	for _, input := range inputs {
+		tokens := createTokens(map[string]string{",": "SEPARATOR"}, input)
+		result, err := services.CreateCYKSyntaxTree(tokens, grammar)
+		if err != nil || !result.Accepted {
+			t.Errorf("Tokens expected to be accepted: %v", tokens)
+			continue
+		}
+
+		tree, _ := services.CreateSyntaxTree(tokens, grammar)
+		if services.ConvertTreeToSExpression(result.Tree.Root) != services.ConvertTreeToSExpression(tree.Root) {
+			t.Errorf("Incorrect tree: \n%v\n%v", services.ConvertTreeToSExpression(result.Tree.Root), services.ConvertTreeToSExpression(tree.Root))
+		}
+	}
+}
+
+func TestCreateCYKSyntaxTree_StartInOutput(t *testing.T) {
+	grammar := services.Grammar{
+		Variables: []string{"TERM"},
+		Terminals: []string{"OPEN", "CLOSE", "INTEGER"},
+		Start:     "TERM",
+		Rules: []services.ParsingRule{
+			{Input: "TERM", Output: []string{"OPEN", "TERM", "CLOSE"}},
+			{Input: "TERM", Output: []string{"INTEGER"}},
+		},
+	}
+
+	tokens := createTokens(map[string]string{"(": "OPEN", ")": "CLOSE"}, "( ( 4 ) )")
+
+	result, err := services.CreateCYKSyntaxTree(tokens, grammar)
+
+	expected := `(TERM (OPEN "(") (TERM (OPEN "(") (TERM (INTEGER "4")) (CLOSE ")")) (CLOSE ")"))`
+
+	if err != nil || !result.Accepted {
+		t.Errorf("Tokens expected to be accepted")
+	} else if services.ConvertTreeToSExpression(result.Tree.Root) != expected {
+		t.Errorf("Incorrect tree: %v", services.ConvertTreeToSExpression(result.Tree.Root))
+	}
+
+	result, err = services.CreateCYKSyntaxTree(tokens[:4], grammar)
+
+	if err != nil {
+		t.Errorf("Error not expected: %v", err)
+	} else if result.Accepted || result.Tree.Root != nil {
+		t.Errorf("Tokens expected to be rejected")
+	}
+}
@@ -0,0 +1,182 @@
+package unit_tests
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/COS301-SE-2025/Visual-Compiler/backend/core/services"
+)
+
+func TestCreateDerivation_EmptyTree(t *testing.T) {
+	_, err := services.CreateDerivation(services.SyntaxTree{}, services.Grammar{})
+
+	if err == nil {
+		t.Errorf("Error expected for empty tree")
+	} else {
+		if err.Error() != fmt.Errorf("syntax tree is empty").Error() {
+			t.Errorf("Incorrect error received: %v", err)
+		}
+	}
+}
+
+func TestCreateDerivation_Leftmost(t *testing.T) {
+
+	expected_res := []string{
+		"STATEMENT",
+		"DECLARATION SEPARATOR",
+		"TYPE IDENTIFIER ASSIGNMENT EXPRESSION SEPARATOR",
+		"KEYWORD IDENTIFIER ASSIGNMENT EXPRESSION SEPARATOR",
+		"KEYWORD IDENTIFIER ASSIGNMENT TERM OPERATOR TERM SEPARATOR",
+		"KEYWORD IDENTIFIER ASSIGNMENT INTEGER OPERATOR TERM SEPARATOR",
+		"KEYWORD IDENTIFIER ASSIGNMENT INTEGER OPERATOR INTEGER SEPARATOR",
+	}
+
+	grammar := services.Grammar{
+		Variables: []string{"STATEMENT", "DECLARATION", "EXPRESSION", "TYPE", "TERM"},
+		Terminals: []string{"KEYWORD", "IDENTIFIER", "ASSIGNMENT", "INTEGER", "OPERATOR", "SEPARATOR"},
+		Start:     "STATEMENT",
+		Rules: []services.ParsingRule{
+			{Input: "STATEMENT", Output: []string{"DECLARATION", "SEPARATOR"}},
+			{Input: "DECLARATION", Output: []string{"TYPE", "IDENTIFIER", "ASSIGNMENT", "EXPRESSION"}},
+			{Input: "EXPRESSION", Output: []string{"TERM", "OPERATOR", "TERM"}},
+			{Input: "TERM", Output: []string{"INTEGER"}},
+			{Input: "TYPE", Output: []string{"KEYWORD"}},
+		},
+	}
+
+	derivation, err := services.CreateDerivation(createDeclarationTree(t), grammar)
+
+	if err != nil {
+		t.Errorf("Error not expected: %v", err)
+	} else if len(derivation) != len(expected_res) {
+		t.Errorf("Incorrect derivation: %v", derivation)
+	} else {
+		for i, step := range derivation {
+			if step.Form != expected_res[i] || step.Step != i {
+				t.Errorf("Incorrect sentential form %v: %v", i, step.Form)
+			}
+		}
+		if derivation[1].Rule != "STATEMENT -> DECLARATION SEPARATOR" {
+			t.Errorf("Incorrect rule: %v", derivation[1].Rule)
+		}
+	}
+}
+
+func TestCreateDerivation_EmptyRule(t *testing.T) {
+
+	grammar := services.Grammar{
+		Variables: []string{"LIST", "REST"},
+		Terminals: []string{"STRING"},
+		Start:     "LIST",
+		Rules: []services.ParsingRule{
+			{Input: "LIST", Output: []string{"STRING", "REST"}},
+			{Input: "REST", Output: []string{"ε"}},
+		},
+	}
+
+	syntax_tree, err := services.CreateSyntaxTree([]services.TypeValue{{Type: "STRING", Value: "red"}}, grammar)
+	if err != nil {
+		t.Fatalf("Error not expected: %v", err)
+	}
+
+	derivation, err := services.CreateDerivation(syntax_tree, grammar)
+
+	if err != nil {
+		t.Errorf("Error not expected: %v", err)
+	} else if len(derivation) != 3 || derivation[2].Form != "STRING" || derivation[2].Rule != "REST -> ε" {
+		t.Errorf("Incorrect derivation: %v", derivation)
+	}
+}
+
+func TestCreateTracedSyntaxTree_Valid(t *testing.T) {
+
+	tokens := []services.TypeValue{
+		{Type: "INTEGER", Value: "42"},
+	}
+
+	grammar := services.Grammar{
+		Variables: []string{"VARIABLE"},
+		Terminals: []string{"INTEGER", "STRING"},
+		Start:     "VARIABLE",
+		Rules: []services.ParsingRule{
+			{Input: "VARIABLE", Output: []string{"STRING"}},
+			{Input: "VARIABLE", Output: []string{"INTEGER"}},
+		},
+	}
+
+	expected_res := []services.ParseStep{
+		{Step: 1, Depth: 0, Action: "try", Symbol: "VARIABLE", Rule: "VARIABLE -> STRING", Position: 0, End: 0},
+		{Step: 2, Depth: 1, Action: "fail terminal", Symbol: "STRING", Position: 0, End: 0},
+		{Step: 3, Depth: 0, Action: "fail", Symbol: "VARIABLE", Rule: "VARIABLE -> STRING", Position: 0, End: 0},
+		{Step: 4, Depth: 0, Action: "try", Symbol: "VARIABLE", Rule: "VARIABLE -> INTEGER", Position: 0, End: 0},
+		{Step: 5, Depth: 1, Action: "match terminal", Symbol: "INTEGER", Position: 0, End: 1},
+		{Step: 6, Depth: 0, Action: "match", Symbol: "VARIABLE", Rule: "VARIABLE -> INTEGER", Position: 0, End: 1},
+		{Step: 7, Depth: 0, Action: "select", Symbol: "VARIABLE", Rule: "VARIABLE -> INTEGER", Position: 0, End: 1},
+	}
+
+	syntax_tree, trace, err := services.CreateTracedSyntaxTree(tokens, grammar)
+
+	if err != nil {
+		t.Errorf("Error not expected: %v", err)
+	}
+	if syntax_tree.Root == nil {
+		t.Errorf("Syntax tree expected")
+	}
+	if len(trace) != len(expected_res) {
+		t.Fatalf("Incorrect trace: %v", trace)
+	}
+	for i, step := range trace {
+		if step != expected_res[i] {
+			t.Errorf("Incorrect step: %v", step)
+		}
+	}
+}
+
+func TestCreateTracedSyntaxTree_SyntaxError(t *testing.T) {
+
+	tokens := []services.TypeValue{
+		{Type: "INTEGER", Value: "42"},
+	}
+
+	grammar := services.Grammar{
+		Variables: []string{"VARIABLE"},
+		Terminals: []string{"INTEGER", "STRING"},
+		Start:     "VARIABLE",
+		Rules: []services.ParsingRule{
+			{Input: "VARIABLE", Output: []string{"STRING"}},
+		},
+	}
+
+	_, trace, err := services.CreateTracedSyntaxTree(tokens, grammar)
+
+	if err == nil {
+		t.Errorf("Error expected for syntax error")
+	}
+	if len(trace) == 0 || trace[len(trace)-1].Action != "backtrack" {
+		t.Errorf("Trace expected to end with a backtrack: %v", trace)
+	}
+}
+
+func TestCreateSyntaxTree_NoTrace(t *testing.T) {
+
+	state := &services.ParseState{
+		Tokens: []services.TypeValue{{Type: "INTEGER", Value: "42"}},
+		Grammar: services.Grammar{
+			Variables: []string{"VARIABLE"},
+			Terminals: []string{"INTEGER"},
+			Start:     "VARIABLE",
+			Rules: []services.ParsingRule{
+				{Input: "VARIABLE", Output: []string{"INTEGER"}},
+			},
+		},
+	}
+
+	_, _, success := services.ParseSymbol(state, "VARIABLE", 0)
+
+	if !success {
+		t.Errorf("Parse expected to succeed")
+	}
+	if len(state.Trace) != 0 {
+		t.Errorf("No trace expected when tracing is disabled")
+	}
+}
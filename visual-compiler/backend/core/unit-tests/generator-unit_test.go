@@ -0,0 +1,161 @@
+package unit_tests
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/COS301-SE-2025/Visual-Compiler/backend/core/services"
+)
+
+func TestGenerateSentences_NoStart(t *testing.T) {
+	_, err := services.GenerateSentences(services.Grammar{}, services.GeneratorOptions{}, nil)
+
+	if err == nil {
+		t.Errorf("Error expected for grammar without start variable")
+	} else {
+		if err.Error() != fmt.Errorf("no start variable found").Error() {
+			t.Errorf("Incorrect error received: %v", err)
+		}
+	}
+}
+
+func TestGenerateSentences_Coverage(t *testing.T) {
+	grammar := createRecoveryGrammar()
+
+	result, err := services.GenerateSentences(grammar, services.GeneratorOptions{Samples: 4, MaxDepth: 6, Seed: 7}, nil)
+
+	if err != nil {
+		t.Fatalf("Error not expected: %v", err)
+	}
+
+	if len(result.Samples) != 4 {
+		t.Errorf("Incorrect number of samples: %v", len(result.Samples))
+	}
+
+	if len(result.Uncovered) != 0 {
+		t.Errorf("All rules expected to be used: %v", result.Uncovered)
+	}
+
+	for _, sample := range result.Samples {
+		tree, err := services.CreateSyntaxTree(sample.Tokens, grammar)
+
+		if err != nil {
+			t.Errorf("Generated tokens not accepted: %v", sample.Tokens)
+		} else if services.ConvertTreeToSExpression(tree.Root) != services.ConvertTreeToSExpression(sample.Tree.Root) {
+			t.Errorf("Generated tree does not match: %v", services.ConvertTreeToSExpression(sample.Tree.Root))
+		}
+	}
+}
+
+func TestGenerateSentences_SameSeed(t *testing.T) {
+	options := services.GeneratorOptions{Samples: 3, MaxDepth: 8, Seed: 42}
+
+	first, _ := services.GenerateSentences(createRecoveryGrammar(), options, nil)
+	second, _ := services.GenerateSentences(createRecoveryGrammar(), options, nil)
+
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("Same seed expected to give the same samples")
+	}
+}
+
+func TestGenerateSentences_Operators(t *testing.T) {
+	grammar, _ := services.ValidateGrammar(createExpressionGrammar())
+
+	result, err := services.GenerateSentences(grammar, services.GeneratorOptions{Samples: 20, MaxDepth: 5, Seed: 3}, nil)
+
+	if err != nil {
+		t.Fatalf("Error not expected: %v", err)
+	}
+
+	operators := 0
+
+	for _, sample := range result.Samples {
+		for i, token := range sample.Tokens {
+			if token.Type == "INTEGER" {
+				sample.Tokens[i].Value = "1"
+			}
+			if token.Type == "OPERATOR" || token.Type == "COMPARE" {
+				operators++
+			}
+		}
+
+		tree, err := services.CreateSyntaxTree(sample.Tokens, grammar)
+		if err != nil {
+			t.Errorf("Generated tokens not accepted: %v", sample.Tokens)
+			continue
+		}
+
+		replaceValues(sample.Tree.Root, "INTEGER", "1")
+		if services.ConvertTreeToSExpression(tree.Root) != services.ConvertTreeToSExpression(sample.Tree.Root) {
+			t.Errorf("Generated tree does not match: \n%v\n%v", services.ConvertTreeToSExpression(sample.Tree.Root), services.ConvertTreeToSExpression(tree.Root))
+		}
+	}
+
+	if operators == 0 {
+		t.Errorf("Operators expected in the generated expressions")
+	}
+}
+
+func TestGenerateSentences_Source(t *testing.T) {
+	rules := []services.TypeRegex{
+		{Type: "KEYWORD", Regex: "int|bool"},
+		{Type: "IDENTIFIER", Regex: "[a-z_][a-z0-9_]*"},
+		{Type: "ASSIGNMENT", Regex: "="},
+		{Type: "INTEGER", Regex: "[0-9]+"},
+		{Type: "SEPARATOR", Regex: ";"},
+	}
+
+	result, err := services.GenerateSentences(createRecoveryGrammar(), services.GeneratorOptions{Samples: 3, Seed: 11}, rules)
+
+	if err != nil {
+		t.Fatalf("Error not expected: %v", err)
+	}
+
+	for _, sample := range result.Samples {
+		tokens, _, err := services.CreateTokens(sample.Source, rules)
+
+		if err != nil || !reflect.DeepEqual(tokens, sample.Tokens) {
+			t.Errorf("Source does not give the generated tokens: %v", sample.Source)
+		}
+	}
+}
+
+func TestGenerateFromRegex_Matches(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+
+	patterns := []string{"[0-9]+", "[a-zA-Z_][a-zA-Z0-9_]*", "(int|float)", `"[^"]*"`, "a{2,4}b?", `\+|-`}
+
+	for _, pattern := range patterns {
+		regex := regexp.MustCompile("^(" + pattern + ")$")
+
+		for i := 0; i < 20; i++ {
+			value, err := services.GenerateFromRegex(pattern, random)
+
+			if err != nil {
+				t.Errorf("Error not expected: %v", err)
+			} else if !regex.MatchString(value) {
+				t.Errorf("Generated value %q does not match %v", value, pattern)
+			}
+		}
+	}
+
+	_, err := services.GenerateFromRegex("[a-", random)
+	if err == nil {
+		t.Errorf("Error expected for invalid regex")
+	}
+}
+
+func replaceValues(node *services.TreeNode, symbol string, value string) {
+	if node == nil {
+		return
+	}
+	if node.Symbol == symbol {
+		node.Value = value
+	}
+	for _, child := range node.Children {
+		replaceValues(child, symbol, value)
+	}
+}
@@ -0,0 +1,259 @@
+package unit_tests
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/COS301-SE-2025/Visual-Compiler/backend/core/services"
+)
+
+func createIRGrammar() services.Grammar {
+	return services.Grammar{
+		Variables: []string{"PROGRAM", "STATEMENT", "ASSIGN", "EXPRESSION", "ELEMENT", "CALL", "CONDITION", "CONDITIONAL", "LOOP", "BLOCK", "FUNCTION", "FUNCTION_DEFINITION", "PARAMETER", "RETURN", "TYPE"},
+		Terminals: []string{"KEYWORD", "CONTROL", "IF", "ELSE", "WHILE", "IDENTIFIER", "INTEGER", "ASSIGNMENT", "OPERATOR", "COMPARISON", "DELIMITER", "OPEN_BRACKET", "CLOSE_BRACKET", "OPEN_SCOPE", "CLOSE_SCOPE"},
+		Start:     "PROGRAM",
+		Rules: []services.ParsingRule{
+			{Input: "PROGRAM", Output: []string{"STATEMENT", "PROGRAM"}},
+			{Input: "PROGRAM", Output: []string{"STATEMENT"}},
+			{Input: "STATEMENT", Output: []string{"ASSIGN", "DELIMITER"}},
+			{Input: "STATEMENT", Output: []string{"CALL", "DELIMITER"}},
+			{Input: "STATEMENT", Output: []string{"CONDITIONAL"}},
+			{Input: "STATEMENT", Output: []string{"LOOP"}},
+			{Input: "STATEMENT", Output: []string{"FUNCTION"}},
+			{Input: "STATEMENT", Output: []string{"RETURN"}},
+			{Input: "ASSIGN", Output: []string{"IDENTIFIER", "ASSIGNMENT", "EXPRESSION"}},
+			{Input: "EXPRESSION", Output: []string{"ELEMENT", "OPERATOR", "EXPRESSION"}},
+			{Input: "EXPRESSION", Output: []string{"ELEMENT"}},
+			{Input: "ELEMENT", Output: []string{"CALL"}},
+			{Input: "ELEMENT", Output: []string{"INTEGER"}},
+			{Input: "ELEMENT", Output: []string{"IDENTIFIER"}},
+			{Input: "CALL", Output: []string{"IDENTIFIER", "OPEN_BRACKET", "ELEMENT", "CLOSE_BRACKET"}},
+			{Input: "CALL", Output: []string{"IDENTIFIER", "OPEN_BRACKET", "CLOSE_BRACKET"}},
+			{Input: "CONDITION", Output: []string{"EXPRESSION", "COMPARISON", "EXPRESSION"}},
+			{Input: "CONDITIONAL", Output: []string{"IF", "OPEN_BRACKET", "CONDITION", "CLOSE_BRACKET", "BLOCK", "ELSE", "BLOCK"}},
+			{Input: "CONDITIONAL", Output: []string{"IF", "OPEN_BRACKET", "CONDITION", "CLOSE_BRACKET", "BLOCK"}},
+			{Input: "LOOP", Output: []string{"WHILE", "OPEN_BRACKET", "CONDITION", "CLOSE_BRACKET", "BLOCK"}},
+			{Input: "BLOCK", Output: []string{"OPEN_SCOPE", "PROGRAM", "CLOSE_SCOPE"}},
+			{Input: "FUNCTION", Output: []string{"FUNCTION_DEFINITION", "BLOCK"}},
+			{Input: "FUNCTION_DEFINITION", Output: []string{"TYPE", "IDENTIFIER", "OPEN_BRACKET", "PARAMETER", "CLOSE_BRACKET"}},
+			{Input: "PARAMETER", Output: []string{"TYPE", "IDENTIFIER"}},
+			{Input: "RETURN", Output: []string{"CONTROL", "EXPRESSION", "DELIMITER"}},
+			{Input: "TYPE", Output: []string{"KEYWORD"}},
+		},
+	}
+}
+
+func createIRTree(t *testing.T, grammar services.Grammar, source_lines ...string) services.SyntaxTree {
+	token_types := map[string]string{
+		"int": "KEYWORD", "return": "CONTROL", "if": "IF", "else": "ELSE", "while": "WHILE",
+		"=": "ASSIGNMENT", "+": "OPERATOR", "-": "OPERATOR", "*": "OPERATOR", "<": "COMPARISON", "==": "COMPARISON",
+		";": "DELIMITER", "(": "OPEN_BRACKET", ")": "CLOSE_BRACKET", "{": "OPEN_SCOPE", "}": "CLOSE_SCOPE",
+	}
+
+	return createTree(t, grammar, token_types, source_lines...)
+}
+
+func irRules() services.IRRules {
+	return services.IRRules{
+		VariableRule:   "IDENTIFIER",
+		AssignmentRule: "ASSIGNMENT",
+		OperatorRule:   "OPERATOR",
+		ComparisonRule: "COMPARISON",
+		TermRule:       "ELEMENT",
+		ConditionRule:  "CONDITION",
+		IfRule:         "CONDITIONAL",
+		ElseRule:       "ELSE",
+		WhileRule:      "LOOP",
+		FunctionRule:   "FUNCTION_DEFINITION",
+		ParameterRule:  "PARAMETER",
+		CallRule:       "CALL",
+		ArgumentRule:   "ELEMENT",
+		ReturnRule:     "RETURN",
+	}
+}
+
+func TestGenerateIR_Expressions(t *testing.T) {
+	tree := createIRTree(t, createIRGrammar(), "x = 1 + 2 * y ;", "z = x ;")
+
+	program, err := services.GenerateIR(tree, irRules())
+
+	expected := "    t1 = 2 * y\n    t2 = 1 + t1\n    x = t2\n    z = x\n"
+	if err != nil {
+		t.Errorf("Error not supposed to occur: %v", err)
+	} else if services.ConvertIRToString(program) != expected {
+		t.Errorf("Expected:\n%v\nbut received:\n%v", expected, services.ConvertIRToString(program))
+	}
+
+	if program.Temporaries != 2 || program.Labels != 0 {
+		t.Errorf("Expected 2 temporaries and no labels but received %v and %v", program.Temporaries, program.Labels)
+	}
+
+	first := program.Instructions[0]
+	if first.Operator != "*" || first.Arg1 != "2" || first.Arg2 != "y" || first.Result != "t1" {
+		t.Errorf("Incorrect quadruple: %v", first)
+	}
+}
+
+func TestGenerateIR_ControlFlow(t *testing.T) {
+	tree := createIRTree(t, createIRGrammar(),
+		"if ( x < y ) { m = y ; } else { m = x ; }",
+		"while ( i < m ) { i = i + 1 ; }",
+	)
+
+	program, err := services.GenerateIR(tree, irRules())
+
+	expected := []string{
+		"    t1 = x < y",
+		"    ifFalse t1 goto L1",
+		"    m = y",
+		"    goto L2",
+		"L1:",
+		"    m = x",
+		"L2:",
+		"L3:",
+		"    t2 = i < m",
+		"    ifFalse t2 goto L4",
+		"    t3 = i + 1",
+		"    i = t3",
+		"    goto L3",
+		"L4:",
+	}
+	if err != nil {
+		t.Errorf("Error not supposed to occur: %v", err)
+	} else if services.ConvertIRToString(program) != strings.Join(expected, "\n")+"\n" {
+		t.Errorf("Expected:\n%v\nbut received:\n%v", strings.Join(expected, "\n"), services.ConvertIRToString(program))
+	}
+}
+
+func TestGenerateIR_IfWithoutElse(t *testing.T) {
+	tree := createIRTree(t, createIRGrammar(), "if ( a == 0 ) { a = 1 ; }")
+
+	program, err := services.GenerateIR(tree, irRules())
+
+	expected := "    t1 = a == 0\n    ifFalse t1 goto L1\n    a = 1\nL1:\n"
+	if err != nil {
+		t.Errorf("Error not supposed to occur: %v", err)
+	} else if services.ConvertIRToString(program) != expected {
+		t.Errorf("Expected:\n%v\nbut received:\n%v", expected, services.ConvertIRToString(program))
+	}
+}
+
+func TestGenerateIR_Functions(t *testing.T) {
+	tree := createIRTree(t, createIRGrammar(),
+		"int double ( int n ) { return n * 2 ; }",
+		"x = double ( 4 ) + 1 ;",
+		"print ( x ) ;",
+	)
+
+	program, err := services.GenerateIR(tree, irRules())
+
+	expected := []string{
+		"begin_func double",
+		"    receive n",
+		"    t1 = n * 2",
+		"    return t1",
+		"end_func double",
+		"    param 4",
+		"    t2 = call double, 1",
+		"    t3 = t2 + 1",
+		"    x = t3",
+		"    param x",
+		"    call print, 1",
+	}
+	if err != nil {
+		t.Errorf("Error not supposed to occur: %v", err)
+	} else if services.ConvertIRToString(program) != strings.Join(expected, "\n")+"\n" {
+		t.Errorf("Expected:\n%v\nbut received:\n%v", strings.Join(expected, "\n"), services.ConvertIRToString(program))
+	}
+
+	encoded, err := json.Marshal(program)
+	if err != nil {
+		t.Errorf("Error not supposed to occur: %v", err)
+	} else if !strings.Contains(string(encoded), `{"operator":"call","arg1":"double","arg2":"1","result":"t2"}`) {
+		t.Errorf("Incorrect JSON form: %v", string(encoded))
+	}
+}
+
+func TestGenerateIR_Errors(t *testing.T) {
+	_, err := services.GenerateIR(services.SyntaxTree{}, irRules())
+	if err == nil || err.Error() != "empty syntax tree" {
+		t.Errorf("Error expected for empty tree but received %v", err)
+	}
+
+	rules := irRules()
+	rules.ConditionRule = ""
+	_, err = services.GenerateIR(services.SyntaxTree{Root: &services.TreeNode{Symbol: "PROGRAM"}}, rules)
+	if err == nil || err.Error() != "conditionals and loops need a condition rule" {
+		t.Errorf("Error expected for missing condition rule but received %v", err)
+	}
+
+	tree := services.SyntaxTree{Root: &services.TreeNode{Symbol: "PROGRAM", Children: []*services.TreeNode{
+		{Symbol: "LOOP", Children: []*services.TreeNode{
+			{Symbol: "WHILE", Value: "while"},
+			{Symbol: "BLOCK", Children: []*services.TreeNode{{Symbol: "OPEN_SCOPE", Value: "{"}, {Symbol: "CLOSE_SCOPE", Value: "}"}}},
+		}},
+	}}}
+	_, err = services.GenerateIR(tree, irRules())
+	if err == nil || err.Error() != "LOOP has no condition: while { }" {
+		t.Errorf("Error expected for missing condition but received %v", err)
+	}
+
+	tree = services.SyntaxTree{Root: &services.TreeNode{Symbol: "ASSIGN", Children: []*services.TreeNode{
+		{Symbol: "IDENTIFIER", Value: "x"}, {Symbol: "ASSIGNMENT", Value: "="}, {Symbol: "DELIMITER", Value: ";"},
+	}}}
+	_, err = services.GenerateIR(tree, irRules())
+	if err == nil || err.Error() != "assignment to x has no value" {
+		t.Errorf("Error expected for missing value but received %v", err)
+	}
+}
+
+func TestGenerateIR_SiblingFunctions(t *testing.T) {
+	// the declarations and their bodies are siblings of the program
+	grammar := createIRGrammar()
+	grammar.Rules = append([]services.ParsingRule{
+		{Input: "PROGRAM", Output: []string{"FUNCTION_DEFINITION", "RETURN", "FUNCTION_DEFINITION", "RETURN", "STATEMENT"}},
+	}, grammar.Rules...)
+	tree := createIRTree(t, grammar, "int f ( int a )", "return 1 ;", "int g ( int b )", "return 2 ;", "x = 3 ;")
+
+	program, err := services.GenerateIR(tree, irRules())
+
+	expected := []string{
+		"begin_func f",
+		"    receive a",
+		"    return 1",
+		"end_func f",
+		"begin_func g",
+		"    receive b",
+		"    return 2",
+		"end_func g",
+		"    x = 3",
+	}
+	if err != nil {
+		t.Errorf("Error not supposed to occur: %v", err)
+	} else if services.ConvertIRToString(program) != strings.Join(expected, "\n")+"\n" {
+		t.Errorf("Expected:\n%v\nbut received:\n%v", strings.Join(expected, "\n"), services.ConvertIRToString(program))
+	}
+}
+
+func TestGenerateIR_FunctionBlock(t *testing.T) {
+	// the function node holds its body, so the next statement is outside the function
+	grammar := createIRGrammar()
+	grammar.Rules = append([]services.ParsingRule{
+		{Input: "FUNCTION", Output: []string{"TYPE", "IDENTIFIER", "OPEN_BRACKET", "PARAMETER", "CLOSE_BRACKET", "BLOCK"}},
+	}, grammar.Rules...)
+	tree := createIRTree(t, grammar, "int f ( int a ) { return a ; }", "x = 1 ;")
+
+	rules := irRules()
+	rules.FunctionRule = "FUNCTION"
+	rules.BlockRule = "BLOCK"
+
+	program, err := services.GenerateIR(tree, rules)
+
+	expected := "begin_func f\n    receive a\n    return a\nend_func f\n    x = 1\n"
+	if err != nil {
+		t.Errorf("Error not supposed to occur: %v", err)
+	} else if services.ConvertIRToString(program) != expected {
+		t.Errorf("Expected:\n%v\nbut received:\n%v", expected, services.ConvertIRToString(program))
+	}
+}
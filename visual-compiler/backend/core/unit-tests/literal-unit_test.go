@@ -0,0 +1,194 @@
+package unit_tests
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/COS301-SE-2025/Visual-Compiler/backend/core/services"
+)
+
+func createKeywordGrammar() services.Grammar {
+	return services.Grammar{
+		Variables: []string{"STATEMENT", "CONDITION"},
+		Terminals: []string{"IDENTIFIER", "INTEGER"},
+		Start:     "STATEMENT",
+		Rules: []services.ParsingRule{
+			{Input: "STATEMENT", Output: []string{"'if'", "CONDITION", "'then'", "IDENTIFIER"}},
+			{Input: "STATEMENT", Output: []string{"keyword:'while'", "CONDITION", "'do'", "IDENTIFIER"}},
+			{Input: "CONDITION", Output: []string{"IDENTIFIER", "'<'", "INTEGER"}},
+		},
+	}
+}
+
+func createKeywordTokens(keyword string, body string) []services.TypeValue {
+	token_types := map[string]string{keyword: "KEYWORD", body: "KEYWORD", "<": "OPERATOR"}
+
+	return createTokens(token_types, keyword+" count < 10 "+body+" step")
+}
+
+func TestReadLiteral_Valid(t *testing.T) {
+	tests := []struct {
+		symbol     string
+		token_type string
+		value      string
+	}{
+		{"'if'", "", "if"},
+		{`"it's"`, "", "it's"},
+		{"KEYWORD:'while'", "KEYWORD", "while"},
+		{"'+'", "", "+"},
+	}
+
+	for _, test := range tests {
+		token_type, value, literal := services.ReadLiteral(test.symbol)
+
+		if !literal || token_type != test.token_type || value != test.value {
+			t.Errorf("Incorrect literal for %v: %v %v %v", test.symbol, token_type, value, literal)
+		}
+
+		if services.FormatLiteral(token_type, value) != test.symbol {
+			t.Errorf("Incorrect format for %v: %v", test.symbol, services.FormatLiteral(token_type, value))
+		}
+	}
+}
+
+func TestReadLiteral_Invalid(t *testing.T) {
+	symbols := []string{"IF", "''", "'if", "KEYWORD'if'", "1A:'if'", "'it's'", "ε"}
+
+	for _, symbol := range symbols {
+		if services.IsLiteral(symbol) {
+			t.Errorf("Symbol not expected to be a literal: %v", symbol)
+		}
+	}
+}
+
+func TestCreateSyntaxTree_Literals(t *testing.T) {
+	grammar, err := services.ValidateGrammar(createKeywordGrammar())
+
+	if err != nil {
+		t.Fatalf("Error not expected: %v", err)
+	}
+
+	if grammar.Rules[1].Output[0] != "KEYWORD:'while'" {
+		t.Errorf("Type of literal not normalised: %v", grammar.Rules[1].Output[0])
+	}
+
+	tree, err := services.CreateSyntaxTree(createKeywordTokens("if", "then"), grammar)
+
+	expected := `(STATEMENT (KEYWORD "if") (CONDITION (IDENTIFIER "count") (OPERATOR "<") (INTEGER "10")) (KEYWORD "then") (IDENTIFIER "step"))`
+
+	if err != nil {
+		t.Errorf("Error not expected: %v", err)
+	} else if services.ConvertTreeToSExpression(tree.Root) != expected {
+		t.Errorf("Incorrect tree: %v", services.ConvertTreeToSExpression(tree.Root))
+	}
+
+	_, err = services.CreateSyntaxTree(createKeywordTokens("while", "do"), grammar)
+	if err != nil {
+		t.Errorf("Error not expected: %v", err)
+	}
+
+	_, err = services.CreateSyntaxTree(createKeywordTokens("if", "do"), grammar)
+	if err == nil {
+		t.Errorf("Error expected for mismatched keywords")
+	}
+}
+
+func TestCreateSyntaxTree_LiteralTypeConstraint(t *testing.T) {
+	grammar, _ := services.ValidateGrammar(createKeywordGrammar())
+
+	tokens := createKeywordTokens("while", "do")
+	tokens[0].Type = "IDENTIFIER"
+
+	_, err := services.CreateSyntaxTree(tokens, grammar)
+
+	if err == nil {
+		t.Errorf("Error expected for literal of the wrong type")
+	}
+}
+
+func TestValidateTokens_Literals(t *testing.T) {
+	grammar, _ := services.ValidateGrammar(createKeywordGrammar())
+
+	err := services.ValidateTokens(createKeywordTokens("if", "then"), grammar)
+	if err != nil {
+		t.Errorf("Error not expected: %v", err)
+	}
+
+	err = services.ValidateTokens(createKeywordTokens("for", "then"), grammar)
+	if err == nil {
+		t.Errorf("Error expected for token matching no terminal")
+	} else {
+		if err.Error() != fmt.Errorf("token types do not correspond to grammar terminals").Error() {
+			t.Errorf("Incorrect error received: %v", err)
+		}
+	}
+}
+
+func TestReadGrammarNotation_Literals(t *testing.T) {
+	grammar, err := services.ReadGrammarNotation(`STATEMENT ::= 'if' CONDITION "then" IDENTIFIER | keyword:'while' CONDITION 'do' IDENTIFIER
+CONDITION ::= IDENTIFIER '<' INTEGER`)
+
+	if err != nil {
+		t.Fatalf("Error not expected: %v", err)
+	}
+
+	expected, _ := services.ValidateGrammar(createKeywordGrammar())
+
+	if !reflect.DeepEqual(grammar.Rules, expected.Rules) {
+		t.Errorf("Incorrect rules: %v", grammar.Rules)
+	}
+
+	if !reflect.DeepEqual(grammar.Terminals, []string{"IDENTIFIER", "INTEGER"}) {
+		t.Errorf("Incorrect terminals: %v", grammar.Terminals)
+	}
+
+	notation := services.ConvertGrammarToNotation(grammar)
+	reread, err := services.ReadGrammarNotation(notation)
+
+	if err != nil || !reflect.DeepEqual(reread.Rules, grammar.Rules) {
+		t.Errorf("Grammar changed after round trip: \n%v", notation)
+	}
+}
+
+func TestCreateCYKSyntaxTree_Literals(t *testing.T) {
+	grammar, _ := services.ValidateGrammar(createKeywordGrammar())
+	tokens := createKeywordTokens("while", "do")
+
+	result, err := services.CreateCYKSyntaxTree(tokens, grammar)
+	tree, _ := services.CreateSyntaxTree(tokens, grammar)
+
+	if err != nil || !result.Accepted {
+		t.Errorf("Tokens expected to be accepted")
+	} else if services.ConvertTreeToSExpression(result.Tree.Root) != services.ConvertTreeToSExpression(tree.Root) {
+		t.Errorf("Incorrect tree: %v", services.ConvertTreeToSExpression(result.Tree.Root))
+	}
+}
+
+func TestGenerateSentences_Literals(t *testing.T) {
+	grammar, _ := services.ValidateGrammar(createKeywordGrammar())
+
+	rules := []services.TypeRegex{
+		{Type: "KEYWORD", Regex: "if|then|while|do"},
+		{Type: "IDENTIFIER", Regex: "[a-z]+"},
+		{Type: "OPERATOR", Regex: "<"},
+		{Type: "INTEGER", Regex: "[0-9]+"},
+	}
+
+	result, err := services.GenerateSentences(grammar, services.GeneratorOptions{Samples: 4, Seed: 5}, rules)
+
+	if err != nil {
+		t.Fatalf("Error not expected: %v", err)
+	}
+
+	for _, sample := range result.Samples {
+		if sample.Tokens[0].Type != "KEYWORD" || sample.Tokens[2].Type != "OPERATOR" || sample.Tokens[2].Value != "<" {
+			t.Errorf("Incorrect literal tokens: %v", sample.Tokens)
+		}
+
+		_, err := services.CreateSyntaxTree(sample.Tokens, grammar)
+		if err != nil {
+			t.Errorf("Generated tokens not accepted: %v", sample.Tokens)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package unit_tests
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/COS301-SE-2025/Visual-Compiler/backend/core/services"
+)
+
+func createExpressionGrammar() services.Grammar {
+	return services.Grammar{
+		Variables: []string{"EXPRESSION"},
+		Terminals: []string{"INTEGER", "OPERATOR", "COMPARE", "OPEN", "CLOSE"},
+		Start:     "EXPRESSION",
+		Rules: []services.ParsingRule{
+			{Input: "EXPRESSION", Output: []string{"INTEGER"}},
+			{Input: "EXPRESSION", Output: []string{"OPEN", "EXPRESSION", "CLOSE"}},
+		},
+		Operators: []services.Operator{
+			{Terminal: "COMPARE", Precedence: 0, Associativity: "nonassoc"},
+			{Terminal: "OPERATOR", Value: "+", Precedence: 1, Associativity: "left"},
+			{Terminal: "OPERATOR", Value: "-", Precedence: 1},
+			{Terminal: "OPERATOR", Value: "*", Precedence: 2, Associativity: "left"},
+			{Terminal: "OPERATOR", Value: "^", Precedence: 3, Associativity: "right"},
+		},
+		Expressions: []string{"EXPRESSION"},
+	}
+}
+
+func createExpressionTokens(expression string) []services.TypeValue {
+	token_types := map[string]string{
+		"(": "OPEN", ")": "CLOSE", "<": "COMPARE",
+		"+": "OPERATOR", "-": "OPERATOR", "*": "OPERATOR", "^": "OPERATOR",
+	}
+
+	return createTokens(token_types, expression)
+}
+
+func TestParseExpression_Precedence(t *testing.T) {
+
+	expressions := map[string]string{
+		"1": `(EXPRESSION (INTEGER "1"))`,
+		"1 + 2 * 3": `(EXPRESSION (EXPRESSION (INTEGER "1")) (OPERATOR "+") ` +
+			`(EXPRESSION (EXPRESSION (INTEGER "2")) (OPERATOR "*") (EXPRESSION (INTEGER "3"))))`,
+		"1 - 2 - 3": `(EXPRESSION (EXPRESSION (EXPRESSION (INTEGER "1")) (OPERATOR "-") (EXPRESSION (INTEGER "2"))) ` +
+			`(OPERATOR "-") (EXPRESSION (INTEGER "3")))`,
+		"2 ^ 3 ^ 2": `(EXPRESSION (EXPRESSION (INTEGER "2")) (OPERATOR "^") ` +
+			`(EXPRESSION (EXPRESSION (INTEGER "3")) (OPERATOR "^") (EXPRESSION (INTEGER "2"))))`,
+		"( 1 + 2 ) * 3": `(EXPRESSION (EXPRESSION (OPEN "(") (EXPRESSION (EXPRESSION (INTEGER "1")) (OPERATOR "+") ` +
+			`(EXPRESSION (INTEGER "2"))) (CLOSE ")")) (OPERATOR "*") (EXPRESSION (INTEGER "3")))`,
+		"1 < 2 + 3": `(EXPRESSION (EXPRESSION (INTEGER "1")) (COMPARE "<") ` +
+			`(EXPRESSION (EXPRESSION (INTEGER "2")) (OPERATOR "+") (EXPRESSION (INTEGER "3"))))`,
+	}
+
+	grammar, err := services.ValidateGrammar(createExpressionGrammar())
+	if err != nil {
+		t.Fatalf("Error not expected: %v", err)
+	}
+
+	for expression, expected := range expressions {
+		tree, err := services.CreateSyntaxTree(createExpressionTokens(expression), grammar)
+
+		if err != nil {
+			t.Errorf("Error not expected for %v: %v", expression, err)
+		} else if services.ConvertTreeToSExpression(tree.Root) != expected {
+			t.Errorf("Incorrect tree for %v: %v", expression, services.ConvertTreeToSExpression(tree.Root))
+		}
+	}
+}
+
+func TestParseExpression_NonAssociative(t *testing.T) {
+	grammar, _ := services.ValidateGrammar(createExpressionGrammar())
+
+	_, err := services.CreateSyntaxTree(createExpressionTokens("1 < 2 < 3"), grammar)
+
+	if err == nil {
+		t.Errorf("Error expected for chained non-associative operator")
+	}
+}
+
+func TestParseExpression_MissingOperand(t *testing.T) {
+	grammar, _ := services.ValidateGrammar(createExpressionGrammar())
+
+	_, err := services.CreateSyntaxTree(createExpressionTokens("1 + * 3"), grammar)
+
+	if err == nil {
+		t.Errorf("Error expected for missing operand")
+	}
+}
+
+func TestValidateGrammar_InvalidAssociativity(t *testing.T) {
+	grammar := createExpressionGrammar()
+	grammar.Operators[0].Associativity = "both"
+
+	_, err := services.ValidateGrammar(grammar)
+
+	if err == nil {
+		t.Errorf("Error expected for invalid associativity")
+	} else {
+		if err.Error() != fmt.Errorf("invalid associativity for operator COMPARE: both").Error() {
+			t.Errorf("Incorrect error received: %v", err)
+		}
+	}
+}
+
+func TestValidateGrammar_DuplicateOperator(t *testing.T) {
+	grammar := createExpressionGrammar()
+	grammar.Operators = append(grammar.Operators, services.Operator{Terminal: "operator", Value: "+", Precedence: 4})
+
+	_, err := services.ValidateGrammar(grammar)
+
+	if err == nil {
+		t.Errorf("Error expected for duplicate operator")
+	} else {
+		if err.Error() != fmt.Errorf("operator declared more than once: OPERATOR +").Error() {
+			t.Errorf("Incorrect error received: %v", err)
+		}
+	}
+}
+
+func TestValidateGrammar_UnknownExpression(t *testing.T) {
+	grammar := createExpressionGrammar()
+	grammar.Expressions = []string{"TERM"}
+
+	_, err := services.ValidateGrammar(grammar)
+
+	if err == nil {
+		t.Errorf("Error expected for unknown expression variable")
+	} else {
+		if err.Error() != fmt.Errorf("expression variable TERM is not in the list of variables").Error() {
+			t.Errorf("Incorrect error received: %v", err)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package unit_tests
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/COS301-SE-2025/Visual-Compiler/backend/core/services"
+)
+
+func createRecoveryGrammar() services.Grammar {
+	return services.Grammar{
+		Variables: []string{"PROGRAM", "STATEMENT"},
+		Terminals: []string{"KEYWORD", "IDENTIFIER", "ASSIGNMENT", "INTEGER", "SEPARATOR"},
+		Start:     "PROGRAM",
+		Rules: []services.ParsingRule{
+			{Input: "PROGRAM", Output: []string{"STATEMENT", "PROGRAM"}},
+			{Input: "PROGRAM", Output: []string{"STATEMENT"}},
+			{Input: "STATEMENT", Output: []string{"KEYWORD", "IDENTIFIER", "ASSIGNMENT", "INTEGER", "SEPARATOR"}},
+		},
+		SyncTerminals: []string{"SEPARATOR"},
+	}
+}
+
+func createStatementTokens(statements ...string) []services.TypeValue {
+	return createTokens(map[string]string{"int": "KEYWORD", "=": "ASSIGNMENT", ";": "SEPARATOR"}, statements...)
+}
+
+func TestCreateRecoveredSyntaxTree_NoSyncTerminals(t *testing.T) {
+	grammar := createRecoveryGrammar()
+	grammar.SyncTerminals = nil
+
+	_, _, err := services.CreateRecoveredSyntaxTree(createStatementTokens("int a = 1 ;"), grammar)
+
+	if err == nil {
+		t.Errorf("Error expected for grammar without synchronising terminals")
+	} else {
+		if err.Error() != fmt.Errorf("grammar has no synchronising terminals").Error() {
+			t.Errorf("Incorrect error received: %v", err)
+		}
+	}
+}
+
+func TestCreateRecoveredSyntaxTree_NoErrors(t *testing.T) {
+	tokens := createStatementTokens("int a = 1 ;", "int b = 2 ;")
+
+	tree, syntax_errors, err := services.CreateRecoveredSyntaxTree(tokens, createRecoveryGrammar())
+
+	if err != nil {
+		t.Errorf("Error not expected: %v", err)
+	} else if len(syntax_errors) != 0 {
+		t.Errorf("No syntax errors expected: %v", syntax_errors)
+	} else {
+		expected, _ := services.CreateSyntaxTree(tokens, createRecoveryGrammar())
+		if services.ConvertTreeToString(tree.Root, "", true) != services.ConvertTreeToString(expected.Root, "", true) {
+			t.Errorf("Incorrect tree: \n%v", services.ConvertTreeToString(tree.Root, "", true))
+		}
+	}
+}
+
+func TestCreateRecoveredSyntaxTree_SkipStatement(t *testing.T) {
+
+	expected_res := `
+└──  PROGRAM
+    ├──  STATEMENT
+    │   ├──  KEYWORD: int
+    │   ├──  IDENTIFIER: a
+    │   ├──  ASSIGNMENT: =
+    │   ├──  INTEGER: 1
+    │   └──  SEPARATOR: ;
+    └──  PROGRAM
+        ├──  STATEMENT
+        │   └──  ERROR
+        │       ├──  KEYWORD: int
+        │       ├──  ASSIGNMENT: =
+        │       ├──  INTEGER: 2
+        │       └──  SEPARATOR: ;
+        └──  PROGRAM
+            └──  STATEMENT
+                ├──  KEYWORD: int
+                ├──  IDENTIFIER: c
+                ├──  ASSIGNMENT: =
+                ├──  INTEGER: 3
+                └──  SEPARATOR: ;`
+
+	tokens := createStatementTokens("int a = 1 ;", "int = 2 ;", "int c = 3 ;")
+
+	tree, syntax_errors, err := services.CreateRecoveredSyntaxTree(tokens, createRecoveryGrammar())
+
+	if err != nil {
+		t.Fatalf("Error not expected: %v", err)
+	}
+
+	tree_string := services.ConvertTreeToString(tree.Root, "", true)
+	if strings.TrimSpace(tree_string) != strings.TrimSpace(expected_res) {
+		t.Errorf("Incorrect tree: \n%v", tree_string)
+	}
+
+	if len(syntax_errors) != 1 {
+		t.Fatalf("One syntax error expected: %v", syntax_errors)
+	}
+
+	syntax_error := syntax_errors[0]
+	if syntax_error.Message != "unexpected ASSIGNMENT '=' at position 6, expected IDENTIFIER" {
+		t.Errorf("Incorrect message: %v", syntax_error.Message)
+	}
+	if syntax_error.Position != 6 || syntax_error.Found.Type != "ASSIGNMENT" || len(syntax_error.Skipped) != 4 {
+		t.Errorf("Incorrect syntax error: %v", syntax_error)
+	}
+}
+
+func TestCreateRecoveredSyntaxTree_MultipleErrors(t *testing.T) {
+	tokens := createStatementTokens("int a = ;", "int b = 2 ;", "int c 3 ;", "int d =")
+
+	tree, syntax_errors, err := services.CreateRecoveredSyntaxTree(tokens, createRecoveryGrammar())
+
+	if err != nil {
+		t.Fatalf("Error not expected: %v", err)
+	}
+
+	if tree.Root == nil {
+		t.Errorf("Partial tree expected")
+	}
+
+	expected := []string{
+		"unexpected SEPARATOR ';' at position 3, expected INTEGER",
+		"unexpected INTEGER '3' at position 11, expected ASSIGNMENT",
+		"unexpected end of input at position 16, expected INTEGER",
+	}
+
+	if len(syntax_errors) != len(expected) {
+		t.Fatalf("Incorrect number of syntax errors: %v", syntax_errors)
+	}
+
+	for i, message := range expected {
+		if syntax_errors[i].Message != message {
+			t.Errorf("Incorrect message: %v", syntax_errors[i].Message)
+		}
+	}
+
+	if len(syntax_errors[2].Skipped) != 3 || syntax_errors[2].Skipped[0].Value != "int" {
+		t.Errorf("Incorrect skipped tokens: %v", syntax_errors[2].Skipped)
+	}
+}
+
+func TestValidateGrammar_UnknownSyncTerminal(t *testing.T) {
+	grammar := createRecoveryGrammar()
+	grammar.SyncTerminals = []string{"brace"}
+
+	_, err := services.ValidateGrammar(grammar)
+
+	if err == nil {
+		t.Errorf("Error expected for unknown synchronising terminal")
+	} else {
+		if err.Error() != fmt.Errorf("synchronising terminal brace is not in the list of terminals").Error() {
+			t.Errorf("Incorrect error received: %v", err)
+		}
+	}
+}
+
+func TestCreateRecoveredSyntaxTree_LiteralSyncTerminal(t *testing.T) {
+	grammar := createRecoveryGrammar()
+	grammar.Rules[2].Output = []string{"KEYWORD", "IDENTIFIER", "ASSIGNMENT", "INTEGER", "'end'"}
+	grammar.SyncTerminals = []string{"'end'"}
+
+	tokens := createStatementTokens("int a = 1 end", "int = 2 end", "int c = 3 end")
+
+	tree, syntax_errors, err := services.CreateRecoveredSyntaxTree(tokens, grammar)
+
+	if err != nil {
+		t.Fatalf("Error not expected: %v", err)
+	}
+
+	if tree.Root == nil {
+		t.Errorf("Partial tree expected")
+	}
+
+	if len(syntax_errors) != 1 {
+		t.Fatalf("One syntax error expected: %v", syntax_errors)
+	}
+
+	skipped := syntax_errors[0].Skipped
+	if len(skipped) != 4 || skipped[0].Value != "int" || skipped[3].Value != "end" {
+		t.Errorf("Incorrect skipped tokens: %v", skipped)
+	}
+}
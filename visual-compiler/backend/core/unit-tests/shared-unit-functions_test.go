@@ -0,0 +1,42 @@
+package unit_tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/COS301-SE-2025/Visual-Compiler/backend/core/services"
+)
+
+// split source lines on spaces into tokens. A value takes its type from the table, and any other value is
+// an INTEGER or FLOAT when it is a number and an IDENTIFIER otherwise
+func createTokens(token_types map[string]string, source_lines ...string) []services.TypeValue {
+	tokens := []services.TypeValue{}
+
+	for _, line := range source_lines {
+		for _, value := range strings.Fields(line) {
+			token_type, exists := token_types[value]
+			if !exists {
+				token_type = "IDENTIFIER"
+				if value[0] >= '0' && value[0] <= '9' {
+					token_type = "INTEGER"
+					if strings.Contains(value, ".") {
+						token_type = "FLOAT"
+					}
+				}
+			}
+			tokens = append(tokens, services.TypeValue{Type: token_type, Value: value})
+		}
+	}
+
+	return tokens
+}
+
+// parse source lines with the grammar, stopping the test when they cannot be parsed
+func createTree(t *testing.T, grammar services.Grammar, token_types map[string]string, source_lines ...string) services.SyntaxTree {
+	syntax_tree, err := services.CreateSyntaxTree(createTokens(token_types, source_lines...), grammar)
+	if err != nil {
+		t.Fatalf("parser failed: %v", err)
+	}
+
+	return syntax_tree
+}
@@ -2,6 +2,7 @@ package unit_tests
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 	
 	"github.com/COS301-SE-2025/Visual-Compiler/backend/core/services"
@@ -495,3 +496,196 @@ func TestSubstituteTemplate_UnavailableTokens(t *testing.T) {
 		t.Errorf("Expected '%s' but received '%s'", expected, result)
 	}
 }
+
+func createTreeTranslationExample(t *testing.T) services.SyntaxTree {
+	grammar := services.Grammar{
+		Variables: []string{"STATEMENT", "EXPRESSION", "TERM"},
+		Terminals: []string{"IDENTIFIER", "ASSIGNMENT", "INTEGER", "OPERATOR", "SEPARATOR"},
+		Start:     "STATEMENT",
+		Rules: []services.ParsingRule{
+			{Input: "STATEMENT", Output: []string{"IDENTIFIER", "ASSIGNMENT", "EXPRESSION", "SEPARATOR"}},
+			{Input: "EXPRESSION", Output: []string{"TERM", "OPERATOR", "TERM"}},
+			{Input: "TERM", Output: []string{"INTEGER", "OPERATOR", "IDENTIFIER"}},
+			{Input: "TERM", Output: []string{"INTEGER"}},
+		},
+	}
+	token_types := map[string]string{"=": "ASSIGNMENT", "+": "OPERATOR", "*": "OPERATOR", ";": "SEPARATOR"}
+
+	return createTree(t, grammar, token_types, "x = 1 + 2 * y ;")
+}
+
+func TestReadTreeTranslationRules_Valid(t *testing.T) {
+	input := []byte(`[
+		{
+			"node": "EXPRESSION",
+			"translation": ["{TERM#1} {OPERATOR} {TERM#2}"]
+		},
+		{
+			"node": "STATEMENT",
+			"production": ["IDENTIFIER ASSIGNMENT EXPRESSION SEPARATOR"],
+			"translation": ["{IDENTIFIER} := {EXPRESSION}"]
+		}
+	]`)
+	rules, err := services.ReadTreeTranslationRules(input)
+
+	if err != nil {
+		t.Errorf("Error not supposed to occur: %v", err)
+	} else if len(rules) != 2 {
+		t.Errorf("Expected 2 rules but received %d", len(rules))
+	} else {
+		if rules[0].Node != "STATEMENT" {
+			t.Errorf("Rule with a production expected first but received %v", rules[0].Node)
+		}
+		if len(rules[0].Production) != 4 {
+			t.Errorf("Production not split into symbols: %v", rules[0].Production)
+		}
+	}
+}
+
+func TestReadTreeTranslationRules_Errors(t *testing.T) {
+	inputs := map[string]string{
+		"no tree translation rules entered": ``,
+		"tree translation rule 1 has no node": `[{"node": " ", "translation": ["x"]}]`,
+		"token {TERM#3} in tree translation rule not found in production: EXPRESSION -> [TERM OPERATOR TERM]": `[{"node": "EXPRESSION", "production": ["TERM", "OPERATOR", "TERM"], "translation": ["{TERM#3}"]}]`,
+		"token {TERM#0} in tree translation rule for EXPRESSION: position must be at least 1": `[{"node": "EXPRESSION", "translation": ["{TERM#0}"]}]`,
+	}
+
+	for expected, input := range inputs {
+		_, err := services.ReadTreeTranslationRules([]byte(input))
+		if err == nil {
+			t.Errorf("Error expected: %v", expected)
+		} else if err.Error() != expected {
+			t.Errorf("Expected '%v' but received '%v'", expected, err)
+		}
+	}
+}
+
+func TestTranslateTree_Nested(t *testing.T) {
+	rules := []services.TreeTranslationRule{
+		{
+			Node:        "STATEMENT",
+			Translation: []string{"{EXPRESSION}", "pop {IDENTIFIER}"},
+		},
+		{
+			Node:        "EXPRESSION",
+			Translation: []string{"{TERM#1}", "{TERM#2}", "op {OPERATOR}"},
+		},
+		{
+			Node:        "TERM",
+			Production:  []string{"INTEGER", "OPERATOR", "IDENTIFIER"},
+			Translation: []string{"push {INTEGER}", "load {IDENTIFIER}", "op {OPERATOR}"},
+		},
+		{
+			Node:        "TERM",
+			Translation: []string{"push {INTEGER}"},
+		},
+	}
+
+	result, err := services.TranslateTree(createTreeTranslationExample(t), rules)
+
+	expected := []string{"push 1", "push 2", "load y", "op *", "op +", "pop x"}
+	if err != nil {
+		t.Errorf("Error not supposed to occur: %v", err)
+	} else if strings.Join(result, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected '%v' but received '%v'", expected, result)
+	}
+}
+
+func TestTranslateTree_InlineAndIndent(t *testing.T) {
+	rules := []services.TreeTranslationRule{
+		{
+			Node:        "STATEMENT",
+			Translation: []string{"begin", "    {EXPRESSION}", "{IDENTIFIER} := {EXPRESSION};", "end"},
+		},
+		{
+			Node:        "EXPRESSION",
+			Translation: []string{"({TERM#1} {OPERATOR} {TERM#2})"},
+		},
+		{
+			Node:        "TERM",
+			Production:  []string{"INTEGER", "OPERATOR", "IDENTIFIER"},
+			Translation: []string{"({INTEGER} {OPERATOR} {IDENTIFIER})"},
+		},
+	}
+
+	result, err := services.TranslateTree(createTreeTranslationExample(t), rules)
+
+	// a TERM holding a single leaf has no rule and takes the value of the leaf
+	expected := []string{"begin", "    (1 + (2 * y))", "x := (1 + (2 * y));", "end"}
+	if err != nil {
+		t.Errorf("Error not supposed to occur: %v", err)
+	} else if strings.Join(result, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected '%v' but received '%v'", expected, result)
+	}
+
+	rules[0].Translation = []string{"do", "  {EXPRESSION}"}
+	rules[1].Translation = []string{"{TERM#1}", "{TERM#2}"}
+
+	result, err = services.TranslateTree(createTreeTranslationExample(t), rules)
+
+	expected = []string{"do", "  1", "  (2 * y)"}
+	if err != nil {
+		t.Errorf("Error not supposed to occur: %v", err)
+	} else if strings.Join(result, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected '%v' but received '%v'", expected, result)
+	}
+}
+
+func TestTranslateTree_Errors(t *testing.T) {
+	_, err := services.TranslateTree(services.SyntaxTree{}, nil)
+	if err == nil || err.Error() != "empty syntax tree" {
+		t.Errorf("Error expected for empty tree but received %v", err)
+	}
+
+	rules := []services.TreeTranslationRule{
+		{Node: "STATEMENT", Translation: []string{"{EXPRESSION}"}},
+	}
+	_, err = services.TranslateTree(createTreeTranslationExample(t), rules)
+
+	expected := "no tree translation rule for EXPRESSION -> [TERM OPERATOR TERM]"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected '%v' but received '%v'", expected, err)
+	}
+
+	rules = []services.TreeTranslationRule{
+		{Node: "STATEMENT", Translation: []string{"{IDENTIFIER#2}"}},
+	}
+	_, err = services.TranslateTree(createTreeTranslationExample(t), rules)
+
+	expected = "token {IDENTIFIER#2} in tree translation rule not found in production: STATEMENT -> [IDENTIFIER ASSIGNMENT EXPRESSION SEPARATOR]"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected '%v' but received '%v'", expected, err)
+	}
+}
+
+func TestTranslateTree_LiteralBraces(t *testing.T) {
+	input := []byte(`[
+		{
+			"node": "STATEMENT",
+			"production": ["IDENTIFIER ASSIGNMENT EXPRESSION SEPARATOR"],
+			"translation": ["int main() {", "    {IDENTIFIER} = {EXPRESSION};", "    print(\"{{IDENTIFIER}}\");", "    { return 0; }", "}"]
+		},
+		{
+			"node": "EXPRESSION",
+			"translation": ["{TERM#1} {OPERATOR} {TERM#2}"]
+		},
+		{
+			"node": "TERM",
+			"production": ["INTEGER", "OPERATOR", "IDENTIFIER"],
+			"translation": ["{INTEGER} {OPERATOR} {IDENTIFIER}"]
+		}
+	]`)
+	rules, err := services.ReadTreeTranslationRules(input)
+	if err != nil {
+		t.Fatalf("Error not supposed to occur: %v", err)
+	}
+
+	result, err := services.TranslateTree(createTreeTranslationExample(t), rules)
+
+	expected := []string{"int main() {", "    x = 1 + 2 * y;", "    print(\"{IDENTIFIER}\");", "    { return 0; }", "}"}
+	if err != nil {
+		t.Errorf("Error not supposed to occur: %v", err)
+	} else if strings.Join(result, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected '%v' but received '%v'", expected, result)
+	}
+}
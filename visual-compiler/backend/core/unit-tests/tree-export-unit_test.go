@@ -0,0 +1,184 @@
+package unit_tests
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/COS301-SE-2025/Visual-Compiler/backend/core/services"
+)
+
+func TestExportTree_UnknownFormat(t *testing.T) {
+	_, err := services.ExportTree(createDeclarationTree(t), "png")
+
+	if err == nil {
+		t.Errorf("Error expected for unknown format")
+	} else {
+		if err.Error() != fmt.Errorf("unknown tree format: png").Error() {
+			t.Errorf("Incorrect error received: %v", err)
+		}
+	}
+}
+
+func TestExportTree_Text(t *testing.T) {
+	syntax_tree := createDeclarationTree(t)
+
+	tree_string, err := services.ExportTree(syntax_tree, "")
+
+	if err != nil {
+		t.Errorf("Error not expected: %v", err)
+	} else if tree_string != services.ConvertTreeToString(syntax_tree.Root, "", true) {
+		t.Errorf("Incorrect tree: \n%v", tree_string)
+	}
+}
+
+func TestConvertTreeToSExpression_Valid(t *testing.T) {
+
+	expected_res := `(STATEMENT (DECLARATION (TYPE (KEYWORD "int")) (IDENTIFIER "blue") (ASSIGNMENT "=") (EXPRESSION (TERM (INTEGER "13")) (OPERATOR "+") (TERM (INTEGER "89")))) (SEPARATOR ";"))`
+
+	sexpr := services.ConvertTreeToSExpression(createDeclarationTree(t).Root)
+
+	if sexpr != expected_res {
+		t.Errorf("Incorrect S-expression: %v", sexpr)
+	}
+}
+
+func TestReadSExpression_RoundTrip(t *testing.T) {
+
+	syntax_tree := services.SyntaxTree{
+		Root: &services.TreeNode{
+			Symbol: "STATEMENT",
+			Children: []*services.TreeNode{
+				{Symbol: "STRING", Value: `say "hi" \ bye`},
+				{Symbol: "EMPTY"},
+				{Symbol: "PAREN", Value: "()"},
+			},
+		},
+	}
+
+	sexpr := services.ConvertTreeToSExpression(syntax_tree.Root)
+
+	read_tree, err := services.ReadSExpression(sexpr)
+
+	if err != nil {
+		t.Errorf("Error not expected: %v", err)
+	} else if !reflect.DeepEqual(read_tree, syntax_tree) {
+		t.Errorf("Incorrect tree: %v", services.ConvertTreeToSExpression(read_tree.Root))
+	}
+}
+
+func TestReadSExpression_Whitespace(t *testing.T) {
+
+	read_tree, err := services.ReadSExpression(`
+		(TERM
+			(INTEGER "13"))
+	`)
+
+	if err != nil {
+		t.Errorf("Error not expected: %v", err)
+	} else if read_tree.Root.Symbol != "TERM" || read_tree.Root.Children[0].Value != "13" {
+		t.Errorf("Incorrect tree: %v", services.ConvertTreeToSExpression(read_tree.Root))
+	}
+}
+
+func TestReadSExpression_Errors(t *testing.T) {
+
+	inputs := map[string]string{
+		"":                     "no S-expression entered",
+		"TERM":                 "expected '(' at position 0",
+		"()":                   "expected a symbol at position 1",
+		`(TERM "13`:            "unterminated string at position 9",
+		`(TERM (INTEGER "13")`: "expected ')' at position 20",
+		`(TERM) (TERM)`:        "unexpected input after S-expression at position 7",
+		`(TERM "13" "14")`:     "expected ')' at position 11",
+	}
+
+	for input, expected := range inputs {
+		_, err := services.ReadSExpression(input)
+
+		if err == nil {
+			t.Errorf("Error expected for: %v", input)
+		} else if err.Error() != expected {
+			t.Errorf("Incorrect error for %v: %v", input, err)
+		}
+	}
+}
+
+func TestConvertTreeToDot_Valid(t *testing.T) {
+
+	expected_res := `digraph SyntaxTree {
+    node [shape=box];
+    n0 [label="TERM"];
+    n1 [label="STRING\n\"a\"", shape=ellipse];
+    n0 -> n1;
+}
+`
+
+	syntax_tree := services.SyntaxTree{
+		Root: &services.TreeNode{
+			Symbol: "TERM",
+			Children: []*services.TreeNode{
+				{Symbol: "STRING", Value: `"a"`},
+			},
+		},
+	}
+
+	dot, err := services.ExportTree(syntax_tree, "dot")
+
+	if err != nil {
+		t.Errorf("Error not expected: %v", err)
+	} else if dot != expected_res {
+		t.Errorf("Incorrect DOT: \n%v", dot)
+	}
+}
+
+func TestConvertTreeToForest_Valid(t *testing.T) {
+
+	expected_res := "\\begin{forest}\n[{TERM} [{OPEN\\_BRACKET} [{\\{}]] [{INTEGER} [{13}]]]\n\\end{forest}\n"
+
+	syntax_tree := services.SyntaxTree{
+		Root: &services.TreeNode{
+			Symbol: "TERM",
+			Children: []*services.TreeNode{
+				{Symbol: "OPEN_BRACKET", Value: "{"},
+				{Symbol: "INTEGER", Value: "13"},
+			},
+		},
+	}
+
+	forest, err := services.ExportTree(syntax_tree, "forest")
+
+	if err != nil {
+		t.Errorf("Error not expected: %v", err)
+	} else if forest != expected_res {
+		t.Errorf("Incorrect forest: \n%v", forest)
+	}
+}
+
+func TestConvertTreeToQtree_Valid(t *testing.T) {
+
+	expected_res := "\\Tree [.{TERM} [.{OPERATOR} {\\%} ] [.{INTEGER} {13} ] ]\n"
+
+	syntax_tree := services.SyntaxTree{
+		Root: &services.TreeNode{
+			Symbol: "TERM",
+			Children: []*services.TreeNode{
+				{Symbol: "OPERATOR", Value: "%"},
+				{Symbol: "INTEGER", Value: "13"},
+			},
+		},
+	}
+
+	qtree, err := services.ExportTree(syntax_tree, "qtree")
+
+	if err != nil {
+		t.Errorf("Error not expected: %v", err)
+	} else if qtree != expected_res {
+		t.Errorf("Incorrect qtree: \n%v", qtree)
+	}
+
+	if !strings.HasPrefix(services.ConvertTreeToQtree(nil), "\\Tree") {
+		t.Errorf("Empty qtree expected for no tree")
+	}
+}
@@ -0,0 +1,277 @@
+package unit_tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/COS301-SE-2025/Visual-Compiler/backend/core/services"
+)
+
+func TestAssembleVM_Valid(t *testing.T) {
+	program, err := services.AssembleVM([]string{
+		"# sums two numbers",
+		"start: push 2",
+		"    PUSH 3.5",
+		"",
+		"    add    # 5.5",
+		"end:",
+		"    print",
+	})
+
+	if err != nil {
+		t.Errorf("Error not supposed to occur: %v", err)
+	} else {
+		if len(program.Instructions) != 4 {
+			t.Errorf("Expected 4 instructions but received %v", len(program.Instructions))
+		}
+		if program.Labels["start"] != 0 || program.Labels["end"] != 3 {
+			t.Errorf("Incorrect labels: %v", program.Labels)
+		}
+		if program.Instructions[1].Opcode != "push" || program.Instructions[1].Operand != "3.5" || program.Instructions[1].Line != 3 {
+			t.Errorf("Incorrect instruction: %v", program.Instructions[1])
+		}
+	}
+}
+
+func TestAssembleVM_Errors(t *testing.T) {
+	sources := map[string][]string{
+		"line 2: unknown instruction: jump":         {"push 1", "jump L1"},
+		"line 1: push takes one operand":            {"push"},
+		"line 1: add takes no operand":              {"add 1"},
+		"line 1: not a number: x":                   {"push x"},
+		"line 2: label declared more than once: L1": {"L1: push 1", "L1:"},
+		"line 1: label not declared: L2":            {"jz L2", "L1:"},
+		"line 1: label has no name":                 {": push 1"},
+	}
+
+	for expected, source := range sources {
+		_, err := services.AssembleVM(source)
+		if err == nil {
+			t.Errorf("Error expected: %v", expected)
+		} else if err.Error() != expected {
+			t.Errorf("Expected '%v' but received '%v'", expected, err)
+		}
+	}
+}
+
+func TestRunVM_Valid(t *testing.T) {
+	program, err := services.AssembleVM([]string{
+		"    read",
+		"    store n",
+		"    push 0",
+		"    store total",
+		"loop:",
+		"    load n",
+		"    jz done",
+		"    load total",
+		"    load n",
+		"    add",
+		"    store total",
+		"    load n",
+		"    push 1",
+		"    sub",
+		"    store n",
+		"    jmp loop",
+		"done:",
+		"    load total",
+		"    print",
+		"    push 7",
+		"    push 2",
+		"    div",
+		"    push 1.5",
+		"    halt",
+		"    print",
+	})
+	if err != nil {
+		t.Fatalf("Error not supposed to occur: %v", err)
+	}
+
+	result, err := services.RunVM(program, "4\n", 0, false)
+
+	if err != nil {
+		t.Errorf("Error not supposed to occur: %v", err)
+	} else {
+		if result.Stdout != "10\n" {
+			t.Errorf("Expected output 10 but received %q", result.Stdout)
+		}
+		if strings.Join(result.Stack, " ") != "3 1.5" {
+			t.Errorf("Expected stack [3 1.5] but received %v", result.Stack)
+		}
+		if result.Variables["total"] != "10" || result.Variables["n"] != "0" {
+			t.Errorf("Incorrect variables: %v", result.Variables)
+		}
+		if !result.Halted || len(result.Trace) != 0 {
+			t.Errorf("Program expected to halt without a trace")
+		}
+	}
+}
+
+func TestRunVM_Trace(t *testing.T) {
+	program, _ := services.AssembleVM([]string{"push 2", "store x", "load x", "print"})
+
+	result, err := services.RunVM(program, "", 0, true)
+
+	if err != nil {
+		t.Errorf("Error not supposed to occur: %v", err)
+	} else if len(result.Trace) != 4 || result.Steps != 4 {
+		t.Errorf("Expected 4 steps in the trace but received %v", len(result.Trace))
+	} else {
+		step := result.Trace[2]
+		if step.Step != 3 || step.Counter != 2 || step.Instruction != "load x" || len(step.Stack) != 0 || step.Variables["x"] != "2" {
+			t.Errorf("Incorrect trace entry: %v", step)
+		}
+	}
+}
+
+func TestRunVM_Errors(t *testing.T) {
+	sources := map[string][]string{
+		"line 3: div: division by zero":            {"push 1", "push 0", "div"},
+		"line 1: pop: stack is empty":              {"pop"},
+		"line 1: load y: variable not assigned: y": {"load y"},
+		"line 1: read: no input left to read":      {"read"},
+		"instruction limit of 50 reached":          {"L1: jmp L1"},
+	}
+
+	for expected, source := range sources {
+		program, err := services.AssembleVM(source)
+		if err != nil {
+			t.Fatalf("Error not supposed to occur: %v", err)
+		}
+
+		result, err := services.RunVM(program, "", 50, false)
+		if err == nil {
+			t.Errorf("Error expected: %v", expected)
+		} else if err.Error() != expected {
+			t.Errorf("Expected '%v' but received '%v'", expected, err)
+		}
+		if result.Halted {
+			t.Errorf("Program not expected to halt: %v", expected)
+		}
+	}
+}
+
+func TestRunVM_Calls(t *testing.T) {
+	// recursive factorial, with the parameter local to each call
+	program, err := services.AssembleVM([]string{
+		"    push 5",
+		"    call fact",
+		"    print",
+		"    halt",
+		"fact:",
+		"    local n",
+		"    load n",
+		"    push 2",
+		"    lt",
+		"    jz recurse",
+		"    push 1",
+		"    ret",
+		"recurse:",
+		"    load n",
+		"    load n",
+		"    push 1",
+		"    sub",
+		"    call fact",
+		"    mul",
+		"    ret",
+	})
+	if err != nil {
+		t.Fatalf("Error not supposed to occur: %v", err)
+	}
+
+	result, err := services.RunVM(program, "", 0, false)
+
+	if err != nil {
+		t.Errorf("Error not supposed to occur: %v", err)
+	} else if result.Stdout != "120\n" || len(result.Variables) != 0 {
+		t.Errorf("Expected output 120 without global variables but received %q and %v", result.Stdout, result.Variables)
+	}
+}
+
+func TestEmitVMCode(t *testing.T) {
+	tree := createIRTree(t, createIRGrammar(),
+		"int double ( int n ) { return n * 2 ; }",
+		"n = read ( ) ;",
+		"i = 0 ;",
+		"while ( i < n ) { i = i + 1 ; print ( double ( i ) ) ; }",
+	)
+
+	ir, err := services.GenerateIR(tree, irRules())
+	if err != nil {
+		t.Fatalf("Error not supposed to occur: %v", err)
+	}
+
+	code, err := services.EmitVMCode(ir)
+	if err != nil {
+		t.Fatalf("Error not supposed to occur: %v", err)
+	}
+
+	if code[len(code)-1] != "    ret" || !strings.Contains(strings.Join(code, "\n"), "    halt\ndouble:\n    local n\n") {
+		t.Errorf("Functions expected after the main code:\n%v", strings.Join(code, "\n"))
+	}
+
+	program, err := services.AssembleVM(code)
+	if err != nil {
+		t.Fatalf("Error not supposed to occur: %v", err)
+	}
+
+	result, err := services.RunVM(program, "3", 0, false)
+	if err != nil {
+		t.Errorf("Error not supposed to occur: %v", err)
+	} else if result.Stdout != "2\n4\n6\n" || result.Variables["i"] != "3" {
+		t.Errorf("Expected output 2 4 6 but received %q and %v", result.Stdout, result.Variables)
+	}
+
+	_, err = services.EmitVMCode(services.IRProgram{Instructions: []services.Quadruple{{Operator: "call", Arg1: "missing", Arg2: "0"}}})
+	if err == nil || err.Error() != "function not declared: missing" {
+		t.Errorf("Error expected for undeclared function but received %v", err)
+	}
+}
+
+func TestEmitVMCode_NestedFunctions(t *testing.T) {
+	programs := map[string][]services.Quadruple{
+		"function g begins inside function f": {
+			{Operator: "begin_func", Arg1: "f"},
+			{Operator: "begin_func", Arg1: "g"},
+			{Operator: "end_func", Arg1: "g"},
+			{Operator: "end_func", Arg1: "f"},
+		},
+		"function f ends without beginning": {
+			{Operator: "end_func", Arg1: "f"},
+		},
+		"function f does not end": {
+			{Operator: "begin_func", Arg1: "f"},
+			{Operator: "return", Arg1: "1"},
+		},
+	}
+
+	for expected, instructions := range programs {
+		_, err := services.EmitVMCode(services.IRProgram{Instructions: instructions})
+		if err == nil {
+			t.Errorf("Error expected: %v", expected)
+		} else if err.Error() != expected {
+			t.Errorf("Expected '%v' but received '%v'", expected, err)
+		}
+	}
+}
+
+func TestRunVM_TraceLimit(t *testing.T) {
+	program, _ := services.AssembleVM([]string{"push 0", "L1: push 1", "add", "dup", "push 1500", "lt", "jnz L1"})
+
+	result, err := services.RunVM(program, "", 0, true)
+
+	if err != nil {
+		t.Errorf("Error not supposed to occur: %v", err)
+	} else {
+		if len(result.Trace) != services.VMTraceLimit || !result.TraceTruncated {
+			t.Errorf("Expected a truncated trace of %v entries but received %v", services.VMTraceLimit, len(result.Trace))
+		}
+		if result.Steps != 1+1500*6 || strings.Join(result.Stack, " ") != "1500" {
+			t.Errorf("Program expected to run to completion but ran %v steps", result.Steps)
+		}
+	}
+
+	result, _ = services.RunVM(program, "", 0, false)
+	if len(result.Trace) != 0 || result.TraceTruncated {
+		t.Errorf("No trace expected without tracing")
+	}
+}
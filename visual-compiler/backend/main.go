@@ -70,6 +70,11 @@ func main() {
 		routers.SetupAnalysingRouter(protected_analysing_routes)
 	}
 
+	protected_intermediate_routes := protected_routes.Group("/intermediate")
+	{
+		routers.SetupIntermediateRouter(protected_intermediate_routes)
+	}
+
 	protected_translating_routes := protected_routes.Group("/translating")
 	{
 		routers.SetupTranslatorRouter(protected_translating_routes)